@@ -1,7 +1,9 @@
 package aggregator
 
 import (
+	"context"
 	"errors"
+	"slices"
 
 	"github.com/pklimuk/ltv-predictor/fileParser"
 	"github.com/shopspring/decimal"
@@ -14,14 +16,25 @@ var (
 	ErrDifferentLength   = errors.New("ltv and revenues slices have different length")
 )
 
+// ctxCheckInterval defines how often aggregators check whether the context is done
+const ctxCheckInterval = 1024
+
 type Aggregator interface {
-	AggregateRevenues(revenues []fileParser.Revenues) (AggregatedRevenuesByKey, error)
+	AggregateRevenues(ctx context.Context, revenues []fileParser.Revenues) (AggregatedRevenuesByKey, error)
 	ConvertAggregatedByKeyRevenuesToLTVs(ar AggregatedRevenuesByKey) (AggregatedLTVsByKey, error)
 }
 
+// StreamAggregator aggregates records as they are read, without keeping all of them in memory
+type StreamAggregator interface {
+	AggregateRevenuesStream(ctx context.Context, records <-chan fileParser.Revenues) (AggregatedRevenuesByKey, error)
+}
+
 type AggregatedRevenues struct {
 	Revenues   []decimal.Decimal
 	UsersCount int64
+	// Streams are the revenues by the revenue type, the users count of a stream is the number of users of the records
+	// which have it. Streams are nil if the records have no breakdown
+	Streams map[string]AggregatedRevenues
 }
 type AggregatedRevenuesByKey map[string]AggregatedRevenues
 type AggregatedLTVs []decimal.Decimal
@@ -37,6 +50,80 @@ func (ar *AggregatedRevenues) addRevenues(revenues []decimal.Decimal) error {
 	return nil
 }
 
+// addStream adds the revenues of usersCount users to the stream, the stream is created if it is not aggregated yet
+func (ar *AggregatedRevenues) addStream(name string, revenues []decimal.Decimal, usersCount int64) error {
+	if ar.Streams == nil {
+		ar.Streams = make(map[string]AggregatedRevenues)
+	}
+	stream, ok := ar.Streams[name]
+	if !ok {
+		ar.Streams[name] = AggregatedRevenues{
+			Revenues:   append([]decimal.Decimal(nil), revenues...),
+			UsersCount: usersCount,
+		}
+		return nil
+	}
+	if err := stream.addRevenues(revenues); err != nil {
+		return err
+	}
+	stream.UsersCount += usersCount
+	ar.Streams[name] = stream
+	return nil
+}
+
+// Merge adds revenues and users counts of other to the aggregated revenues, it allows
+// to aggregate the data in parts and combine the results
+func (ar AggregatedRevenuesByKey) Merge(other AggregatedRevenuesByKey) error {
+	for k, v := range other {
+		existing, ok := ar[k]
+		if !ok {
+			existing = AggregatedRevenues{
+				Revenues:   append([]decimal.Decimal(nil), v.Revenues...),
+				UsersCount: v.UsersCount,
+			}
+		} else {
+			err := existing.addRevenues(v.Revenues)
+			if err != nil {
+				return err
+			}
+			existing.UsersCount += v.UsersCount
+		}
+		for name, stream := range v.Streams {
+			err := existing.addStream(name, stream.Revenues, stream.UsersCount)
+			if err != nil {
+				return err
+			}
+		}
+		ar[k] = existing
+	}
+	return nil
+}
+
+// Streams returns the names of the revenue streams of all the keys in the sorted order
+func (ar AggregatedRevenuesByKey) Streams() []string {
+	names := make([]string, 0)
+	for _, v := range ar {
+		for name := range v.Streams {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Stream returns the aggregated revenues of the stream by key, keys without the stream are omitted
+func (ar AggregatedRevenuesByKey) Stream(name string) AggregatedRevenuesByKey {
+	result := make(AggregatedRevenuesByKey)
+	for k, v := range ar {
+		if stream, ok := v.Streams[name]; ok {
+			result[k] = stream
+		}
+	}
+	return result
+}
+
 func convertAggregatedByKeyRevenuesToLTVs(ar AggregatedRevenuesByKey) (AggregatedLTVsByKey, error) {
 	var result AggregatedLTVsByKey = make(map[string]AggregatedLTVs)
 	for k, v := range ar {
@@ -50,6 +50,80 @@ func TestAggregatedRevenues_addRevenues_differentLength(t *testing.T) {
 	assert.Equal(t, ErrDifferentLength, err)
 }
 
+func TestAggregatedRevenuesByKey_Merge(t *testing.T) {
+	// Prepare data
+	ar := AggregatedRevenuesByKey{
+		"key1": {Revenues: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)}, UsersCount: 1},
+	}
+	other := AggregatedRevenuesByKey{
+		"key1": {Revenues: []decimal.Decimal{decimal.NewFromInt(3), decimal.NewFromInt(4)}, UsersCount: 2},
+		"key2": {Revenues: []decimal.Decimal{decimal.NewFromInt(5), decimal.NewFromInt(6)}, UsersCount: 3},
+	}
+
+	// Call the function
+	err := ar.Merge(other)
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), ar["key1"].UsersCount)
+	assert.True(t, decimal.NewFromInt(4).Equal(ar["key1"].Revenues[0]))
+	assert.True(t, decimal.NewFromInt(6).Equal(ar["key1"].Revenues[1]))
+	assert.Equal(t, int64(3), ar["key2"].UsersCount)
+	// merged revenues should not share memory with the other map
+	ar["key2"].Revenues[0] = decimal.Zero
+	assert.True(t, decimal.NewFromInt(5).Equal(other["key2"].Revenues[0]))
+}
+
+func TestAggregatedRevenuesByKey_Merge_Streams(t *testing.T) {
+	// Prepare data
+	ar := AggregatedRevenuesByKey{
+		"key1": {Revenues: []decimal.Decimal{decimal.NewFromInt(3)}, UsersCount: 2, Streams: map[string]AggregatedRevenues{
+			"ads": {Revenues: []decimal.Decimal{decimal.NewFromInt(1)}, UsersCount: 1},
+		}},
+	}
+	other := AggregatedRevenuesByKey{
+		"key1": {Revenues: []decimal.Decimal{decimal.NewFromInt(5)}, UsersCount: 2, Streams: map[string]AggregatedRevenues{
+			"ads": {Revenues: []decimal.Decimal{decimal.NewFromInt(2)}, UsersCount: 2},
+			"iap": {Revenues: []decimal.Decimal{decimal.NewFromInt(3)}, UsersCount: 2},
+		}},
+		"key2": {Revenues: []decimal.Decimal{decimal.NewFromInt(7)}, UsersCount: 1, Streams: map[string]AggregatedRevenues{
+			"iap": {Revenues: []decimal.Decimal{decimal.NewFromInt(7)}, UsersCount: 1},
+		}},
+	}
+
+	// Call the function
+	err := ar.Merge(other)
+
+	// Assertions
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ads", "iap"}, ar.Streams())
+	assert.Equal(t, int64(3), ar["key1"].Streams["ads"].UsersCount)
+	assert.True(t, decimal.NewFromInt(3).Equal(ar["key1"].Streams["ads"].Revenues[0]))
+	assert.Equal(t, AggregatedRevenuesByKey{
+		"key1": {Revenues: []decimal.Decimal{decimal.NewFromInt(3)}, UsersCount: 2},
+		"key2": {Revenues: []decimal.Decimal{decimal.NewFromInt(7)}, UsersCount: 1},
+	}, ar.Stream("iap"))
+	// merged streams should not share memory with the other map
+	ar["key2"].Streams["iap"].Revenues[0] = decimal.Zero
+	assert.True(t, decimal.NewFromInt(7).Equal(other["key2"].Streams["iap"].Revenues[0]))
+}
+
+func TestAggregatedRevenuesByKey_Merge_DifferentLength(t *testing.T) {
+	// Prepare data
+	ar := AggregatedRevenuesByKey{
+		"key1": {Revenues: []decimal.Decimal{decimal.NewFromInt(1)}, UsersCount: 1},
+	}
+	other := AggregatedRevenuesByKey{
+		"key1": {Revenues: []decimal.Decimal{decimal.NewFromInt(3), decimal.NewFromInt(4)}, UsersCount: 2},
+	}
+
+	// Call the function
+	err := ar.Merge(other)
+
+	// Assertions
+	assert.Equal(t, ErrDifferentLength, err)
+}
+
 func TestConvertAggregatedByKeyRevenuesToLTVs(t *testing.T) {
 	// Prepare data
 	ar := AggregatedRevenuesByKey{
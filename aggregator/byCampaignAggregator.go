@@ -1,35 +1,24 @@
 package aggregator
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pklimuk/ltv-predictor/fileParser"
 )
 
-type ByCampaignAggregator struct{}
+// ByCampaignAggregator aggregates revenues by campaign, the input is split across Shards goroutines if Shards is greater than 1
+type ByCampaignAggregator struct {
+	Shards int
+}
 
-func (a ByCampaignAggregator) AggregateRevenues(revenues []fileParser.Revenues) (AggregatedRevenuesByKey, error) {
-	if len(revenues) == 0 {
-		return nil, fmt.Errorf(ErrAggregatorError.Error(), ErrNoDataToAggregate)
-	}
-	var result AggregatedRevenuesByKey = make(map[string]AggregatedRevenues)
-	for i := 0; i < len(revenues); i++ {
-		rec := revenues[i]
-		if ar, ok := result[rec.CampaignID]; !ok {
-			result[rec.CampaignID] = AggregatedRevenues{
-				Revenues:   rec.Revenues,
-				UsersCount: rec.UsersCount,
-			}
-		} else {
-			err := ar.addRevenues(rec.Revenues)
-			if err != nil {
-				return nil, fmt.Errorf(ErrAggregatorError.Error(), err)
-			}
-			ar.UsersCount += rec.UsersCount
-			result[rec.CampaignID] = ar
-		}
-	}
-	return result, nil
+func (a ByCampaignAggregator) AggregateRevenues(ctx context.Context, revenues []fileParser.Revenues) (AggregatedRevenuesByKey, error) {
+	return aggregateSharded(ctx, revenues, a.Shards, byCampaignID)
+}
+
+// AggregateRevenuesStream aggregates records read from the channel until it is closed
+func (a ByCampaignAggregator) AggregateRevenuesStream(ctx context.Context, records <-chan fileParser.Revenues) (AggregatedRevenuesByKey, error) {
+	return aggregateStream(ctx, records, a.Shards, byCampaignID)
 }
 
 func (a ByCampaignAggregator) ConvertAggregatedByKeyRevenuesToLTVs(ar AggregatedRevenuesByKey) (AggregatedLTVsByKey, error) {
@@ -39,3 +28,7 @@ func (a ByCampaignAggregator) ConvertAggregatedByKeyRevenuesToLTVs(ar Aggregated
 	}
 	return ltvs, nil
 }
+
+func byCampaignID(rec fileParser.Revenues) string {
+	return rec.CampaignID
+}
@@ -1,6 +1,7 @@
 package aggregator
 
 import (
+	"context"
 	"testing"
 
 	"github.com/pklimuk/ltv-predictor/fileParser"
@@ -22,7 +23,7 @@ func TestByCampaignAggregator_AggregateRevenues(t *testing.T) {
 		},
 	}
 
-	result, err := aggregator.AggregateRevenues(revenues)
+	result, err := aggregator.AggregateRevenues(context.Background(), revenues)
 	assert.NoError(t, err)
 	for k, v := range result {
 		for i, r := range v.Revenues {
@@ -40,7 +41,7 @@ func TestByCampaignAggregator_AggregateRevenues_DifferentLengthError(t *testing.
 		{CampaignID: "campaign1", Revenues: []decimal.Decimal{decimal.NewFromFloat(150)}, UsersCount: 15},
 	}
 
-	result, err := aggregator.AggregateRevenues(revenues)
+	result, err := aggregator.AggregateRevenues(context.Background(), revenues)
 	assert.Error(t, err)
 	assert.Equal(t, "aggregator error: ltv and revenues slices have different length", err.Error())
 	assert.Nil(t, result)
@@ -50,7 +51,7 @@ func TestByCampaignAggregator_AggregateRevenues_No_Data(t *testing.T) {
 	aggregator := ByCampaignAggregator{}
 	revenues := []fileParser.Revenues{}
 
-	result, err := aggregator.AggregateRevenues(revenues)
+	result, err := aggregator.AggregateRevenues(context.Background(), revenues)
 	assert.Error(t, err)
 	assert.Equal(t, "aggregator error: no data to aggregate", err.Error())
 	assert.Nil(t, result)
@@ -1,35 +1,24 @@
 package aggregator
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pklimuk/ltv-predictor/fileParser"
 )
 
-type ByCountryAggregator struct{}
+// ByCountryAggregator aggregates revenues by country, the input is split across Shards goroutines if Shards is greater than 1
+type ByCountryAggregator struct {
+	Shards int
+}
 
-func (a ByCountryAggregator) AggregateRevenues(revenues []fileParser.Revenues) (AggregatedRevenuesByKey, error) {
-	if len(revenues) == 0 {
-		return nil, fmt.Errorf(ErrAggregatorError.Error(), ErrNoDataToAggregate)
-	}
-	var result AggregatedRevenuesByKey = make(map[string]AggregatedRevenues)
-	for i := 0; i < len(revenues); i++ {
-		rec := revenues[i]
-		if ar, ok := result[rec.Country]; !ok {
-			result[rec.Country] = AggregatedRevenues{
-				Revenues:   rec.Revenues,
-				UsersCount: rec.UsersCount,
-			}
-		} else {
-			err := ar.addRevenues(rec.Revenues)
-			if err != nil {
-				return nil, fmt.Errorf(ErrAggregatorError.Error(), err)
-			}
-			ar.UsersCount += rec.UsersCount
-			result[rec.Country] = ar
-		}
-	}
-	return result, nil
+func (a ByCountryAggregator) AggregateRevenues(ctx context.Context, revenues []fileParser.Revenues) (AggregatedRevenuesByKey, error) {
+	return aggregateSharded(ctx, revenues, a.Shards, byCountry)
+}
+
+// AggregateRevenuesStream aggregates records read from the channel until it is closed
+func (a ByCountryAggregator) AggregateRevenuesStream(ctx context.Context, records <-chan fileParser.Revenues) (AggregatedRevenuesByKey, error) {
+	return aggregateStream(ctx, records, a.Shards, byCountry)
 }
 
 func (a ByCountryAggregator) ConvertAggregatedByKeyRevenuesToLTVs(ar AggregatedRevenuesByKey) (AggregatedLTVsByKey, error) {
@@ -39,3 +28,7 @@ func (a ByCountryAggregator) ConvertAggregatedByKeyRevenuesToLTVs(ar AggregatedR
 	}
 	return ltvs, nil
 }
+
+func byCountry(rec fileParser.Revenues) string {
+	return rec.Country
+}
@@ -1,6 +1,7 @@
 package aggregator
 
 import (
+	"context"
 	"testing"
 
 	"github.com/pklimuk/ltv-predictor/fileParser"
@@ -22,7 +23,7 @@ func TestByCountryAggregator_AggregateRevenues(t *testing.T) {
 		},
 	}
 
-	result, err := aggregator.AggregateRevenues(revenues)
+	result, err := aggregator.AggregateRevenues(context.Background(), revenues)
 	assert.NoError(t, err)
 	for k, v := range result {
 		for i, r := range v.Revenues {
@@ -33,6 +34,19 @@ func TestByCountryAggregator_AggregateRevenues(t *testing.T) {
 	}
 }
 
+func TestByCountryAggregator_AggregateRevenues_InputNotModified(t *testing.T) {
+	aggregator := ByCountryAggregator{}
+	revenues := []fileParser.Revenues{
+		{Country: "US", Revenues: []decimal.Decimal{decimal.NewFromFloat(100), decimal.NewFromFloat(200)}, UsersCount: 10},
+		{Country: "US", Revenues: []decimal.Decimal{decimal.NewFromFloat(150), decimal.NewFromFloat(300)}, UsersCount: 15},
+	}
+
+	_, err := aggregator.AggregateRevenues(context.Background(), revenues)
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromFloat(100).Equal(revenues[0].Revenues[0]))
+	assert.True(t, decimal.NewFromFloat(200).Equal(revenues[0].Revenues[1]))
+}
+
 func TestByCountryAggregator_AggregateRevenues_DifferentLengthError(t *testing.T) {
 	aggregator := ByCountryAggregator{}
 	revenues := []fileParser.Revenues{
@@ -40,7 +54,7 @@ func TestByCountryAggregator_AggregateRevenues_DifferentLengthError(t *testing.T
 		{Country: "US", Revenues: []decimal.Decimal{decimal.NewFromFloat(150)}, UsersCount: 15},
 	}
 
-	result, err := aggregator.AggregateRevenues(revenues)
+	result, err := aggregator.AggregateRevenues(context.Background(), revenues)
 	assert.Error(t, err)
 	assert.Equal(t, "aggregator error: ltv and revenues slices have different length", err.Error())
 	assert.Nil(t, result)
@@ -50,7 +64,7 @@ func TestByCountryAggregator_AggregateRevenues_No_Data(t *testing.T) {
 	aggregator := ByCountryAggregator{}
 	revenues := []fileParser.Revenues{}
 
-	result, err := aggregator.AggregateRevenues(revenues)
+	result, err := aggregator.AggregateRevenues(context.Background(), revenues)
 	assert.Error(t, err)
 	assert.Equal(t, "aggregator error: no data to aggregate", err.Error())
 	assert.Nil(t, result)
@@ -94,3 +108,17 @@ func TestByCountryAggregator_ConvertAggregatedByKeyRevenuesToLTVs_DivisionByZero
 	assert.Equal(t, "aggregator error: division by zero", err.Error())
 	assert.Nil(t, result)
 }
+
+func TestByCountryAggregator_AggregateRevenues_ContextCancelled(t *testing.T) {
+	aggregator := ByCountryAggregator{}
+	revenues := []fileParser.Revenues{
+		{Country: "US", Revenues: []decimal.Decimal{decimal.NewFromFloat(100)}, UsersCount: 10},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := aggregator.AggregateRevenues(ctx, revenues)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "aggregator error: context canceled", err.Error())
+	assert.Nil(t, result)
+}
@@ -0,0 +1,51 @@
+package aggregator
+
+import (
+	"slices"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+)
+
+// Filter selects the records and the keys which are predicted, empty fields do not filter anything
+type Filter struct {
+	Countries []string
+	Campaigns []string
+	// MinUsers is the minimum number of users of an aggregated key
+	MinUsers int64
+}
+
+// Records returns the records of the selected countries and campaigns
+func (f Filter) Records(revenues []fileParser.Revenues) []fileParser.Revenues {
+	if len(f.Countries) == 0 && len(f.Campaigns) == 0 {
+		return revenues
+	}
+	result := make([]fileParser.Revenues, 0, len(revenues))
+	for _, rec := range revenues {
+		if f.Selects(rec) {
+			result = append(result, rec)
+		}
+	}
+	return result
+}
+
+// Selects reports whether the record belongs to the selected countries and campaigns
+func (f Filter) Selects(rec fileParser.Revenues) bool {
+	if len(f.Countries) > 0 && !slices.Contains(f.Countries, rec.Country) {
+		return false
+	}
+	return len(f.Campaigns) == 0 || slices.Contains(f.Campaigns, rec.CampaignID)
+}
+
+// Keys removes the keys with fewer users than MinUsers
+func (f Filter) Keys(ar AggregatedRevenuesByKey) AggregatedRevenuesByKey {
+	if f.MinUsers <= 0 {
+		return ar
+	}
+	result := make(AggregatedRevenuesByKey, len(ar))
+	for k, v := range ar {
+		if v.UsersCount >= f.MinUsers {
+			result[k] = v
+		}
+	}
+	return result
+}
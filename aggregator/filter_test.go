@@ -0,0 +1,40 @@
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_Records(t *testing.T) {
+	revenues := []fileParser.Revenues{
+		{Country: "US", CampaignID: "a"},
+		{Country: "DE", CampaignID: "a"},
+		{Country: "US", CampaignID: "b"},
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []fileParser.Revenues
+	}{
+		{"Empty filter", Filter{}, revenues},
+		{"Countries", Filter{Countries: []string{"US"}}, []fileParser.Revenues{revenues[0], revenues[2]}},
+		{"Countries and campaigns", Filter{Countries: []string{"US", "DE"}, Campaigns: []string{"a"}}, []fileParser.Revenues{revenues[0], revenues[1]}},
+		{"Nothing selected", Filter{Campaigns: []string{"c"}}, []fileParser.Revenues{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.filter.Records(revenues))
+		})
+	}
+}
+
+func TestFilter_Keys(t *testing.T) {
+	ar := AggregatedRevenuesByKey{"US": {UsersCount: 100}, "DE": {UsersCount: 10}}
+
+	assert.Equal(t, ar, Filter{}.Keys(ar))
+	assert.Equal(t, AggregatedRevenuesByKey{"US": {UsersCount: 100}}, Filter{MinUsers: 50}.Keys(ar))
+}
@@ -0,0 +1,131 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/shopspring/decimal"
+)
+
+// keyFunc returns the key a record is aggregated by
+type keyFunc func(rec fileParser.Revenues) string
+
+// aggregateSharded splits the revenues into up to shards contiguous parts, aggregates every part in its own
+// goroutine and merges the partial results, the revenues are aggregated in place if shards is less than 2
+func aggregateSharded(ctx context.Context, revenues []fileParser.Revenues, shards int, key keyFunc) (AggregatedRevenuesByKey, error) {
+	if len(revenues) == 0 {
+		return nil, fmt.Errorf(ErrAggregatorError.Error(), ErrNoDataToAggregate)
+	}
+	shards = max(1, min(shards, len(revenues)))
+	partials := make([]AggregatedRevenuesByKey, shards)
+	errs := make([]error, shards)
+	partSize := (len(revenues) + shards - 1) / shards
+
+	var wg sync.WaitGroup
+	for s := 0; s < shards; s++ {
+		part := revenues[min(s*partSize, len(revenues)):min((s+1)*partSize, len(revenues))]
+		wg.Add(1)
+		go func(s int) {
+			defer wg.Done()
+			partials[s] = make(AggregatedRevenuesByKey)
+			for i, rec := range part {
+				if i%ctxCheckInterval == 0 {
+					if errs[s] = ctx.Err(); errs[s] != nil {
+						return
+					}
+				}
+				if errs[s] = partials[s].add(rec, key); errs[s] != nil {
+					return
+				}
+			}
+		}(s)
+	}
+	wg.Wait()
+	return mergePartials(ctx, partials, errs)
+}
+
+// aggregateStream reads records until the channel is closed and aggregates them in up to shards goroutines.
+// The channel is drained even if aggregation fails, so the producer is never blocked
+func aggregateStream(ctx context.Context, records <-chan fileParser.Revenues, shards int, key keyFunc) (AggregatedRevenuesByKey, error) {
+	shards = max(1, shards)
+	partials := make([]AggregatedRevenuesByKey, shards)
+	errs := make([]error, shards)
+	counts := make([]int, shards)
+
+	var wg sync.WaitGroup
+	for s := 0; s < shards; s++ {
+		partials[s] = make(AggregatedRevenuesByKey)
+		wg.Add(1)
+		go func(s int) {
+			defer wg.Done()
+			for rec := range records {
+				if errs[s] != nil {
+					continue
+				}
+				if counts[s]%ctxCheckInterval == 0 {
+					if errs[s] = ctx.Err(); errs[s] != nil {
+						continue
+					}
+				}
+				counts[s]++
+				errs[s] = partials[s].add(rec, key)
+			}
+		}(s)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	if total == 0 && errors.Join(errs...) == nil {
+		return nil, fmt.Errorf(ErrAggregatorError.Error(), ErrNoDataToAggregate)
+	}
+	return mergePartials(ctx, partials, errs)
+}
+
+// mergePartials merges the partial results into the first one, errors of the parts are reported together
+func mergePartials(ctx context.Context, partials []AggregatedRevenuesByKey, errs []error) (AggregatedRevenuesByKey, error) {
+	// every part stops on cancellation, the error is reported once
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf(ErrAggregatorError.Error(), err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf(ErrAggregatorError.Error(), err)
+	}
+	result := partials[0]
+	for _, partial := range partials[1:] {
+		if err := result.Merge(partial); err != nil {
+			return nil, fmt.Errorf(ErrAggregatorError.Error(), err)
+		}
+	}
+	return result, nil
+}
+
+// add aggregates a single record under its key
+func (ar AggregatedRevenuesByKey) add(rec fileParser.Revenues, key keyFunc) error {
+	k := key(rec)
+	existing, ok := ar[k]
+	if !ok {
+		existing = AggregatedRevenues{
+			// revenues are copied, so the input records are not modified by the following additions
+			Revenues:   append([]decimal.Decimal(nil), rec.Revenues...),
+			UsersCount: rec.UsersCount,
+		}
+	} else {
+		if err := existing.addRevenues(rec.Revenues); err != nil {
+			return err
+		}
+		existing.UsersCount += rec.UsersCount
+	}
+	for name, revenues := range rec.Streams {
+		if err := existing.addStream(name, revenues, rec.UsersCount); err != nil {
+			return err
+		}
+	}
+	ar[k] = existing
+	return nil
+}
@@ -0,0 +1,130 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestRevenues(count int) []fileParser.Revenues {
+	revenues := make([]fileParser.Revenues, count)
+	for i := range revenues {
+		revenues[i] = fileParser.Revenues{
+			CampaignID: fmt.Sprintf("campaign%d", i%50),
+			Country:    fmt.Sprintf("C%d", i%20),
+			Revenues:   []decimal.Decimal{decimal.NewFromInt(int64(i % 7)), decimal.NewFromInt(int64(i % 11)), decimal.NewFromInt(int64(i % 13))},
+			UsersCount: 1,
+		}
+		// only some records have the breakdown, so the users counts of the streams differ from the total ones
+		if i%3 == 0 {
+			revenues[i].Streams = map[string][]decimal.Decimal{
+				"ads": {decimal.NewFromInt(int64(i % 5)), decimal.NewFromInt(int64(i % 5)), decimal.NewFromInt(int64(i % 7))},
+			}
+		}
+	}
+	return revenues
+}
+
+func streamRecords(revenues []fileParser.Revenues) <-chan fileParser.Revenues {
+	records := make(chan fileParser.Revenues)
+	go func() {
+		defer close(records)
+		for _, rec := range revenues {
+			records <- rec
+		}
+	}()
+	return records
+}
+
+func TestAggregateRevenues_ShardsGiveSameResult(t *testing.T) {
+	revenues := createTestRevenues(10000)
+	aggregators := []struct {
+		name       string
+		sequential Aggregator
+		sharded    Aggregator
+	}{
+		{"country", ByCountryAggregator{}, ByCountryAggregator{Shards: 8}},
+		{"campaign", ByCampaignAggregator{}, ByCampaignAggregator{Shards: 8}},
+	}
+
+	for _, a := range aggregators {
+		t.Run(a.name, func(t *testing.T) {
+			expected, err := a.sequential.AggregateRevenues(context.Background(), revenues)
+			assert.NoError(t, err)
+
+			result, err := a.sharded.AggregateRevenues(context.Background(), revenues)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, result)
+
+			stream, err := a.sharded.(StreamAggregator).AggregateRevenuesStream(context.Background(), streamRecords(revenues))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, stream)
+		})
+	}
+}
+
+func TestAggregateRevenues_ShardsMoreThanRecords(t *testing.T) {
+	revenues := createTestRevenues(3)
+
+	result, err := ByCountryAggregator{Shards: 16}.AggregateRevenues(context.Background(), revenues)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+}
+
+func TestAggregateRevenues_ShardedDifferentLength(t *testing.T) {
+	revenues := createTestRevenues(100)
+	revenues[99].Country = revenues[0].Country
+	revenues[99].Revenues = revenues[99].Revenues[:2]
+
+	_, err := ByCountryAggregator{Shards: 4}.AggregateRevenues(context.Background(), revenues)
+	assert.EqualError(t, err, "aggregator error: ltv and revenues slices have different length")
+
+	_, err = ByCountryAggregator{Shards: 4}.AggregateRevenuesStream(context.Background(), streamRecords(revenues))
+	assert.EqualError(t, err, "aggregator error: ltv and revenues slices have different length")
+}
+
+func TestAggregateRevenues_ShardedContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ByCampaignAggregator{Shards: 4}.AggregateRevenues(ctx, createTestRevenues(100))
+	assert.EqualError(t, err, "aggregator error: context canceled")
+
+	_, err = ByCampaignAggregator{Shards: 4}.AggregateRevenuesStream(ctx, streamRecords(createTestRevenues(100)))
+	assert.EqualError(t, err, "aggregator error: context canceled")
+}
+
+func TestAggregateRevenuesStream_NoData(t *testing.T) {
+	_, err := ByCountryAggregator{Shards: 2}.AggregateRevenuesStream(context.Background(), streamRecords(nil))
+
+	assert.EqualError(t, err, "aggregator error: no data to aggregate")
+}
+
+func BenchmarkByCountryAggregator_AggregateRevenues(b *testing.B) {
+	revenues := createTestRevenues(1_000_000)
+	for _, shards := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			a := ByCountryAggregator{Shards: shards}
+			for i := 0; i < b.N; i++ {
+				_, _ = a.AggregateRevenues(context.Background(), revenues)
+			}
+		})
+	}
+}
+
+func BenchmarkByCountryAggregator_AggregateRevenuesStream(b *testing.B) {
+	revenues := createTestRevenues(1_000_000)
+	for _, shards := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			a := ByCountryAggregator{Shards: shards}
+			for i := 0; i < b.N; i++ {
+				_, _ = a.AggregateRevenuesStream(context.Background(), streamRecords(revenues))
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/pklimuk/ltv-predictor/backtester"
+	"github.com/pklimuk/ltv-predictor/config"
+	flagsParser "github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/predictor"
+)
+
+// backtest predicts the last observed day of every key from the earlier days and prints the errors
+func backtest(args []string) int {
+	flags, parseErr := flagsParser.ParseCommand(flagsParser.BacktestCommand, args, os.LookupEnv)
+	if listComponents(flags) {
+		return exitOK
+	}
+	appConfig, err := config.CreateAppConfig(flags)
+	if err = errors.Join(parseErr, err); err != nil {
+		log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+		return exitUsage
+	}
+
+	ctx, cancel := newContext(flags.Timeout)
+	defer cancel()
+
+	revenues, ltvs, err := newProcessor(appConfig).Aggregate(ctx)
+	if err != nil {
+		log.Printf("An error occurred during processing:\n\t%s", indent(err))
+		return exitFailure
+	}
+	result, err := backtester.Backtester{Predictor: appConfig.Predictor, Holdout: flags.Holdout, KeepGoing: flags.KeepGoing}.Run(ctx, revenues, ltvs)
+	var partialErr *predictor.PartialError
+	if err != nil && !errors.As(err, &partialErr) {
+		log.Printf("An error occurred during backtesting:\n\t%s", indent(err))
+		return exitFailure
+	}
+	err = result.Print(os.Stdout)
+	if err != nil {
+		log.Printf("An error occurred during printing:\n\t%s", indent(err))
+		return exitFailure
+	}
+	if partialErr != nil {
+		return exitPartialSuccess
+	}
+	return exitOK
+}
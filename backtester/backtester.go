@@ -0,0 +1,161 @@
+package backtester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrBacktestError     = errors.New("backtest error: %w")
+	ErrHoldoutTooShort   = errors.New("holdout should be greater than 0")
+	ErrNothingToBacktest = errors.New("no key has enough observed days for the holdout")
+)
+
+// Backtester hides the last Holdout observed days of every key, predicts the last observed day from
+// the remaining ones and compares the prediction with the observed value
+type Backtester struct {
+	Predictor predictor.Predictor
+	Holdout   int64
+	// KeepGoing reports keys which could not be predicted as failures instead of failing the whole run
+	KeepGoing bool
+}
+
+// Result contains the comparison for every key sorted by key and the mean errors of all the keys
+type Result struct {
+	Holdout int64
+	Rows    []Row
+	// MAE is the mean absolute error
+	MAE decimal.Decimal
+	// MAPE is the mean absolute percentage error of the keys with a non-zero observed value
+	MAPE     decimal.Decimal
+	Failures predictor.Failures
+}
+
+type Row struct {
+	Key       string
+	Users     int64
+	Day       int64
+	Observed  decimal.Decimal
+	Predicted decimal.Decimal
+	Error     decimal.Decimal
+	// PercentageError is zero if the observed value is zero
+	PercentageError decimal.Decimal
+}
+
+var hundred = decimal.NewFromInt(100)
+
+// Run predicts the last observed day of every key, keys with fewer days than needed are reported as failures
+// if the predictor keeps going on failed keys, otherwise the whole run fails
+func (b Backtester) Run(ctx context.Context, ar aggregator.AggregatedRevenuesByKey, al aggregator.AggregatedLTVsByKey) (*Result, error) {
+	if b.Holdout <= 0 {
+		return nil, fmt.Errorf(ErrBacktestError.Error(), ErrHoldoutTooShort)
+	}
+	// keys are grouped by the number of observed days, as the predicted day is passed for all keys at once
+	byDay := make(map[int64]aggregator.AggregatedLTVsByKey)
+	for k, ltvs := range al {
+		day := int64(len(ltvs))
+		if byDay[day] == nil {
+			byDay[day] = make(aggregator.AggregatedLTVsByKey)
+		}
+		byDay[day][k] = ltvs[:max(0, day-b.Holdout)]
+	}
+
+	result := &Result{Holdout: b.Holdout, Failures: make(predictor.Failures)}
+	var partialErr *predictor.PartialError
+	days := make([]int64, 0, len(byDay))
+	for day := range byDay {
+		days = append(days, day)
+	}
+	slices.Sort(days)
+	for _, day := range days {
+		predictions, err := b.Predictor.Predict(ctx, byDay[day], day)
+		switch {
+		case errors.As(err, &partialErr):
+			for k, failure := range partialErr.Failures {
+				result.Failures[k] = failure
+			}
+		case err != nil && b.KeepGoing && ctx.Err() == nil:
+			// all the keys with the same number of days failed
+			for k := range byDay[day] {
+				result.Failures[k] = err
+			}
+		case err != nil:
+			return nil, fmt.Errorf(ErrBacktestError.Error(), err)
+		}
+		for k, predicted := range predictions {
+			observed := al[k][day-1]
+			row := Row{Key: k, Users: ar[k].UsersCount, Day: day, Observed: observed, Predicted: predicted, Error: predicted.Sub(observed)}
+			if !observed.IsZero() {
+				row.PercentageError = row.Error.Div(observed).Mul(hundred)
+			}
+			result.Rows = append(result.Rows, row)
+		}
+	}
+	if len(result.Rows) == 0 {
+		return nil, fmt.Errorf(ErrBacktestError.Error(), ErrNothingToBacktest)
+	}
+	slices.SortFunc(result.Rows, func(a, b Row) int { return strings.Compare(a.Key, b.Key) })
+
+	var absErrors, absPercentageErrors decimal.Decimal
+	var percentageCount int64
+	for _, row := range result.Rows {
+		absErrors = absErrors.Add(row.Error.Abs())
+		if !row.Observed.IsZero() {
+			absPercentageErrors = absPercentageErrors.Add(row.PercentageError.Abs())
+			percentageCount++
+		}
+	}
+	result.MAE = absErrors.Div(decimal.NewFromInt(int64(len(result.Rows))))
+	if percentageCount > 0 {
+		result.MAPE = absPercentageErrors.Div(decimal.NewFromInt(percentageCount))
+	}
+	if len(result.Failures) > 0 {
+		return result, &predictor.PartialError{Failures: result.Failures}
+	}
+	return result, nil
+}
+
+// Print prints the comparison as an aligned table followed by the mean errors and the failed keys
+func (r Result) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, err := fmt.Fprintln(tw, "KEY\tUSERS\tDAY\tOBSERVED\tPREDICTED\tERROR\tERROR %\t")
+	if err != nil {
+		return err
+	}
+	for _, row := range r.Rows {
+		_, err = fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t\n", row.Key, row.Users, row.Day, row.Observed.StringFixed(2),
+			row.Predicted.StringFixed(2), row.Error.StringFixed(2), row.PercentageError.StringFixed(1))
+		if err != nil {
+			return err
+		}
+	}
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\nHoldout: %d days\nMAE: %s\nMAPE: %s%%\n", r.Holdout, r.MAE.StringFixed(2), r.MAPE.StringFixed(1))
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(r.Failures))
+	for k := range r.Failures {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		_, err = fmt.Fprintf(w, "%s: failed: %v\n", k, r.Failures[k])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package backtester
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func decimals(values ...int64) []decimal.Decimal {
+	result := make([]decimal.Decimal, 0, len(values))
+	for _, v := range values {
+		result = append(result, decimal.NewFromInt(v))
+	}
+	return result
+}
+
+func TestBacktester_Run(t *testing.T) {
+	ar := aggregator.AggregatedRevenuesByKey{"US": {UsersCount: 10}, "DE": {UsersCount: 5}}
+	al := aggregator.AggregatedLTVsByKey{
+		// linear growth is predicted exactly
+		"US": decimals(1, 2, 3, 4, 5, 6, 7),
+		// growth slows down, so the prediction is too high
+		"DE": decimals(2, 4, 6, 8, 8, 9, 10),
+	}
+
+	result, err := Backtester{Predictor: predictor.LinearExtrapolator{}, Holdout: 3}.Run(context.Background(), ar, al)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Rows, 2)
+	assert.Equal(t, "DE", result.Rows[0].Key)
+	assert.True(t, decimal.NewFromInt(14).Equal(result.Rows[0].Predicted))
+	assert.True(t, decimal.NewFromInt(4).Equal(result.Rows[0].Error))
+	assert.True(t, decimal.NewFromInt(40).Equal(result.Rows[0].PercentageError))
+	assert.True(t, decimal.Zero.Equal(result.Rows[1].Error))
+	assert.True(t, decimal.NewFromInt(2).Equal(result.MAE))
+	assert.True(t, decimal.NewFromInt(20).Equal(result.MAPE))
+
+	var buf bytes.Buffer
+	assert.NoError(t, result.Print(&buf))
+	assert.Equal(t, "  KEY  USERS  DAY  OBSERVED  PREDICTED  ERROR  ERROR %\n"+
+		"   DE      5    7     10.00      14.00   4.00     40.0\n"+
+		"   US     10    7      7.00       7.00   0.00      0.0\n"+
+		"\nHoldout: 3 days\nMAE: 2.00\nMAPE: 20.0%\n", buf.String())
+}
+
+func TestBacktester_Run_NotEnoughDays(t *testing.T) {
+	ar := aggregator.AggregatedRevenuesByKey{"US": {UsersCount: 10}, "DE": {UsersCount: 5}}
+	al := aggregator.AggregatedLTVsByKey{"US": decimals(1, 2, 3, 4, 5, 6, 7), "DE": decimals(1, 2, 3)}
+
+	_, err := Backtester{Predictor: predictor.LinearExtrapolator{}, Holdout: 2}.Run(context.Background(), ar, al)
+	assert.EqualError(t, err, "backtest error: predictor error: DE: not enough data to make prediction")
+
+	result, err := Backtester{Predictor: predictor.LinearExtrapolator{KeepGoing: true}, Holdout: 2, KeepGoing: true}.Run(context.Background(), ar, al)
+	assert.ErrorAs(t, err, new(*predictor.PartialError))
+	assert.Len(t, result.Rows, 1)
+	assert.ErrorIs(t, result.Failures["DE"], predictor.ErrNotEnoughData)
+}
+
+func TestBacktester_Run_InvalidHoldout(t *testing.T) {
+	_, err := Backtester{Predictor: predictor.LinearExtrapolator{}}.Run(context.Background(), nil, nil)
+
+	assert.EqualError(t, err, "backtest error: holdout should be greater than 0")
+}
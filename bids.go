@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+
+	"github.com/pklimuk/ltv-predictor/bids"
+	"github.com/pklimuk/ltv-predictor/config"
+	flagsParser "github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/pklimuk/ltv-predictor/roas"
+)
+
+// recommendBids predicts the LTVs of every key and prints the maximum CPI which meets the target ROAS
+func recommendBids(args []string) int {
+	flags, parseErr := flagsParser.ParseCommand(flagsParser.BidsCommand, args, os.LookupEnv)
+	appConfig, err := config.CreateAppConfig(flags)
+	if err = errors.Join(parseErr, config.ValidateBids(flags), err); err != nil {
+		log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+		return exitUsage
+	}
+	var model *predictor.Model
+	if flags.ModelFile != "" {
+		model, err = config.LoadModel(flags)
+		if err != nil {
+			log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+			return exitUsage
+		}
+		appConfig.Predictor = predictor.SavedModel{Model: model, KeepGoing: flags.KeepGoing}
+		appConfig.Model = model.Model
+		appConfig.Streams = nil
+	}
+	// the lower bounds need the parameters of the curves, so they are either loaded or fitted
+	fitter, canFit := appConfig.Predictor.(predictor.Fitter)
+	if flags.Confidence > 0 && model == nil && !canFit {
+		log.Printf("Confidence interval of model %s could not be estimated, it has no parameters to fit", flags.Model)
+		return exitUsage
+	}
+	var spend roas.SpendByKey
+	if flags.Spend != "" {
+		spend, err = config.LoadSpend(flags)
+		if err != nil {
+			log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+			return exitUsage
+		}
+	}
+
+	ctx, cancel := newContext(flags.Timeout)
+	defer cancel()
+
+	p := newProcessor(appConfig)
+	p.Spend = spend
+	report, err := p.Run(ctx)
+	var partialErr *predictor.PartialError
+	if err != nil && !errors.As(err, &partialErr) {
+		log.Printf("An error occurred during processing:\n\t%s", indent(err))
+		return exitFailure
+	}
+	var bounds *bids.Bounds
+	if flags.Confidence > 0 {
+		bounds, err = lowerBounds(ctx, flags, model, fitter, report)
+		if err != nil {
+			log.Printf("An error occurred during estimation of the confidence interval:\n\t%s", indent(err))
+			return exitFailure
+		}
+	}
+	result := bids.Recommend(*report, config.TargetROAS(flags), bounds)
+	write := func(w io.Writer) error {
+		return result.Write(w, flags.Output, int32(flags.Round))
+	}
+	if flags.OutputPath != "" {
+		err = outputPrinter.WriteFileAtomic(flags.OutputPath, write)
+	} else {
+		err = write(os.Stdout)
+	}
+	if err != nil {
+		log.Printf("An error occurred during printing:\n\t%s", indent(err))
+		return exitFailure
+	}
+	if len(result.Failures) > 0 {
+		return exitPartialSuccess
+	}
+	return exitOK
+}
+
+// lowerBounds estimates the lower bounds of the predictions from the parameters of the saved model or fits them
+func lowerBounds(ctx context.Context, flags *flagsParser.Flags, model *predictor.Model, fitter predictor.Fitter, report *outputPrinter.Report) (*bids.Bounds, error) {
+	var params predictor.ParamsByKey
+	if model != nil {
+		params = model.Params
+	} else {
+		var err error
+		params, err = fitter.Fit(ctx, report.LTVs)
+		var partialErr *predictor.PartialError
+		if err != nil && !errors.As(err, &partialErr) {
+			return nil, err
+		}
+	}
+	ltvs, failures, err := predictor.LowerBounds(params, report.LTVs, flags.PredictionLength, flags.Confidence)
+	if err != nil {
+		return nil, err
+	}
+	return &bids.Bounds{Confidence: flags.Confidence, LTVs: ltvs, Failures: failures}, nil
+}
@@ -0,0 +1,94 @@
+package bids
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrBidsError         = errors.New("bids error: %w")
+	ErrUnknownBidsFormat = errors.New("unknown bids format(%s)")
+)
+
+// Bid is the maximum cost of acquiring a user of a key which meets the target ROAS
+type Bid struct {
+	Key       string
+	Users     int64
+	Predicted decimal.Decimal
+	// LTV is the value the bid is based on, the lower bound of the prediction for conservative bids
+	LTV decimal.Decimal
+	// MaxCPI is the break-even cost per install at the target ROAS, it is 0 if the LTV is negative
+	MaxCPI decimal.Decimal
+	// CPI is the current cost per install from the spend file, nil if the key is not in it
+	CPI *decimal.Decimal
+	// Change is the relative change from the current CPI to the maximum one, nil without the current CPI
+	Change *decimal.Decimal
+}
+
+// Bounds are the lower bounds of the predictions conservative bids are based on
+type Bounds struct {
+	Confidence float64
+	LTVs       predictor.PredictedLTVs
+	// Failures contains the keys the lower bound could not be estimated for
+	Failures predictor.Failures
+}
+
+type Result struct {
+	TargetROAS decimal.Decimal
+	// Confidence of the lower bounds, 0 if the bids are based on the predictions
+	Confidence float64
+	// Bids are sorted by key
+	Bids     []Bid
+	Failures predictor.Failures
+}
+
+// TargetROASFromMargin converts the target margin of the revenue into the target ROAS, e.g. 20% margin is 1.25 ROAS
+func TargetROASFromMargin(margin decimal.Decimal) decimal.Decimal {
+	return decimal.NewFromInt(1).Div(decimal.NewFromInt(1).Sub(margin))
+}
+
+// Recommend computes the maximum CPI of every predicted key as the LTV divided by the target ROAS. If bounds are
+// set the lower bounds are used instead of the predictions and the keys without them are reported as failures
+func Recommend(report outputPrinter.Report, targetROAS decimal.Decimal, bounds *Bounds) Result {
+	result := Result{TargetROAS: targetROAS, Failures: make(predictor.Failures)}
+	for k, err := range report.Failures {
+		result.Failures[k] = err
+	}
+	if bounds != nil {
+		result.Confidence = bounds.Confidence
+	}
+	for k, predicted := range report.Predictions {
+		bid := Bid{Key: k, Predicted: predicted, LTV: predicted}
+		if revenues, ok := report.Revenues[k]; ok {
+			bid.Users = revenues.UsersCount
+		}
+		if bounds != nil {
+			lower, ok := bounds.LTVs[k]
+			if !ok {
+				err, failed := bounds.Failures[k]
+				if !failed {
+					err = predictor.ErrKeyNotFitted
+				}
+				result.Failures[k] = err
+				continue
+			}
+			bid.LTV = lower
+		}
+		bid.MaxCPI = decimal.Max(bid.LTV, decimal.Zero).Div(targetROAS)
+		if m, ok := report.ROAS[k]; ok {
+			cpi := m.CPI
+			change := bid.MaxCPI.Div(cpi).Sub(decimal.NewFromInt(1))
+			bid.CPI, bid.Change = &cpi, &change
+		}
+		result.Bids = append(result.Bids, bid)
+	}
+	slices.SortFunc(result.Bids, func(a, b Bid) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return result
+}
@@ -0,0 +1,135 @@
+package bids
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/pklimuk/ltv-predictor/roas"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestReport() outputPrinter.Report {
+	return outputPrinter.Report{
+		Predictions: predictor.PredictedLTVs{
+			"US": decimal.NewFromInt(15),
+			"DE": decimal.NewFromInt(6),
+			"TR": decimal.NewFromInt(3),
+		},
+		Revenues: aggregator.AggregatedRevenuesByKey{
+			"US": {UsersCount: 100},
+			"DE": {UsersCount: 50},
+			"TR": {UsersCount: 20},
+			"FR": {UsersCount: 1},
+		},
+		Failures: predictor.Failures{"FR": predictor.ErrNotEnoughData},
+		ROAS:     roas.MetricsByKey{"US": {CPI: decimal.NewFromInt(8)}},
+	}
+}
+
+func TestTargetROASFromMargin(t *testing.T) {
+	assert.Equal(t, "1.25", TargetROASFromMargin(decimal.NewFromFloat(0.2)).String())
+	assert.Equal(t, "1", TargetROASFromMargin(decimal.Zero).String())
+}
+
+func TestRecommend(t *testing.T) {
+	result := Recommend(createTestReport(), decimal.NewFromFloat(1.5), nil)
+
+	assert.Len(t, result.Bids, 3)
+	assert.Equal(t, predictor.Failures{"FR": predictor.ErrNotEnoughData}, result.Failures)
+	de, tr, us := result.Bids[0], result.Bids[1], result.Bids[2]
+	assert.Equal(t, "DE", de.Key)
+	assert.True(t, decimal.NewFromInt(4).Equal(de.MaxCPI))
+	assert.Nil(t, de.CPI)
+	assert.True(t, decimal.NewFromInt(2).Equal(tr.MaxCPI))
+	assert.Equal(t, int64(100), us.Users)
+	assert.True(t, decimal.NewFromInt(10).Equal(us.MaxCPI))
+	assert.True(t, decimal.NewFromInt(8).Equal(*us.CPI))
+	assert.True(t, decimal.NewFromFloat(0.25).Equal(*us.Change))
+}
+
+func TestRecommend_Bounds(t *testing.T) {
+	bounds := &Bounds{
+		Confidence: 0.9,
+		LTVs:       predictor.PredictedLTVs{"US": decimal.NewFromInt(12), "DE": decimal.NewFromInt(-1)},
+		Failures:   predictor.Failures{"TR": predictor.ErrNotEnoughData},
+	}
+
+	result := Recommend(createTestReport(), decimal.NewFromInt(2), bounds)
+
+	assert.Equal(t, 0.9, result.Confidence)
+	assert.Len(t, result.Bids, 2)
+	// negative lower bounds give no bid
+	assert.True(t, decimal.Zero.Equal(result.Bids[0].MaxCPI))
+	assert.True(t, decimal.NewFromInt(15).Equal(result.Bids[1].Predicted))
+	assert.True(t, decimal.NewFromInt(6).Equal(result.Bids[1].MaxCPI))
+	assert.Equal(t, predictor.ErrNotEnoughData, result.Failures["TR"])
+}
+
+func TestResult_Print(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Recommend(createTestReport(), decimal.NewFromFloat(1.5), nil).Print(&buf, 2)
+
+	assert.NoError(t, err)
+	expected := "Target ROAS: 1.50\n\n" +
+		"  KEY  USERS  PREDICTED  MAX CPI   CPI  CHANGE %\n" +
+		"   DE     50       6.00     4.00                \n" +
+		"   TR     20       3.00     2.00                \n" +
+		"   US    100      15.00    10.00  8.00      25.0\n" +
+		"FR: failed: not enough data to make prediction\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestResult_Print_Bounds(t *testing.T) {
+	report := createTestReport()
+	report.ROAS = nil
+	bounds := &Bounds{Confidence: 0.9, LTVs: predictor.PredictedLTVs{"US": decimal.NewFromInt(12), "DE": decimal.NewFromInt(4), "TR": decimal.NewFromInt(2)}}
+	var buf bytes.Buffer
+
+	err := Recommend(report, decimal.NewFromInt(2), bounds).Print(&buf, 1)
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Bids are based on the lower bound of the 90% confidence interval\n")
+	assert.Contains(t, buf.String(), "KEY  USERS  PREDICTED  LOWER BOUND  MAX CPI\n")
+	assert.Contains(t, buf.String(), "   US    100       15.0         12.0      6.0\n")
+}
+
+func TestResult_WriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Recommend(createTestReport(), decimal.NewFromFloat(1.5), nil).Write(&buf, FormatCSV, 2)
+
+	assert.NoError(t, err)
+	expected := "key,users,predicted,ltv,max_cpi,cpi,change,error\n" +
+		"DE,50,6.00,6.00,4.00,,,\n" +
+		"TR,20,3.00,3.00,2.00,,,\n" +
+		"US,100,15.00,15.00,10.00,8.00,0.2500,\n" +
+		"FR,,,,,,,not enough data to make prediction\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestResult_WriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := Recommend(createTestReport(), decimal.NewFromFloat(1.5), nil).Write(&buf, FormatJSON, 2)
+
+	assert.NoError(t, err)
+	var result jsonResult
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, json.Number("1.5"), result.TargetROAS)
+	assert.Len(t, result.Bids, 3)
+	assert.Nil(t, result.Bids[0].CPI)
+	assert.Equal(t, json.Number("0.25"), *result.Bids[2].Change)
+	assert.Equal(t, []jsonFailure{{Key: "FR", Error: "not enough data to make prediction"}}, result.Failures)
+}
+
+func TestResult_Write_UnknownFormat(t *testing.T) {
+	err := Result{}.Write(&bytes.Buffer{}, "html", 2)
+
+	assert.EqualError(t, err, "unknown bids format(html)")
+}
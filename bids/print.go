@@ -0,0 +1,196 @@
+package bids
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	FormatConsole = "console"
+	FormatCSV     = "csv"
+	FormatJSON    = "json"
+)
+
+// Formats are the output formats of the bids
+var Formats = []string{FormatConsole, FormatCSV, FormatJSON}
+
+type jsonResult struct {
+	TargetROAS json.Number   `json:"targetROAS"`
+	Confidence float64       `json:"confidence,omitempty"`
+	Bids       []jsonBid     `json:"bids"`
+	Failures   []jsonFailure `json:"failures,omitempty"`
+}
+
+type jsonBid struct {
+	Key       string       `json:"key"`
+	Users     int64        `json:"users"`
+	Predicted json.Number  `json:"predicted"`
+	LTV       json.Number  `json:"ltv"`
+	MaxCPI    json.Number  `json:"maxCPI"`
+	CPI       *json.Number `json:"cpi,omitempty"`
+	Change    *json.Number `json:"change,omitempty"`
+}
+
+type jsonFailure struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// Write writes the result in one of the Formats, numbers of the console and csv formats are rounded
+func (r Result) Write(w io.Writer, format string, round int32) error {
+	switch format {
+	case FormatConsole:
+		return r.Print(w, round)
+	case FormatCSV:
+		return r.WriteCSV(w, round)
+	case FormatJSON:
+		return r.WriteJSON(w)
+	default:
+		return fmt.Errorf(ErrUnknownBidsFormat.Error(), format)
+	}
+}
+
+// Print prints the target and the bids as an aligned table followed by the failed keys, the current CPI
+// and its change are printed only if the spend of some keys is known
+func (r Result) Print(w io.Writer, round int32) error {
+	_, err := fmt.Fprintf(w, "Target ROAS: %s\n", r.TargetROAS.StringFixed(2))
+	if err != nil {
+		return err
+	}
+	if r.Confidence > 0 {
+		_, err = fmt.Fprintf(w, "Bids are based on the lower bound of the %s%% confidence interval\n", strconv.FormatFloat(r.Confidence*100, 'f', -1, 64))
+		if err != nil {
+			return err
+		}
+	}
+	withCPI := slices.ContainsFunc(r.Bids, func(b Bid) bool { return b.CPI != nil })
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := "KEY\tUSERS\tPREDICTED\t"
+	if r.Confidence > 0 {
+		header += "LOWER BOUND\t"
+	}
+	header += "MAX CPI\t"
+	if withCPI {
+		header += "CPI\tCHANGE %\t"
+	}
+	// the empty line separates the target from the table
+	_, err = fmt.Fprintln(w)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(tw, header)
+	if err != nil {
+		return err
+	}
+	for _, b := range r.Bids {
+		line := fmt.Sprintf("%s\t%d\t%s\t", b.Key, b.Users, b.Predicted.StringFixed(round))
+		if r.Confidence > 0 {
+			line += b.LTV.StringFixed(round) + "\t"
+		}
+		line += b.MaxCPI.StringFixed(round) + "\t"
+		if withCPI {
+			line += fixed(b.CPI, round) + "\t" + fixed(percent(b.Change), 1) + "\t"
+		}
+		_, err = fmt.Fprintln(tw, line)
+		if err != nil {
+			return err
+		}
+	}
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+	for _, k := range r.failedKeys() {
+		_, err = fmt.Fprintf(w, "%s: failed: %v\n", k, r.Failures[k])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes the bids with a header row, the failed keys have only the key and the error
+func (r Result) WriteCSV(w io.Writer, round int32) error {
+	csvWriter := csv.NewWriter(w)
+	err := csvWriter.Write([]string{"key", "users", "predicted", "ltv", "max_cpi", "cpi", "change", "error"})
+	if err != nil {
+		return err
+	}
+	for _, b := range r.Bids {
+		err = csvWriter.Write([]string{b.Key, strconv.FormatInt(b.Users, 10), b.Predicted.StringFixed(round), b.LTV.StringFixed(round),
+			b.MaxCPI.StringFixed(round), fixed(b.CPI, round), fixed(b.Change, round+2), ""})
+		if err != nil {
+			return err
+		}
+	}
+	for _, k := range r.failedKeys() {
+		err = csvWriter.Write([]string{k, "", "", "", "", "", "", r.Failures[k].Error()})
+		if err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// WriteJSON writes the result as an indented JSON document, numbers are written with full precision
+func (r Result) WriteJSON(w io.Writer) error {
+	result := jsonResult{TargetROAS: json.Number(r.TargetROAS.String()), Confidence: r.Confidence, Bids: make([]jsonBid, 0, len(r.Bids))}
+	for _, b := range r.Bids {
+		result.Bids = append(result.Bids, jsonBid{
+			Key:       b.Key,
+			Users:     b.Users,
+			Predicted: json.Number(b.Predicted.String()),
+			LTV:       json.Number(b.LTV.String()),
+			MaxCPI:    json.Number(b.MaxCPI.String()),
+			CPI:       jsonNumber(b.CPI),
+			Change:    jsonNumber(b.Change),
+		})
+	}
+	for _, k := range r.failedKeys() {
+		result.Failures = append(result.Failures, jsonFailure{Key: k, Error: r.Failures[k].Error()})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+func (r Result) failedKeys() []string {
+	keys := make([]string, 0, len(r.Failures))
+	for k := range r.Failures {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// fixed formats the optional value, it is empty if the value is nil
+func fixed(d *decimal.Decimal, round int32) string {
+	if d == nil {
+		return ""
+	}
+	return d.StringFixed(round)
+}
+
+func percent(d *decimal.Decimal) *decimal.Decimal {
+	if d == nil {
+		return nil
+	}
+	p := d.Mul(decimal.NewFromInt(100))
+	return &p
+}
+
+func jsonNumber(d *decimal.Decimal) *json.Number {
+	if d == nil {
+		return nil
+	}
+	n := json.Number(d.String())
+	return &n
+}
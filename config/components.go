@@ -0,0 +1,178 @@
+package config
+
+import (
+	"io"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/pklimuk/ltv-predictor/predictor"
+)
+
+// Built-in components, in-house ones are registered the same way from init functions of their packages
+func init() {
+	Parsers.Register(Component[ParserFactory]{
+		Name:        ".csv",
+		Description: "CSV file with a header and a row per user",
+		Parameters:  []string{"strict"},
+		Factory: func(path string, r io.Reader, o Options) (fileParser.FileParser, error) {
+			strict, err := o.Bool("strict")
+			if err != nil {
+				return nil, err
+			}
+			return fileParser.CSVParser{Path: path, Reader: r, Strict: strict}, nil
+		},
+	})
+	Parsers.Register(Component[ParserFactory]{
+		Name:        ".json",
+		Description: "JSON array with an object per user",
+		Factory: func(path string, r io.Reader, o Options) (fileParser.FileParser, error) {
+			return fileParser.JSONParser{Path: path, Reader: r}, nil
+		},
+	})
+
+	Aggregators.Register(Component[AggregatorFactory]{
+		Name:        "country",
+		Description: "aggregates users by country",
+		Parameters:  []string{"shards"},
+		Factory: func(o Options) (aggregator.Aggregator, error) {
+			shards, err := o.Int("shards")
+			if err != nil {
+				return nil, err
+			}
+			return aggregator.ByCountryAggregator{Shards: int(shards)}, nil
+		},
+	})
+	Aggregators.Register(Component[AggregatorFactory]{
+		Name:        "campaign",
+		Description: "aggregates users by campaign",
+		Parameters:  []string{"shards"},
+		Factory: func(o Options) (aggregator.Aggregator, error) {
+			shards, err := o.Int("shards")
+			if err != nil {
+				return nil, err
+			}
+			return aggregator.ByCampaignAggregator{Shards: int(shards)}, nil
+		},
+	})
+
+	Predictors.Register(Component[PredictorFactory]{
+		Name:        "linearExtrapolation",
+		Description: "extends the line through the last two different observed LTVs",
+		Parameters:  []string{"workers", "keepGoing"},
+		Factory: func(o Options) (predictor.Predictor, error) {
+			workers, keepGoing, err := workerOptions(o)
+			if err != nil {
+				return nil, err
+			}
+			return predictor.LinearExtrapolator{Workers: workers, KeepGoing: keepGoing}, nil
+		},
+	})
+	Predictors.Register(Component[PredictorFactory]{
+		Name:        "linearRegression",
+		Description: "fits a line to the observed LTVs with the least squares method",
+		Parameters:  []string{"workers", "keepGoing"},
+		Factory: func(o Options) (predictor.Predictor, error) {
+			workers, keepGoing, err := workerOptions(o)
+			if err != nil {
+				return nil, err
+			}
+			return predictor.LinearRegressor{Workers: workers, KeepGoing: keepGoing}, nil
+		},
+	})
+
+	Printers.Register(Component[PrinterFactory]{
+		Name:        "console",
+		Description: "key: value lines or an aligned table with sparklines",
+		Parameters:  []string{"rich", "sort", "round"},
+		Factory: func(o Options) (outputPrinter.OutputPrinter, error) {
+			rich, err := o.Bool("rich")
+			if err != nil {
+				return nil, err
+			}
+			sortBy, round, err := createOrdering(o)
+			if err != nil {
+				return nil, err
+			}
+			return outputPrinter.ConsolePrinter{Rich: rich, SortBy: sortBy, Round: round}, nil
+		},
+	})
+	Printers.Register(Component[PrinterFactory]{
+		Name:        "csv",
+		Description: "CSV table",
+		Parameters:  []string{"columns", "sort", "round"},
+		Factory: func(o Options) (outputPrinter.OutputPrinter, error) {
+			options, err := createTableOptions(o)
+			if err != nil {
+				return nil, err
+			}
+			return outputPrinter.CSVPrinter{Options: *options}, nil
+		},
+	})
+	Printers.Register(Component[PrinterFactory]{
+		Name:        "markdown",
+		Description: "Markdown table",
+		Parameters:  []string{"columns", "sort", "round"},
+		Factory: func(o Options) (outputPrinter.OutputPrinter, error) {
+			options, err := createTableOptions(o)
+			if err != nil {
+				return nil, err
+			}
+			return outputPrinter.MarkdownPrinter{Options: *options}, nil
+		},
+	})
+	Printers.Register(Component[PrinterFactory]{
+		Name:        "html",
+		Description: "self-contained page with a sortable table and SVG charts",
+		Parameters:  []string{"round"},
+		Factory: func(o Options) (outputPrinter.OutputPrinter, error) {
+			round, err := o.Int("round")
+			if err != nil {
+				return nil, err
+			}
+			if round < 0 {
+				return nil, ErrRoundNegative
+			}
+			return outputPrinter.HTMLPrinter{Round: int32(round)}, nil
+		},
+	})
+	Printers.Register(Component[PrinterFactory]{
+		Name:        "json",
+		Description: "JSON document with full precision numbers",
+		Factory: func(o Options) (outputPrinter.OutputPrinter, error) {
+			return outputPrinter.JSONPrinter{}, nil
+		},
+	})
+	Printers.Register(Component[PrinterFactory]{
+		Name:        "openmetrics",
+		Description: "OpenMetrics gauges for the node_exporter textfile collector",
+		Factory: func(o Options) (outputPrinter.OutputPrinter, error) {
+			return outputPrinter.OpenMetricsPrinter{}, nil
+		},
+	})
+	Printers.Register(Component[PrinterFactory]{
+		Name:        "template",
+		Description: "output rendered with a text/template file",
+		Parameters:  []string{"template"},
+		Factory: func(o Options) (outputPrinter.OutputPrinter, error) {
+			printer, err := outputPrinter.NewTemplatePrinter(o.String("template"))
+			if err != nil {
+				return nil, err
+			}
+			return printer, nil
+		},
+	})
+}
+
+// workerOptions returns the number of workers and the keep going mode shared by the built-in models
+func workerOptions(o Options) (int, bool, error) {
+	workers, err := o.Int("workers")
+	if err != nil {
+		return 0, false, err
+	}
+	keepGoing, err := o.Bool("keepGoing")
+	if err != nil {
+		return 0, false, err
+	}
+	return int(workers), keepGoing, nil
+}
@@ -3,13 +3,25 @@ package config
 import (
 	"errors"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/bids"
+	"github.com/pklimuk/ltv-predictor/currency"
 	"github.com/pklimuk/ltv-predictor/fileParser"
 	"github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/notifier"
 	"github.com/pklimuk/ltv-predictor/outputPrinter"
 	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/pklimuk/ltv-predictor/processor"
+	"github.com/pklimuk/ltv-predictor/roas"
+	"github.com/shopspring/decimal"
 )
 
 const (
@@ -23,81 +35,288 @@ var (
 	ErrUnsupportedFileFormat       = errors.New("source file format is not supported")
 	ErrPredictionLengthNotPositive = errors.New("prediction length should be greater than 0")
 	ErrPredictionLengthTooShort    = errors.New("prediction length should be greater than 7")
+	ErrUnknownOutputFormat         = errors.New("unknown output format")
+	ErrRoundNegative               = errors.New("number of decimal places should not be negative")
+	ErrWorkersNegative             = errors.New("number of workers should not be negative")
+	ErrShardsNegative              = errors.New("number of shards should not be negative")
+	ErrMinUsersNegative            = errors.New("minimum number of users should not be negative")
+	ErrTimeoutNegative             = errors.New("timeout should not be negative")
+	ErrModelFileNotSpecified       = errors.New("model file is not specified")
+	ErrStreamModelsWithModelFile   = errors.New("stream models can't be used with the model file, it has no models of the streams")
+	ErrHistoryNotSpecified         = errors.New("history directory is not specified")
+	ErrThresholdNegative           = errors.New("threshold should not be negative")
+	ErrAlertThresholdNegative      = errors.New("alert threshold should not be negative")
+	ErrAlertZScoreNegative         = errors.New("alert z-score should not be negative")
+	ErrAlertWindowNotPositive      = errors.New("alert window should be greater than 0")
+	ErrAlertsWithoutHistory        = errors.New("alerts need the history directory")
+	ErrInvalidWebhookURL           = errors.New("webhook should be an absolute http or https URL")
+	ErrUnknownWebhookFormat        = errors.New("unknown webhook format")
+	ErrWebhookTimeoutNegative      = errors.New("webhook timeout should not be negative")
+	ErrWebhookRetriesNegative      = errors.New("number of webhook retries should not be negative")
+	ErrWebhookTopNegative          = errors.New("number of top predictions of the webhook should not be negative")
+	ErrTargetNotSpecified          = errors.New("target ROAS or target margin should be specified")
+	ErrTargetConflict              = errors.New("only one of target ROAS and target margin should be specified")
+	ErrTargetROASNegative          = errors.New("target ROAS should be greater than 0")
+	ErrTargetMarginOutOfRange      = errors.New("target margin should be between 0 and 1")
+	ErrConfidenceOutOfRange        = errors.New("confidence should be between 0 and 1")
+	ErrInvalidRatesDate            = errors.New("rates date should be in the YYYY-MM-DD format")
+	ErrUnknownTargetCurrency       = errors.New("target currency(%s) has no rate")
+	ErrInvalidStreamModel          = errors.New("stream model should be in the stream=model format(%s)")
+	ErrStreamModelError            = errors.New("model of stream %s: %w")
 )
 
 type AppConfig struct {
 	Parser           fileParser.FileParser
 	Aggregator       aggregator.Aggregator
+	Filter           aggregator.Filter
 	Predictor        predictor.Predictor
+	Model            string
+	AggregateBy      string
+	Source           string
 	PredictionLength int64
 	OutputPrinter    outputPrinter.OutputPrinter
+	OutputPath       string
+	// Streams are the models of the revenue streams, the model of the total is used for the streams without their own one
+	Streams *processor.StreamModels
 }
 
+// CreateAppConfig validates the flags and creates the config, all invalid values are reported at once
 func CreateAppConfig(f *flagsParser.Flags) (*AppConfig, error) {
 	parser, err := createParser(f)
-	if err != nil {
-		return nil, fmt.Errorf(ErrConfigError.Error(), err)
+	return createFlagsRatesAppConfig(f, parser, err)
+}
+
+// CreateReaderAppConfig creates a config which reads the source data from the reader,
+// f.Source is used only to detect the format of the data
+func CreateReaderAppConfig(f *flagsParser.Flags, r io.Reader) (*AppConfig, error) {
+	parser, err := newParser(f, r)
+	return createFlagsRatesAppConfig(f, parser, err)
+}
+
+// CreateParserAppConfig creates a config with the given parser instead of the one detected from f.Source.
+// The revenues are converted with the given rates instead of reading f.Rates, they are not converted if rates is nil
+func CreateParserAppConfig(f *flagsParser.Flags, parser fileParser.FileParser, rates *Rates) (*AppConfig, error) {
+	return createAppConfig(f, parser, nil, rates)
+}
+
+// createFlagsRatesAppConfig creates the config with the rates of f.Rates
+func createFlagsRatesAppConfig(f *flagsParser.Flags, parser fileParser.FileParser, parserErr error) (*AppConfig, error) {
+	var rates *Rates
+	var err error
+	if f.Rates != "" {
+		rates, err = LoadRates(f)
 	}
+	return createAppConfig(f, parser, errors.Join(parserErr, err), rates)
+}
+
+// createAppConfig creates every part of the config and joins the errors of all of them
+func createAppConfig(f *flagsParser.Flags, parser fileParser.FileParser, parserErr error, rates *Rates) (*AppConfig, error) {
+	errs := []error{parserErr}
 
 	aggregator, err := createAggregator(f)
-	if err != nil {
-		return nil, fmt.Errorf(ErrConfigError.Error(), err)
-	}
+	errs = append(errs, err)
 
 	predictor, err := createPredictor(f)
-	if err != nil {
-		return nil, fmt.Errorf(ErrConfigError.Error(), err)
-	}
+	errs = append(errs, err)
 
-	outputPrinter := outputPrinter.ConsolePrinter{}
+	streams, err := createStreamModels(f, predictor)
+	errs = append(errs, err)
 
-	err = validatePredictionLength(f.PredictionLength)
-	if err != nil {
+	outputPrinter, err := createOutputPrinter(f)
+	errs = append(errs, err)
+
+	if parser != nil && rates != nil {
+		parser, err = createCurrencyParser(f, parser, rates)
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, ValidatePredictionLength(f.PredictionLength), validateLimits(f), validateAlerts(f), validateNotifier(f))
+	if err = errors.Join(errs...); err != nil {
 		return nil, fmt.Errorf(ErrConfigError.Error(), err)
 	}
 	return &AppConfig{
 		Parser:           parser,
 		Aggregator:       aggregator,
+		Filter:           createFilter(f),
 		Predictor:        predictor,
+		Model:            f.Model,
+		AggregateBy:      f.AggregateBy,
+		Source:           f.Source,
 		OutputPrinter:    outputPrinter,
+		OutputPath:       f.OutputPath,
 		PredictionLength: f.PredictionLength,
+		Streams:          streams,
 	}, nil
 }
 
 func createParser(f *flagsParser.Flags) (fileParser.FileParser, error) {
-	switch filepath.Ext(f.Source) {
-	case ".csv":
-		return fileParser.CSVParser{Path: f.Source}, nil
-	case ".json":
-		return fileParser.JSONParser{Path: f.Source}, nil
-	default:
-		return nil, ErrUnsupportedFileFormat
+	return newParser(f, nil)
+}
+
+func newParser(f *flagsParser.Flags, r io.Reader) (fileParser.FileParser, error) {
+	c, err := Parsers.Get(filepath.Ext(f.Source))
+	if err != nil {
+		return nil, err
+	}
+	o, err := c.Options(f)
+	if err != nil {
+		return nil, err
+	}
+	return c.Factory(f.Source, r, o)
+}
+
+// Rates are the exchange rates of the rates file and the date they are selected by, they are loaded once
+// and could be used by many configs
+type Rates struct {
+	Rates currency.Rates
+	// Date selects the rates, the latest rates are used if it is zero
+	Date time.Time
+}
+
+// LoadRates reads the rates file of f.Rates and parses f.RatesDate. If only the date is invalid the latest rates
+// are returned together with the error, so the target currency could be validated as well
+func LoadRates(f *flagsParser.Flags) (*Rates, error) {
+	var date time.Time
+	var dateErr error
+	if f.RatesDate != "" {
+		var err error
+		date, err = time.Parse(currency.DateLayout, f.RatesDate)
+		if err != nil {
+			dateErr = ErrInvalidRatesDate
+		}
+	}
+	rates, err := loadRates(f.Rates)
+	if err != nil {
+		return nil, errors.Join(dateErr, err)
+	}
+	return &Rates{Rates: rates, Date: date}, dateErr
+}
+
+// createCurrencyParser wraps the parser, so the revenues are converted into the target currency before the aggregation
+func createCurrencyParser(f *flagsParser.Flags, parser fileParser.FileParser, rates *Rates) (fileParser.FileParser, error) {
+	if _, ok := rates.Rates.ToUSD(f.Currency, rates.Date); !ok {
+		return nil, fmt.Errorf(ErrUnknownTargetCurrency.Error(), f.Currency)
 	}
+	return &currency.Parser{Parser: parser, Rates: rates.Rates, Target: f.Currency, Date: rates.Date, Strict: f.Strict}, nil
+}
+
+func loadRates(path string) (currency.Rates, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return currency.ReadRates(file)
 }
 
 func createAggregator(f *flagsParser.Flags) (aggregator.Aggregator, error) {
-	switch f.AggregateBy {
-	case "country":
-		return aggregator.ByCountryAggregator{}, nil
-	case "campaign":
-		return aggregator.ByCampaignAggregator{}, nil
-	default:
-		return nil, ErrUnknownAggregateBy
+	c, err := Aggregators.Get(f.AggregateBy)
+	if err != nil {
+		return nil, err
 	}
+	o, err := c.Options(f)
+	if err != nil {
+		return nil, err
+	}
+	return c.Factory(o)
 }
 
 func createPredictor(f *flagsParser.Flags) (predictor.Predictor, error) {
-	switch f.Model {
-	case "linearExtrapolation":
-		return predictor.LinearExtrapolator{}, nil
-	case "linearRegression":
-		return predictor.LinearRegressor{}, nil
-	default:
-		return nil, ErrUnknownModel
+	c, err := Predictors.Get(f.Model)
+	if err != nil {
+		return nil, err
+	}
+	o, err := c.Options(f)
+	if err != nil {
+		return nil, err
 	}
+	return c.Factory(o)
 }
 
-func validatePredictionLength(predictionLength int64) error {
+// createStreamModels creates the predictors of the streams listed in f.StreamModels as stream=model pairs
+func createStreamModels(f *flagsParser.Flags, defaultPredictor predictor.Predictor) (*processor.StreamModels, error) {
+	streams := &processor.StreamModels{
+		Default: processor.StreamModel{Model: f.Model, Predictor: defaultPredictor},
+		ByName:  make(map[string]processor.StreamModel),
+	}
+	var errs []error
+	for _, pair := range splitList(f.StreamModels) {
+		name, model, ok := strings.Cut(pair, "=")
+		name, model = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(model)
+		if !ok || name == "" || model == "" {
+			errs = append(errs, fmt.Errorf(ErrInvalidStreamModel.Error(), pair))
+			continue
+		}
+		// the stream model has the same settings as the model of the total
+		streamFlags := *f
+		streamFlags.Model = model
+		p, err := createPredictor(&streamFlags)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(ErrStreamModelError.Error(), name, err))
+			continue
+		}
+		streams.ByName[name] = processor.StreamModel{Model: model, Predictor: p}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return streams, nil
+}
+
+func createOutputPrinter(f *flagsParser.Flags) (outputPrinter.OutputPrinter, error) {
+	name := f.Output
+	if name == "" {
+		name = "console"
+	}
+	c, err := Printers.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	o, err := c.Options(f)
+	if err != nil {
+		return nil, err
+	}
+	return c.Factory(o)
+}
+
+func createTableOptions(o Options) (*outputPrinter.TableOptions, error) {
+	columns := outputPrinter.DefaultColumns
+	if o.String("columns") != "" {
+		var err error
+		columns, err = outputPrinter.ParseColumns(o.String("columns"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	sortBy, round, err := createOrdering(o)
+	if err != nil {
+		return nil, err
+	}
+	return &outputPrinter.TableOptions{Columns: columns, SortBy: sortBy, Round: round}, nil
+}
+
+// createOrdering validates the sort order and the rounding shared by the console and table outputs
+func createOrdering(o Options) (outputPrinter.SortOrder, int32, error) {
+	sortBy := outputPrinter.SortByKey
+	if o.String("sort") != "" {
+		var err error
+		sortBy, err = outputPrinter.ParseSortOrder(o.String("sort"))
+		if err != nil {
+			return "", 0, err
+		}
+	}
+	round, err := o.Int("round")
+	if err != nil {
+		return "", 0, err
+	}
+	if round < 0 {
+		return "", 0, ErrRoundNegative
+	}
+	return sortBy, int32(round), nil
+}
+
+// ValidatePredictionLength checks that the prediction is made for a day after the observed ones
+func ValidatePredictionLength(predictionLength int64) error {
 	if predictionLength <= 0 {
 		return ErrPredictionLengthNotPositive
 	} else if predictionLength <= LTVDataLength {
@@ -105,3 +324,192 @@ func validatePredictionLength(predictionLength int64) error {
 	}
 	return nil
 }
+
+// ValidateHistory checks the settings of the commands working with the history of predictions
+func ValidateHistory(f *flagsParser.Flags) error {
+	var errs []error
+	if f.History == "" {
+		errs = append(errs, ErrHistoryNotSpecified)
+	}
+	if f.Threshold < 0 {
+		errs = append(errs, ErrThresholdNegative)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf(ErrConfigError.Error(), err)
+	}
+	return nil
+}
+
+// ValidateBids checks the settings of the bids command
+func ValidateBids(f *flagsParser.Flags) error {
+	var errs []error
+	switch {
+	case f.TargetROAS == 0 && f.TargetMargin == 0:
+		errs = append(errs, ErrTargetNotSpecified)
+	case f.TargetROAS != 0 && f.TargetMargin != 0:
+		errs = append(errs, ErrTargetConflict)
+	}
+	if f.TargetROAS < 0 {
+		errs = append(errs, ErrTargetROASNegative)
+	}
+	if f.TargetMargin < 0 || f.TargetMargin >= 1 {
+		errs = append(errs, ErrTargetMarginOutOfRange)
+	}
+	if f.Confidence < 0 || f.Confidence >= 1 {
+		errs = append(errs, ErrConfidenceOutOfRange)
+	}
+	if !slices.Contains(bids.Formats, f.Output) {
+		errs = append(errs, ErrUnknownOutputFormat)
+	}
+	if f.Round < 0 {
+		errs = append(errs, ErrRoundNegative)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf(ErrConfigError.Error(), err)
+	}
+	return nil
+}
+
+// TargetROAS returns the target ROAS of the bids, the target margin is converted into it
+func TargetROAS(f *flagsParser.Flags) decimal.Decimal {
+	if f.TargetMargin != 0 {
+		return bids.TargetROASFromMargin(decimal.NewFromFloat(f.TargetMargin))
+	}
+	return decimal.NewFromFloat(f.TargetROAS)
+}
+
+func validateLimits(f *flagsParser.Flags) error {
+	var errs []error
+	if f.Workers < 0 {
+		errs = append(errs, ErrWorkersNegative)
+	}
+	if f.Shards < 0 {
+		errs = append(errs, ErrShardsNegative)
+	}
+	if f.MinUsers < 0 {
+		errs = append(errs, ErrMinUsersNegative)
+	}
+	if f.Timeout < 0 {
+		errs = append(errs, ErrTimeoutNegative)
+	}
+	if f.Threshold < 0 {
+		errs = append(errs, ErrThresholdNegative)
+	}
+	return errors.Join(errs...)
+}
+
+func validateAlerts(f *flagsParser.Flags) error {
+	var errs []error
+	if f.AlertThreshold < 0 {
+		errs = append(errs, ErrAlertThresholdNegative)
+	}
+	if f.AlertZScore < 0 {
+		errs = append(errs, ErrAlertZScoreNegative)
+	}
+	if AlertsEnabled(f) {
+		if f.AlertWindow <= 0 {
+			errs = append(errs, ErrAlertWindowNotPositive)
+		}
+		if f.History == "" {
+			errs = append(errs, ErrAlertsWithoutHistory)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AlertsEnabled reports whether predictions should be compared with the history
+func AlertsEnabled(f *flagsParser.Flags) bool {
+	return f.AlertThreshold > 0 || f.AlertZScore > 0
+}
+
+func validateNotifier(f *flagsParser.Flags) error {
+	if f.Webhook == "" {
+		return nil
+	}
+	var errs []error
+	if u, err := url.Parse(f.Webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, ErrInvalidWebhookURL)
+	}
+	if f.WebhookFormat != notifier.FormatJSON && f.WebhookFormat != notifier.FormatSlack {
+		errs = append(errs, ErrUnknownWebhookFormat)
+	}
+	if f.WebhookTimeout < 0 {
+		errs = append(errs, ErrWebhookTimeoutNegative)
+	}
+	if f.WebhookRetries < 0 {
+		errs = append(errs, ErrWebhookRetriesNegative)
+	}
+	if f.WebhookTop < 0 {
+		errs = append(errs, ErrWebhookTopNegative)
+	}
+	return errors.Join(errs...)
+}
+
+// CreateNotifier creates the notifier posting to f.Webhook, the flags are validated by CreateAppConfig
+func CreateNotifier(f *flagsParser.Flags) notifier.Notifier {
+	return notifier.Notifier{
+		URL:     f.Webhook,
+		Format:  f.WebhookFormat,
+		Timeout: f.WebhookTimeout,
+		Retries: f.WebhookRetries,
+		Backoff: notifier.DefaultBackoff,
+		Top:     f.WebhookTop,
+	}
+}
+
+func createFilter(f *flagsParser.Flags) aggregator.Filter {
+	return aggregator.Filter{
+		Countries: splitList(f.Countries),
+		Campaigns: splitList(f.Campaigns),
+		MinUsers:  f.MinUsers,
+	}
+}
+
+// splitList splits the comma separated list skipping empty values
+func splitList(s string) []string {
+	var result []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// LoadModel reads the model saved by fit from f.ModelFile and checks that it was fitted on the same aggregation
+func LoadModel(f *flagsParser.Flags) (*predictor.Model, error) {
+	if f.ModelFile == "" {
+		return nil, fmt.Errorf(ErrConfigError.Error(), ErrModelFileNotSpecified)
+	}
+	if f.StreamModels != "" {
+		return nil, fmt.Errorf(ErrConfigError.Error(), ErrStreamModelsWithModelFile)
+	}
+	file, err := os.Open(f.ModelFile)
+	if err != nil {
+		return nil, fmt.Errorf(ErrConfigError.Error(), err)
+	}
+	defer file.Close()
+	model, err := predictor.ReadModel(file)
+	if err != nil {
+		return nil, fmt.Errorf(ErrConfigError.Error(), err)
+	}
+	err = model.CheckAggregation(f.AggregateBy)
+	if err != nil {
+		return nil, fmt.Errorf(ErrConfigError.Error(), err)
+	}
+	return model, nil
+}
+
+// LoadSpend reads the spend file of f.Spend, the keys are taken from the column named after the aggregation
+func LoadSpend(f *flagsParser.Flags) (roas.SpendByKey, error) {
+	file, err := os.Open(f.Spend)
+	if err != nil {
+		return nil, fmt.Errorf(ErrConfigError.Error(), err)
+	}
+	defer file.Close()
+	spend, err := roas.ReadSpend(file, f.AggregateBy)
+	if err != nil {
+		return nil, fmt.Errorf(ErrConfigError.Error(), err)
+	}
+	return spend, nil
+}
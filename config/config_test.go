@@ -1,11 +1,17 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/currency"
 	"github.com/pklimuk/ltv-predictor/fileParser"
 	"github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/notifier"
 	"github.com/pklimuk/ltv-predictor/outputPrinter"
 	"github.com/pklimuk/ltv-predictor/predictor"
 	"github.com/stretchr/testify/assert"
@@ -25,12 +31,16 @@ func TestCreateAppConfig(t *testing.T) {
 				AggregateBy:      "country",
 				Model:            "linearExtrapolation",
 				PredictionLength: 10,
+				OutputPath:       "output.txt",
 			},
 			expectedConfig: &AppConfig{
 				Parser:           fileParser.CSVParser{Path: "data.csv"},
 				Aggregator:       aggregator.ByCountryAggregator{},
 				Predictor:        predictor.LinearExtrapolator{},
-				OutputPrinter:    outputPrinter.ConsolePrinter{},
+				OutputPrinter:    outputPrinter.ConsolePrinter{SortBy: outputPrinter.SortByKey},
+				OutputPath:       "output.txt",
+				AggregateBy:      "country",
+				Source:           "data.csv",
 				PredictionLength: 10,
 			},
 			expectedErrString: "",
@@ -97,11 +107,287 @@ func TestCreateAppConfig(t *testing.T) {
 				assert.Equal(t, test.expectedConfig.Predictor, config.Predictor)
 				assert.Equal(t, test.expectedConfig.OutputPrinter, config.OutputPrinter)
 				assert.Equal(t, test.expectedConfig.PredictionLength, config.PredictionLength)
+				assert.Equal(t, test.expectedConfig.OutputPath, config.OutputPath)
+				assert.Equal(t, test.expectedConfig.AggregateBy, config.AggregateBy)
+				assert.Equal(t, test.expectedConfig.Source, config.Source)
 			}
 		})
 	}
 }
 
+func TestCreateReaderAppConfig(t *testing.T) {
+	reader := strings.NewReader("data")
+	flags := &flagsParser.Flags{
+		Source:           "upload.json",
+		AggregateBy:      "campaign",
+		Model:            "linearRegression",
+		PredictionLength: 30,
+		Output:           "json",
+		Workers:          4,
+		Shards:           2,
+	}
+
+	config, err := CreateReaderAppConfig(flags, reader)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fileParser.JSONParser{Path: "upload.json", Reader: reader}, config.Parser)
+	assert.Equal(t, aggregator.ByCampaignAggregator{Shards: 2}, config.Aggregator)
+	assert.Equal(t, predictor.LinearRegressor{Workers: 4}, config.Predictor)
+	assert.Equal(t, outputPrinter.JSONPrinter{}, config.OutputPrinter)
+
+	flags.Source = "upload"
+	_, err = CreateReaderAppConfig(flags, reader)
+	assert.EqualError(t, err, "config error: source file format is not supported")
+}
+
+func TestCreateAppConfig_ComponentSections(t *testing.T) {
+	flags := &flagsParser.Flags{
+		Source:           "data.csv",
+		AggregateBy:      "country",
+		Model:            "linearRegression",
+		PredictionLength: 10,
+		Workers:          2,
+		Output:           "csv",
+		Components: map[string]map[string]string{
+			"linearRegression": {"workers": "8"},
+			"country":          {"shards": "4"},
+			"csv":              {"round": "4", "sort": "predicted"},
+			".csv":             {"strict": "true"},
+		},
+	}
+
+	config, err := CreateAppConfig(flags)
+
+	assert.NoError(t, err)
+	assert.Equal(t, fileParser.CSVParser{Path: "data.csv", Strict: true}, config.Parser)
+	assert.Equal(t, aggregator.ByCountryAggregator{Shards: 4}, config.Aggregator)
+	assert.Equal(t, predictor.LinearRegressor{Workers: 8}, config.Predictor)
+	assert.Equal(t, outputPrinter.CSVPrinter{Options: outputPrinter.TableOptions{
+		Columns: outputPrinter.DefaultColumns, SortBy: outputPrinter.SortByPredicted, Round: 4}}, config.OutputPrinter)
+
+	flags.Components = map[string]map[string]string{"linearRegression": {"depth": "2", "workers": "many"}}
+	_, err = CreateAppConfig(flags)
+	assert.EqualError(t, err, "config error: unknown parameter(depth) of linearRegression")
+
+	delete(flags.Components["linearRegression"], "depth")
+	_, err = CreateAppConfig(flags)
+	assert.EqualError(t, err, "config error: invalid value of parameter workers: strconv.ParseInt: parsing \"many\": invalid syntax")
+}
+
+func TestCreateAppConfig_ReportsAllErrors(t *testing.T) {
+	flags := &flagsParser.Flags{
+		Source:           "data.txt",
+		AggregateBy:      "city",
+		Model:            "unknown",
+		PredictionLength: 5,
+		Output:           "csv",
+		Columns:          "key,unknown",
+		Workers:          -1,
+		MinUsers:         -1,
+	}
+
+	config, err := CreateAppConfig(flags)
+
+	assert.Nil(t, config)
+	assert.ErrorIs(t, err, ErrUnsupportedFileFormat)
+	assert.ErrorIs(t, err, ErrWorkersNegative)
+	assert.EqualError(t, err, "config error: source file format is not supported\n"+
+		"unknown aggregation field\n"+
+		"unknown model\n"+
+		"unknown column(unknown)\n"+
+		"prediction length should be greater than 7\n"+
+		"number of workers should not be negative\n"+
+		"minimum number of users should not be negative")
+}
+
+func TestCreateAppConfig_Filter(t *testing.T) {
+	flags := &flagsParser.Flags{
+		Source:           "data.csv",
+		AggregateBy:      "country",
+		Model:            "linearExtrapolation",
+		PredictionLength: 10,
+		Countries:        "US, DE,",
+		MinUsers:         100,
+	}
+
+	config, err := CreateAppConfig(flags)
+
+	assert.NoError(t, err)
+	assert.Equal(t, aggregator.Filter{Countries: []string{"US", "DE"}, MinUsers: 100}, config.Filter)
+}
+
+func TestLoadModel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"version": 1, "model": "linearRegression", "aggregation": "country",
+		"params": {"US": {"coefficients": ["1", "2"], "days": 7}}}`), 0o600))
+
+	model, err := LoadModel(&flagsParser.Flags{ModelFile: path, AggregateBy: "country"})
+	assert.NoError(t, err)
+	assert.Equal(t, "linearRegression", model.Model)
+	assert.Len(t, model.Params, 1)
+
+	_, err = LoadModel(&flagsParser.Flags{ModelFile: path, AggregateBy: "campaign"})
+	assert.EqualError(t, err, "config error: model error: model was fitted on keys aggregated by country, not by campaign")
+
+	_, err = LoadModel(&flagsParser.Flags{AggregateBy: "country"})
+	assert.EqualError(t, err, "config error: model file is not specified")
+
+	_, err = LoadModel(&flagsParser.Flags{ModelFile: path, AggregateBy: "country", StreamModels: "ads=linearRegression"})
+	assert.EqualError(t, err, "config error: stream models can't be used with the model file, it has no models of the streams")
+}
+
+func TestLoadSpend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spend.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("campaign,country,spend,installs\nc1,US,10,2\nc2,US,20,3\n"), 0o644))
+
+	spend, err := LoadSpend(&flagsParser.Flags{Spend: path, AggregateBy: "country"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), spend["US"].Installs)
+
+	_, err = LoadSpend(&flagsParser.Flags{Spend: path, AggregateBy: "channel"})
+	assert.EqualError(t, err, "config error: spend error: column channel is not found in the header")
+
+	_, err = LoadSpend(&flagsParser.Flags{Spend: filepath.Join(t.TempDir(), "missing.csv"), AggregateBy: "country"})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestValidateBids(t *testing.T) {
+	assert.NoError(t, ValidateBids(&flagsParser.Flags{TargetROAS: 1.5, Output: "csv"}))
+	assert.NoError(t, ValidateBids(&flagsParser.Flags{TargetMargin: 0.2, Confidence: 0.9, Output: "console"}))
+	assert.EqualError(t, ValidateBids(&flagsParser.Flags{Output: "html", Round: -1}),
+		"config error: target ROAS or target margin should be specified\nunknown output format\nnumber of decimal places should not be negative")
+	assert.EqualError(t, ValidateBids(&flagsParser.Flags{TargetROAS: -1, TargetMargin: 1, Confidence: 1, Output: "json"}),
+		"config error: only one of target ROAS and target margin should be specified\ntarget ROAS should be greater than 0\n"+
+			"target margin should be between 0 and 1\nconfidence should be between 0 and 1")
+}
+
+func TestTargetROAS(t *testing.T) {
+	assert.Equal(t, "1.5", TargetROAS(&flagsParser.Flags{TargetROAS: 1.5}).String())
+	assert.Equal(t, "1.25", TargetROAS(&flagsParser.Flags{TargetMargin: 0.2}).String())
+}
+
+func TestValidateHistory(t *testing.T) {
+	assert.NoError(t, ValidateHistory(&flagsParser.Flags{History: "history", Threshold: 0.1}))
+	assert.EqualError(t, ValidateHistory(&flagsParser.Flags{Threshold: -1}),
+		"config error: history directory is not specified\nthreshold should not be negative")
+}
+
+func TestCreateAppConfig_Alerts(t *testing.T) {
+	flags := &flagsParser.Flags{
+		Source:           "data.csv",
+		AggregateBy:      "country",
+		Model:            "linearExtrapolation",
+		PredictionLength: 10,
+		AlertThreshold:   0.3,
+	}
+
+	_, err := CreateAppConfig(flags)
+	assert.EqualError(t, err, "config error: alert window should be greater than 0\nalerts need the history directory")
+
+	flags.History = "history"
+	flags.AlertWindow = 7
+	_, err = CreateAppConfig(flags)
+	assert.NoError(t, err)
+	assert.True(t, AlertsEnabled(flags))
+
+	flags.AlertThreshold = 0
+	flags.AlertZScore = -1
+	_, err = CreateAppConfig(flags)
+	assert.EqualError(t, err, "config error: alert z-score should not be negative")
+}
+
+func TestCreateAppConfig_Notifier(t *testing.T) {
+	flags := &flagsParser.Flags{
+		Source:           "data.csv",
+		AggregateBy:      "country",
+		Model:            "linearExtrapolation",
+		PredictionLength: 10,
+		Webhook:          "hooks.example.com/ltv",
+		WebhookFormat:    "xml",
+		WebhookTimeout:   -1,
+		WebhookRetries:   -1,
+		WebhookTop:       -1,
+	}
+
+	_, err := CreateAppConfig(flags)
+	assert.EqualError(t, err, "config error: webhook should be an absolute http or https URL\nunknown webhook format\n"+
+		"webhook timeout should not be negative\nnumber of webhook retries should not be negative\n"+
+		"number of top predictions of the webhook should not be negative")
+
+	flags.Webhook = "https://hooks.example.com/ltv"
+	flags.WebhookFormat = "slack"
+	flags.WebhookTimeout = time.Second
+	flags.WebhookRetries = 2
+	flags.WebhookTop = 3
+	_, err = CreateAppConfig(flags)
+	assert.NoError(t, err)
+	assert.Equal(t, notifier.Notifier{
+		URL:     "https://hooks.example.com/ltv",
+		Format:  "slack",
+		Timeout: time.Second,
+		Retries: 2,
+		Backoff: notifier.DefaultBackoff,
+		Top:     3,
+	}, CreateNotifier(flags))
+}
+
+func TestCreateAppConfig_Currency(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("date,currency,rate\n2023-10-01,EUR,1.05\n"), 0o644))
+	flags := &flagsParser.Flags{
+		Source:           "data.csv",
+		AggregateBy:      "country",
+		Model:            "linearExtrapolation",
+		PredictionLength: 10,
+		Currency:         "GBP",
+		Rates:            path,
+		RatesDate:        "01.10.2023",
+	}
+
+	_, err := CreateAppConfig(flags)
+	assert.EqualError(t, err, "config error: rates date should be in the YYYY-MM-DD format\ntarget currency(GBP) has no rate")
+
+	flags.Currency = "eur"
+	flags.RatesDate = "2023-10-02"
+	flags.Strict = true
+	appConfig, err := CreateAppConfig(flags)
+	assert.NoError(t, err)
+	parser, ok := appConfig.Parser.(*currency.Parser)
+	assert.True(t, ok)
+	assert.Equal(t, fileParser.CSVParser{Path: "data.csv", Strict: true}, parser.Parser)
+	assert.Equal(t, "eur", parser.Target)
+	assert.True(t, parser.Strict)
+
+	flags.Rates = filepath.Join(t.TempDir(), "missing.csv")
+	_, err = CreateAppConfig(flags)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCreateAppConfig_Streams(t *testing.T) {
+	flags := &flagsParser.Flags{
+		Source:           "data.csv",
+		AggregateBy:      "country",
+		Model:            "linearExtrapolation",
+		PredictionLength: 10,
+		StreamModels:     "Ads=linearRegression, iap",
+	}
+
+	_, err := CreateAppConfig(flags)
+	assert.EqualError(t, err, "config error: stream model should be in the stream=model format(iap)")
+
+	flags.StreamModels = "ads=unknown"
+	_, err = CreateAppConfig(flags)
+	assert.ErrorContains(t, err, "model of stream ads:")
+
+	flags.StreamModels = "Ads=linearRegression"
+	appConfig, err := CreateAppConfig(flags)
+	assert.NoError(t, err)
+	assert.Equal(t, "linearExtrapolation", appConfig.Streams.For("iap").Model)
+	assert.Equal(t, "linearRegression", appConfig.Streams.For("ads").Model)
+	assert.IsType(t, predictor.LinearRegressor{}, appConfig.Streams.For("ads").Predictor)
+}
+
 func TestCreateParser(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -192,6 +478,65 @@ func TestCreatePredictor(t *testing.T) {
 	}
 }
 
+func TestCreateOutputPrinter(t *testing.T) {
+	tests := []struct {
+		name              string
+		flags             *flagsParser.Flags
+		expectedPrinter   outputPrinter.OutputPrinter
+		expectedErrString string
+	}{
+		{"Default output", &flagsParser.Flags{}, outputPrinter.ConsolePrinter{SortBy: outputPrinter.SortByKey}, ""},
+		{"Console output", &flagsParser.Flags{Output: "console", SortBy: "predicted", Round: 3}, outputPrinter.ConsolePrinter{SortBy: outputPrinter.SortByPredicted, Round: 3}, ""},
+		{"Rich console output", &flagsParser.Flags{Output: "console", Rich: true}, outputPrinter.ConsolePrinter{Rich: true, SortBy: outputPrinter.SortByKey}, ""},
+		{
+			"CSV output",
+			&flagsParser.Flags{Output: "csv", Columns: "key,users", SortBy: "users", Round: 3},
+			outputPrinter.CSVPrinter{Options: outputPrinter.TableOptions{
+				Columns: []outputPrinter.Column{outputPrinter.ColumnKey, outputPrinter.ColumnUsers},
+				SortBy:  outputPrinter.SortByUsers,
+				Round:   3,
+			}},
+			"",
+		},
+		{
+			"Markdown output with defaults",
+			&flagsParser.Flags{Output: "markdown", Round: 2},
+			outputPrinter.MarkdownPrinter{Options: outputPrinter.TableOptions{
+				Columns: outputPrinter.DefaultColumns,
+				SortBy:  outputPrinter.SortByKey,
+				Round:   2,
+			}},
+			"",
+		},
+		{"HTML output", &flagsParser.Flags{Output: "html", Round: 1}, outputPrinter.HTMLPrinter{Round: 1}, ""},
+		{"JSON output", &flagsParser.Flags{Output: "json"}, outputPrinter.JSONPrinter{}, ""},
+		{"OpenMetrics output", &flagsParser.Flags{Output: "openmetrics"}, outputPrinter.OpenMetricsPrinter{}, ""},
+		{"Template output without template", &flagsParser.Flags{Output: "template"}, nil, "path to the template file is not specified"},
+		{"Unknown output", &flagsParser.Flags{Output: "xml"}, nil, "unknown output format"},
+		{"Unknown column", &flagsParser.Flags{Output: "csv", Columns: "key,foo"}, nil, "unknown column(foo)"},
+		{"Unknown sort order", &flagsParser.Flags{Output: "csv", SortBy: "foo"}, nil, "unknown sort order(foo)"},
+		{"Unknown sort order of console", &flagsParser.Flags{SortBy: "foo"}, nil, "unknown sort order(foo)"},
+		{"Negative rounding of console", &flagsParser.Flags{Round: -1}, nil, "number of decimal places should not be negative"},
+		{"Negative rounding", &flagsParser.Flags{Output: "markdown", Round: -1}, nil, "number of decimal places should not be negative"},
+		{"Negative rounding in HTML", &flagsParser.Flags{Output: "html", Round: -1}, nil, "number of decimal places should not be negative"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			printer, err := createOutputPrinter(test.flags)
+
+			if test.expectedErrString != "" {
+				assert.Error(t, err)
+				assert.Nil(t, printer)
+				assert.EqualError(t, err, test.expectedErrString)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedPrinter, printer)
+			}
+		})
+	}
+}
+
 func TestValidatePredictionLength(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -206,7 +551,7 @@ func TestValidatePredictionLength(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			err := validatePredictionLength(test.predictionLength)
+			err := ValidatePredictionLength(test.predictionLength)
 
 			if test.expectedErr != nil {
 				assert.Error(t, err)
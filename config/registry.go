@@ -0,0 +1,219 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/pklimuk/ltv-predictor/predictor"
+)
+
+// ListName is the value of the model, aggregate, output and source format settings which lists
+// the registered components instead of running the prediction
+const ListName = "list"
+
+var (
+	ErrUnknownParameter = errors.New("unknown parameter(%s) of %s")
+	ErrInvalidParameter = errors.New("invalid value of parameter %s: %w")
+)
+
+// ParserFactory creates a parser of the source file at the path, the reader is nil if the data is read from the file
+type ParserFactory func(path string, r io.Reader, o Options) (fileParser.FileParser, error)
+
+// AggregatorFactory creates an aggregator configured with the options
+type AggregatorFactory func(o Options) (aggregator.Aggregator, error)
+
+// PredictorFactory creates a predictor configured with the options
+type PredictorFactory func(o Options) (predictor.Predictor, error)
+
+// PrinterFactory creates an output printer configured with the options
+type PrinterFactory func(o Options) (outputPrinter.OutputPrinter, error)
+
+// Component describes a registered factory
+type Component[F any] struct {
+	Name        string
+	Description string
+	// Parameters are the names of the options the component is configured with, the ones named after flags
+	// take their values from the flags
+	Parameters []string
+	Factory    F
+}
+
+// Options are the values of the parameters of a component by name, parameters which are not set are missing
+type Options map[string]string
+
+// String returns the value of the parameter
+func (o Options) String(name string) string {
+	return o[name]
+}
+
+// Int returns the value of the parameter as an integer, 0 if it is not set
+func (o Options) Int(name string) (int64, error) {
+	if o[name] == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(o[name], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf(ErrInvalidParameter.Error(), name, err)
+	}
+	return v, nil
+}
+
+// Float returns the value of the parameter as a float, 0 if it is not set
+func (o Options) Float(name string) (float64, error) {
+	if o[name] == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(o[name], 64)
+	if err != nil {
+		return 0, fmt.Errorf(ErrInvalidParameter.Error(), name, err)
+	}
+	return v, nil
+}
+
+// Bool returns the value of the parameter as a boolean, false if it is not set
+func (o Options) Bool(name string) (bool, error) {
+	if o[name] == "" {
+		return false, nil
+	}
+	v, err := strconv.ParseBool(o[name])
+	if err != nil {
+		return false, fmt.Errorf(ErrInvalidParameter.Error(), name, err)
+	}
+	return v, nil
+}
+
+// Options returns the values of the parameters of the component. Parameters named after flags take the values
+// of the flags, the section of the component in the configuration file overrides them unless the flags are specified
+// on the command line or in the environment. Parameters of the section which the component does not have are reported
+func (c Component[F]) Options(f *flagsParser.Flags) (Options, error) {
+	o := make(Options, len(c.Parameters))
+	for _, name := range c.Parameters {
+		if v, ok := f.Value(name); ok {
+			o[name] = v
+		}
+	}
+	section := f.Components[c.Name]
+	names := make([]string, 0, len(section))
+	for name := range section {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var errs []error
+	for _, name := range names {
+		if !slices.Contains(c.Parameters, name) {
+			errs = append(errs, fmt.Errorf(ErrUnknownParameter.Error(), name, c.Name))
+			continue
+		}
+		if !f.IsSet(name) {
+			o[name] = section[name]
+		}
+	}
+	return o, errors.Join(errs...)
+}
+
+// Registry keeps the factories of one kind of components by name, it is safe for concurrent use
+type Registry[F any] struct {
+	title      string
+	errUnknown error
+	mu         sync.RWMutex
+	components map[string]Component[F]
+}
+
+var (
+	// Parsers are registered by the extension of the source file, e.g. ".csv"
+	Parsers     = NewRegistry[ParserFactory]("Source formats", ErrUnsupportedFileFormat)
+	Aggregators = NewRegistry[AggregatorFactory]("Aggregations", ErrUnknownAggregateBy)
+	Predictors  = NewRegistry[PredictorFactory]("Models", ErrUnknownModel)
+	Printers    = NewRegistry[PrinterFactory]("Output formats", ErrUnknownOutputFormat)
+)
+
+// NewRegistry creates an empty registry, errUnknown is returned for names which are not registered
+func NewRegistry[F any](title string, errUnknown error) *Registry[F] {
+	return &Registry[F]{title: title, errUnknown: errUnknown, components: make(map[string]Component[F])}
+}
+
+// Register adds the component to the registry, it is supposed to be called from init functions
+// and panics if the name is empty or already registered
+func (r *Registry[F]) Register(c Component[F]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if c.Name == "" {
+		panic("config: component name is empty")
+	}
+	if _, ok := r.components[c.Name]; ok {
+		panic(fmt.Sprintf("config: %s is registered twice", c.Name))
+	}
+	r.components[c.Name] = c
+}
+
+// Get returns the component registered with the name
+func (r *Registry[F]) Get(name string) (Component[F], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.components[name]
+	if !ok {
+		return c, r.errUnknown
+	}
+	return c, nil
+}
+
+// Components returns the registered components sorted by name
+func (r *Registry[F]) Components() []Component[F] {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]Component[F], 0, len(r.components))
+	for _, c := range r.components {
+		result = append(result, c)
+	}
+	slices.SortFunc(result, func(a, b Component[F]) int { return strings.Compare(a.Name, b.Name) })
+	return result
+}
+
+// Names returns the names of the registered components in the sorted order
+func (r *Registry[F]) Names() []string {
+	components := r.Components()
+	names := make([]string, 0, len(components))
+	for _, c := range components {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// Describe prints the title of the registry followed by the names, descriptions and parameters of the components
+func (r *Registry[F]) Describe(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%s:\n", r.title)
+	if err != nil {
+		return err
+	}
+	for _, c := range r.Components() {
+		line := fmt.Sprintf("  %s - %s", c.Name, c.Description)
+		if len(c.Parameters) > 0 {
+			line += fmt.Sprintf("(parameters: %s)", strings.Join(c.Parameters, ", "))
+		}
+		_, err = fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Describe prints all the registered components
+func Describe(w io.Writer) error {
+	for _, describe := range []func(io.Writer) error{Predictors.Describe, Aggregators.Describe, Parsers.Describe, Printers.Describe} {
+		err := describe(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
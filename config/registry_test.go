@@ -0,0 +1,132 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/stretchr/testify/assert"
+)
+
+type constantPredictor struct {
+	value predictor.PredictedLTVs
+}
+
+func (p constantPredictor) Predict(ctx context.Context, al aggregator.AggregatedLTVsByKey, predictionLength int64) (predictor.PredictedLTVs, error) {
+	return p.value, nil
+}
+
+func TestRegistry(t *testing.T) {
+	errUnknown := errors.New("unknown test component")
+	registry := NewRegistry[PredictorFactory]("Models", errUnknown)
+	registry.Register(Component[PredictorFactory]{
+		Name:        "constant",
+		Description: "predicts the same value for every key",
+		Parameters:  []string{"workers"},
+		Factory: func(o Options) (predictor.Predictor, error) {
+			return constantPredictor{}, nil
+		},
+	})
+	registry.Register(Component[PredictorFactory]{
+		Name:        "another",
+		Description: "another model",
+		Factory: func(o Options) (predictor.Predictor, error) {
+			return nil, errors.New("not configured")
+		},
+	})
+
+	c, err := registry.Get("constant")
+	assert.NoError(t, err)
+	p, err := c.Factory(Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, constantPredictor{}, p)
+
+	_, err = registry.Get("missing")
+	assert.ErrorIs(t, err, errUnknown)
+
+	assert.Equal(t, []string{"another", "constant"}, registry.Names())
+
+	var buf bytes.Buffer
+	assert.NoError(t, registry.Describe(&buf))
+	assert.Equal(t, "Models:\n"+
+		"  another - another model\n"+
+		"  constant - predicts the same value for every key(parameters: workers)\n", buf.String())
+
+	assert.PanicsWithValue(t, "config: constant is registered twice", func() {
+		registry.Register(Component[PredictorFactory]{Name: "constant"})
+	})
+}
+
+type workersPredictor struct {
+	workers int64
+	scale   float64
+}
+
+func (p workersPredictor) Predict(ctx context.Context, al aggregator.AggregatedLTVsByKey, predictionLength int64) (predictor.PredictedLTVs, error) {
+	return nil, nil
+}
+
+func TestComponent_Options(t *testing.T) {
+	c := Component[PredictorFactory]{
+		Name:       "scaled",
+		Parameters: []string{"workers", "keepGoing", "scale"},
+		Factory: func(o Options) (predictor.Predictor, error) {
+			workers, err := o.Int("workers")
+			if err != nil {
+				return nil, err
+			}
+			scale, err := o.Float("scale")
+			if err != nil {
+				return nil, err
+			}
+			return workersPredictor{workers: workers, scale: scale}, nil
+		},
+	}
+	flags := &flagsParser.Flags{
+		Workers:    4,
+		KeepGoing:  true,
+		Components: map[string]map[string]string{"scaled": {"scale": "1.5"}, "other": {"workers": "2"}},
+	}
+
+	o, err := c.Options(flags)
+
+	// only the parameters of the component are passed to it, the sections of other components are ignored
+	assert.NoError(t, err)
+	assert.Equal(t, Options{"workers": "4", "keepGoing": "true", "scale": "1.5"}, o)
+	p, err := c.Factory(o)
+	assert.NoError(t, err)
+	assert.Equal(t, workersPredictor{workers: 4, scale: 1.5}, p)
+
+	flags.Components["scaled"] = map[string]string{"workers": "8", "scale": "x", "depth": "2"}
+	o, err = c.Options(flags)
+	assert.EqualError(t, err, "unknown parameter(depth) of scaled")
+	assert.Equal(t, "8", o["workers"])
+	_, err = c.Factory(o)
+	assert.EqualError(t, err, "invalid value of parameter scale: strconv.ParseFloat: parsing \"x\": invalid syntax")
+}
+
+func TestBuiltInComponents(t *testing.T) {
+	assert.Equal(t, []string{".csv", ".json"}, Parsers.Names())
+	assert.Equal(t, []string{"campaign", "country"}, Aggregators.Names())
+	// other tests register additional models
+	assert.Subset(t, Predictors.Names(), []string{"linearExtrapolation", "linearRegression"})
+	assert.Equal(t, []string{"console", "csv", "html", "json", "markdown", "openmetrics", "template"}, Printers.Names())
+}
+
+func TestCreateAppConfig_RegisteredPredictor(t *testing.T) {
+	Predictors.Register(Component[PredictorFactory]{
+		Name: "testConstant",
+		Factory: func(o Options) (predictor.Predictor, error) {
+			return constantPredictor{}, nil
+		},
+	})
+
+	config, err := CreateAppConfig(&flagsParser.Flags{Source: "data.csv", AggregateBy: "country", Model: "testConstant", PredictionLength: 10})
+
+	assert.NoError(t, err)
+	assert.Equal(t, constantPredictor{}, config.Predictor)
+}
@@ -0,0 +1,188 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency(%s) of %d record(s)")
+)
+
+// streamBuffer is the number of parsed records which could wait for the conversion
+const streamBuffer = 1024
+
+// Parser converts the revenues read by Parser into the Target currency before they are aggregated. Records without
+// a currency are expected to be in the target one already. Records in currencies without a rate are skipped, logged
+// and counted by UnknownCurrencies, in the strict mode they are reported as errors all at once
+type Parser struct {
+	Parser fileParser.FileParser
+	Rates  Rates
+	Target string
+	// Date selects the rates the revenues are converted with, the latest rates are used if it is zero
+	Date   time.Time
+	Strict bool
+
+	unknown map[string]int
+}
+
+func (p *Parser) Parse(ctx context.Context) ([]fileParser.Revenues, error) {
+	records, err := p.Parser.Parse(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := p.newConverter()
+	converted := make([]fileParser.Revenues, 0, len(records))
+	for _, r := range records {
+		if r, ok := c.convert(r); ok {
+			converted = append(converted, r)
+		}
+	}
+	err = p.finish(c)
+	if err != nil {
+		return nil, err
+	}
+	return converted, nil
+}
+
+// ParseStream converts the records as they pass from the wrapped parser to the channel. If the wrapped parser
+// can't stream its records are parsed at once and sent after the conversion
+func (p *Parser) ParseStream(ctx context.Context, records chan<- fileParser.Revenues) error {
+	defer close(records)
+	sp, ok := p.Parser.(fileParser.StreamParser)
+	if !ok {
+		converted, err := p.Parse(ctx)
+		if err != nil {
+			return err
+		}
+		for _, r := range converted {
+			select {
+			case records <- r:
+			case <-ctx.Done():
+				return fmt.Errorf(fileParser.ErrParsingError.Error(), ctx.Err())
+			}
+		}
+		return nil
+	}
+	parsed := make(chan fileParser.Revenues, streamBuffer)
+	parseErr := make(chan error, 1)
+	go func() {
+		parseErr <- sp.ParseStream(ctx, parsed)
+	}()
+	c := p.newConverter()
+	for r := range parsed {
+		r, ok := c.convert(r)
+		if !ok {
+			continue
+		}
+		select {
+		case records <- r:
+		case <-ctx.Done():
+			// the wrapped parser stops sending once the context is done
+			<-parseErr
+			return fmt.Errorf(fileParser.ErrParsingError.Error(), ctx.Err())
+		}
+	}
+	if err := <-parseErr; err != nil {
+		return err
+	}
+	return p.finish(c)
+}
+
+// UnknownCurrencies returns the number of the skipped records by their currency, it is complete once parsing finishes
+func (p *Parser) UnknownCurrencies() map[string]int {
+	return p.unknown
+}
+
+func (p *Parser) newConverter() *converter {
+	return &converter{
+		rates:   p.Rates,
+		target:  Normalize(p.Target),
+		date:    p.Date,
+		factors: make(map[string]decimal.Decimal),
+		unknown: make(map[string]int),
+	}
+}
+
+// finish keeps the counts of the currencies without a rate and logs them, in the strict mode they are returned as errors
+func (p *Parser) finish(c *converter) error {
+	p.unknown = c.unknown
+	if len(c.unknown) == 0 {
+		return nil
+	}
+	currencies := make([]string, 0, len(c.unknown))
+	for currency := range c.unknown {
+		currencies = append(currencies, currency)
+	}
+	slices.Sort(currencies)
+	var errs []error
+	for _, currency := range currencies {
+		err := fmt.Errorf(ErrUnknownCurrency.Error(), currency, c.unknown[currency])
+		if p.Strict {
+			errs = append(errs, err)
+			continue
+		}
+		log.Printf("Records could not be processed: %v.", err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf(ErrCurrencyError.Error(), err)
+	}
+	return nil
+}
+
+// converter converts the records of one run, the factors of the currencies are cached and the records
+// in currencies without a rate are counted
+type converter struct {
+	rates   Rates
+	target  string
+	date    time.Time
+	factors map[string]decimal.Decimal
+	unknown map[string]int
+}
+
+// convert returns the record in the target currency, false is returned if its currency has no rate
+func (c *converter) convert(r fileParser.Revenues) (fileParser.Revenues, bool) {
+	currency := Normalize(r.Currency)
+	if currency == "" || currency == c.target {
+		return r, true
+	}
+	factor, ok := c.factors[currency]
+	if !ok {
+		factor, ok = c.rates.Factor(currency, c.target, c.date)
+		if !ok {
+			c.unknown[currency]++
+			return r, false
+		}
+		c.factors[currency] = factor
+	}
+	return convert(r, factor, c.target), true
+}
+
+// convert returns a copy of the record, so the revenues of the wrapped parser are not modified
+func convert(r fileParser.Revenues, factor decimal.Decimal, target string) fileParser.Revenues {
+	r.Revenues = multiply(r.Revenues, factor)
+	if r.Streams != nil {
+		streams := make(map[string][]decimal.Decimal, len(r.Streams))
+		for name, revenues := range r.Streams {
+			streams[name] = multiply(revenues, factor)
+		}
+		r.Streams = streams
+	}
+	r.Currency = target
+	return r
+}
+
+func multiply(values []decimal.Decimal, factor decimal.Decimal) []decimal.Decimal {
+	result := make([]decimal.Decimal, 0, len(values))
+	for _, v := range values {
+		result = append(result, v.Mul(factor))
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+package currency
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestRecords() []fileParser.Revenues {
+	return []fileParser.Revenues{
+		{Revenues: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)}, Country: "US", UsersCount: 1},
+		{Revenues: []decimal.Decimal{decimal.NewFromInt(10), decimal.NewFromInt(20)}, Country: "DE", UsersCount: 1, Currency: "eur",
+			Streams: map[string][]decimal.Decimal{"ads": {decimal.NewFromInt(2), decimal.NewFromInt(4)}}},
+		{Revenues: []decimal.Decimal{decimal.NewFromInt(100)}, Country: "TR", UsersCount: 1, Currency: "TRY"},
+		{Revenues: []decimal.Decimal{decimal.NewFromInt(5)}, Country: "GB", UsersCount: 1, Currency: "GBP"},
+		{Revenues: []decimal.Decimal{decimal.NewFromInt(5)}, Country: "GB", UsersCount: 1, Currency: "GBP"},
+	}
+}
+
+func TestParser_Parse(t *testing.T) {
+	rates, _ := ReadRates(strings.NewReader(testRates))
+	records := createTestRecords()
+	parser := Parser{Parser: fileParser.MemoryParser{Revenues: records}, Rates: rates, Target: "usd", Date: date("2023-10-02")}
+
+	result, err := parser.Parse(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+	// records without the currency are kept as they are
+	assert.Equal(t, records[0], result[0])
+	assert.Equal(t, "USD", result[1].Currency)
+	assert.Equal(t, "10.5", result[1].Revenues[0].String())
+	assert.Equal(t, "21", result[1].Revenues[1].String())
+	assert.Equal(t, "4.2", result[1].Streams["ads"][1].String())
+	assert.Equal(t, "3.6", result[2].Revenues[0].String())
+	// the records of the wrapped parser are not modified
+	assert.Equal(t, "10", records[1].Revenues[0].String())
+	assert.Equal(t, "4", records[1].Streams["ads"][1].String())
+}
+
+func TestParser_Parse_LatestRates(t *testing.T) {
+	rates, _ := ReadRates(strings.NewReader(testRates))
+	parser := Parser{Parser: fileParser.MemoryParser{Revenues: createTestRecords()[1:2]}, Rates: rates, Target: "USD"}
+
+	result, err := parser.Parse(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "11", result[0].Revenues[0].String())
+}
+
+func TestParser_Parse_Strict(t *testing.T) {
+	rates, _ := ReadRates(strings.NewReader(testRates))
+	parser := Parser{Parser: fileParser.MemoryParser{Revenues: createTestRecords()}, Rates: rates, Target: "USD", Date: date("2023-09-01"), Strict: true}
+
+	_, err := parser.Parse(context.Background())
+
+	assert.EqualError(t, err, "currency error: unknown currency(EUR) of 1 record(s)\nunknown currency(GBP) of 2 record(s)\nunknown currency(TRY) of 1 record(s)")
+	assert.Equal(t, map[string]int{"EUR": 1, "GBP": 2, "TRY": 1}, parser.UnknownCurrencies())
+}
+
+func TestParser_Parse_ParserError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	parser := Parser{Parser: fileParser.MemoryParser{}, Target: "USD", Date: time.Time{}}
+
+	_, err := parser.Parse(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParser_ParseStream(t *testing.T) {
+	rates, _ := ReadRates(strings.NewReader(testRates))
+	csv := "UserId,CampaignId,Country,Ltv1,Ltv2,Ltv3,Ltv4,Ltv5,Ltv6,Ltv7,Currency\n" +
+		"1,c1,DE,10,10,10,10,10,10,10,EUR\n" +
+		"2,c1,GB,5,5,5,5,5,5,5,GBP\n" +
+		"3,c1,US,1,1,1,1,1,1,1,\n"
+	parser := &Parser{Parser: fileParser.CSVParser{Reader: strings.NewReader(csv)}, Rates: rates, Target: "USD", Date: date("2023-10-02")}
+	records := make(chan fileParser.Revenues, 10)
+
+	err := parser.ParseStream(context.Background(), records)
+
+	assert.NoError(t, err)
+	var result []fileParser.Revenues
+	for r := range records {
+		result = append(result, r)
+	}
+	assert.Len(t, result, 2)
+	assert.Equal(t, "USD", result[0].Currency)
+	assert.Equal(t, "10.5", result[0].Revenues[0].String())
+	assert.Equal(t, "US", result[1].Country)
+	assert.Equal(t, map[string]int{"GBP": 1}, parser.UnknownCurrencies())
+}
+
+func TestParser_ParseStream_NotStreamingParser(t *testing.T) {
+	rates, _ := ReadRates(strings.NewReader(testRates))
+	parser := &Parser{Parser: fileParser.MemoryParser{Revenues: createTestRecords()}, Rates: rates, Target: "USD", Date: date("2023-10-02")}
+	records := make(chan fileParser.Revenues, 10)
+
+	err := parser.ParseStream(context.Background(), records)
+
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, map[string]int{"GBP": 2}, parser.UnknownCurrencies())
+}
+
+func TestParser_ParseStream_Strict(t *testing.T) {
+	rates, _ := ReadRates(strings.NewReader(testRates))
+	csv := "UserId,CampaignId,Country,Ltv1,Ltv2,Ltv3,Ltv4,Ltv5,Ltv6,Ltv7,Currency\n" +
+		"1,c1,GB,5,5,5,5,5,5,5,GBP\n"
+	parser := &Parser{Parser: fileParser.CSVParser{Reader: strings.NewReader(csv)}, Rates: rates, Target: "USD", Strict: true}
+
+	err := parser.ParseStream(context.Background(), make(chan fileParser.Revenues, 10))
+
+	assert.EqualError(t, err, "currency error: unknown currency(GBP) of 1 record(s)")
+}
@@ -0,0 +1,140 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// USD is the base currency of the rates, its rate is always 1
+	USD = "USD"
+	// DateLayout is the layout of the dates of the rates file
+	DateLayout = time.DateOnly
+
+	dateColumn     = "date"
+	currencyColumn = "currency"
+	rateColumn     = "rate"
+)
+
+var (
+	ErrCurrencyError   = errors.New("currency error: %w")
+	ErrRateNotPositive = errors.New("rate should be greater than 0")
+	ErrDuplicateRate   = errors.New("duplicate rate of %s on %s")
+	ErrNoRates         = errors.New("no rate records")
+	ErrEmptyCurrency   = errors.New("currency should not be empty")
+)
+
+// Rate is the value of one unit of a currency in USD starting from Date
+type Rate struct {
+	Date time.Time
+	Rate decimal.Decimal
+}
+
+// Rates are the exchange rates to USD by the upper case currency code, the rates of every currency are sorted by date
+type Rates map[string][]Rate
+
+// ReadRates reads the rates CSV file, the header should contain date(YYYY-MM-DD), currency and rate columns,
+// other columns are ignored. Invalid records are reported all at once
+func ReadRates(r io.Reader) (Rates, error) {
+	result := make(Rates)
+	records, err := fileParser.ReadCSVColumns(r, []string{dateColumn, currencyColumn, rateColumn}, func(fields []string) error {
+		currency, rate, err := convertRateRecord(fields)
+		if err != nil {
+			return err
+		}
+		result[currency] = append(result[currency], rate)
+		return nil
+	})
+	errs := []error{err}
+	currencies := make([]string, 0, len(result))
+	for c := range result {
+		currencies = append(currencies, c)
+	}
+	slices.Sort(currencies)
+	for _, currency := range currencies {
+		rates := result[currency]
+		slices.SortFunc(rates, func(a, b Rate) int { return a.Date.Compare(b.Date) })
+		for i := 1; i < len(rates); i++ {
+			if rates[i].Date.Equal(rates[i-1].Date) {
+				errs = append(errs, fmt.Errorf(ErrDuplicateRate.Error(), currency, rates[i].Date.Format(DateLayout)))
+			}
+		}
+	}
+	if records == 0 && err == nil {
+		errs = append(errs, ErrNoRates)
+	}
+	if err = errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf(ErrCurrencyError.Error(), err)
+	}
+	return result, nil
+}
+
+// ToUSD returns the latest rate of the currency on or before the date, the latest known rate if the date is zero.
+// It reports false if the currency is unknown or has no rates before the date
+func (r Rates) ToUSD(currency string, date time.Time) (decimal.Decimal, bool) {
+	currency = Normalize(currency)
+	if currency == USD {
+		return decimal.NewFromInt(1), true
+	}
+	rates := r[currency]
+	if date.IsZero() {
+		if len(rates) == 0 {
+			return decimal.Decimal{}, false
+		}
+		return rates[len(rates)-1].Rate, true
+	}
+	// rates after the date are not known at the date
+	i, found := slices.BinarySearchFunc(rates, date, func(r Rate, d time.Time) int { return r.Date.Compare(d) })
+	if found {
+		return rates[i].Rate, true
+	}
+	if i == 0 {
+		return decimal.Decimal{}, false
+	}
+	return rates[i-1].Rate, true
+}
+
+// Factor returns the number the amounts in the currency are multiplied by to convert them into the target currency
+func (r Rates) Factor(currency, target string, date time.Time) (decimal.Decimal, bool) {
+	from, ok := r.ToUSD(currency, date)
+	if !ok {
+		return decimal.Decimal{}, false
+	}
+	to, ok := r.ToUSD(target, date)
+	if !ok {
+		return decimal.Decimal{}, false
+	}
+	return from.Div(to), true
+}
+
+// Normalize converts the currency code into the form used by the rates, e.g. " eur" into "EUR"
+func Normalize(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// convertRateRecord converts the date, currency and rate fields of the record
+func convertRateRecord(fields []string) (string, Rate, error) {
+	date, err := time.Parse(DateLayout, strings.TrimSpace(fields[0]))
+	if err != nil {
+		return "", Rate{}, err
+	}
+	currency := Normalize(fields[1])
+	if currency == "" {
+		return "", Rate{}, ErrEmptyCurrency
+	}
+	rate, err := decimal.NewFromString(strings.TrimSpace(fields[2]))
+	if err != nil {
+		return "", Rate{}, err
+	}
+	if !rate.IsPositive() {
+		return "", Rate{}, ErrRateNotPositive
+	}
+	return currency, Rate{Date: date, Rate: rate}, nil
+}
@@ -0,0 +1,104 @@
+package currency
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRates = `date,currency,rate
+2023-10-01,EUR,1.05
+2023-10-03,eur,1.10
+2023-10-01,TRY,0.036
+`
+
+func date(s string) time.Time {
+	d, _ := time.Parse(DateLayout, s)
+	return d
+}
+
+func TestReadRates(t *testing.T) {
+	rates, err := ReadRates(strings.NewReader(testRates))
+
+	assert.NoError(t, err)
+	assert.Len(t, rates, 2)
+	assert.Equal(t, []Rate{
+		{Date: date("2023-10-01"), Rate: decimal.RequireFromString("1.05")},
+		{Date: date("2023-10-03"), Rate: decimal.RequireFromString("1.10")},
+	}, rates["EUR"])
+}
+
+func TestReadRates_MissingColumns(t *testing.T) {
+	_, err := ReadRates(strings.NewReader("day,currency,value\n2023-10-01,EUR,1\n"))
+
+	assert.EqualError(t, err, "currency error: column date is not found in the header\ncolumn rate is not found in the header")
+}
+
+func TestReadRates_InvalidRecords(t *testing.T) {
+	data := `date,currency,rate
+01.10.2023,EUR,1.05
+2023-10-01,,1
+2023-10-01,TRY,0
+2023-10-01,GBP
+2023-10-01,EUR,1.05
+2023-10-01,EUR,1.06
+`
+
+	_, err := ReadRates(strings.NewReader(data))
+
+	assert.EqualError(t, err, "currency error: invalid record on line 2: parsing time \"01.10.2023\" as \"2006-01-02\": cannot parse \"01.10.2023\" as \"2006\"\n"+
+		"invalid record on line 3: currency should not be empty\n"+
+		"invalid record on line 4: rate should be greater than 0\n"+
+		"invalid record on line 5: not enough fields in the record\n"+
+		"duplicate rate of EUR on 2023-10-01")
+}
+
+func TestReadRates_Empty(t *testing.T) {
+	_, err := ReadRates(strings.NewReader("date,currency,rate\n"))
+
+	assert.EqualError(t, err, "currency error: no rate records")
+}
+
+func TestRates_ToUSD(t *testing.T) {
+	rates, _ := ReadRates(strings.NewReader(testRates))
+
+	tests := []struct {
+		name     string
+		currency string
+		date     time.Time
+		expected string
+		ok       bool
+	}{
+		{"latest", "EUR", time.Time{}, "1.1", true},
+		{"on the date", "EUR", date("2023-10-01"), "1.05", true},
+		{"between the dates", "eur", date("2023-10-02"), "1.05", true},
+		{"after the last date", "EUR", date("2023-12-01"), "1.1", true},
+		{"before the first date", "EUR", date("2023-09-30"), "", false},
+		{"base currency", "usd", date("2020-01-01"), "1", true},
+		{"unknown currency", "GBP", time.Time{}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, ok := rates.ToUSD(tt.currency, tt.date)
+
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.expected, rate.String())
+			}
+		})
+	}
+}
+
+func TestRates_Factor(t *testing.T) {
+	rates, _ := ReadRates(strings.NewReader(testRates))
+
+	factor, ok := rates.Factor("TRY", "EUR", date("2023-10-01"))
+
+	assert.True(t, ok)
+	assert.Equal(t, "0.0342857142857143", factor.String())
+	_, ok = rates.Factor("EUR", "GBP", time.Time{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/pklimuk/ltv-predictor/config"
+	flagsParser "github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/history"
+	"github.com/shopspring/decimal"
+)
+
+// diff compares the predictions of two runs saved to the history, the ids of the old and the new runs
+// are taken from the arguments, the last two runs are compared if no ids are given
+func diff(args []string) int {
+	flags, err := flagsParser.ParseCommand(flagsParser.DiffCommand, args, os.LookupEnv)
+	err = errors.Join(err, config.ValidateHistory(flags))
+	if err != nil {
+		log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+		return exitUsage
+	}
+	if len(flags.Args) != 0 && len(flags.Args) != 2 {
+		log.Printf("Either two run ids or none should be specified, got %d", len(flags.Args))
+		return exitUsage
+	}
+
+	store := history.Store{Dir: flags.History}
+	var runs []*history.Run
+	if len(flags.Args) == 0 {
+		runs, err = store.Last(2)
+		if err == nil && len(runs) < 2 {
+			log.Printf("Nothing to compare, %d run(s) are saved to %s", len(runs), flags.History)
+			return exitFailure
+		}
+	} else {
+		for _, id := range flags.Args {
+			var run *history.Run
+			run, err = store.Load(id)
+			if err != nil {
+				break
+			}
+			runs = append(runs, run)
+		}
+	}
+	if err != nil {
+		log.Printf("An error occurred during loading:\n\t%s", indent(err))
+		return exitFailure
+	}
+
+	threshold := decimal.NewFromFloat(flags.Threshold)
+	err = history.PrintDiff(os.Stdout, runs[0], runs[1], history.Diff(runs[0], runs[1], threshold), threshold)
+	if err != nil {
+		log.Printf("An error occurred during printing:\n\t%s", indent(err))
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,65 @@
+package fileParser
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrMissingColumn = errors.New("column %s is not found in the header")
+)
+
+// ReadCSVColumns reads CSV data with a header and calls convert with the fields of every record in the order
+// of the columns. Columns are found by their names ignoring the case, other columns are ignored. Missing columns
+// are reported all at once, as well as the records which are too short or fail to convert, with their line numbers.
+// The number of the records is returned, so the callers could report data without records
+func ReadCSVColumns(r io.Reader, columns []string, convert func(fields []string) error) (int, error) {
+	csvReader := csv.NewReader(r)
+	// the number of fields is checked for every record to report all invalid records
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if err != nil {
+		return 0, err
+	}
+	indexes := make([]int, 0, len(columns))
+	var errs []error
+	for _, name := range columns {
+		i := slices.IndexFunc(header, func(h string) bool { return strings.EqualFold(strings.TrimSpace(h), name) })
+		if i < 0 {
+			errs = append(errs, fmt.Errorf(ErrMissingColumn.Error(), name))
+		}
+		indexes = append(indexes, i)
+	}
+	if err = errors.Join(errs...); err != nil {
+		return 0, err
+	}
+	lastIndex := slices.Max(indexes)
+	records := 0
+	fields := make([]string, len(indexes))
+	// records start on the second line after the header
+	for line := 2; ; line++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return records, err
+		}
+		records++
+		if len(record) <= lastIndex {
+			errs = append(errs, fmt.Errorf(ErrInvalidRecord.Error(), line, ErrNotEnoughFields))
+			continue
+		}
+		for i, index := range indexes {
+			fields[i] = record[index]
+		}
+		if err = convert(fields); err != nil {
+			errs = append(errs, fmt.Errorf(ErrInvalidRecord.Error(), line, err))
+		}
+	}
+	return records, errors.Join(errs...)
+}
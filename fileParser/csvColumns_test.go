@@ -0,0 +1,36 @@
+package fileParser
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCSVColumns(t *testing.T) {
+	data := "Ignored, Name ,VALUE\nx,a,1\ny,b\nz,c,bad\n"
+	var rows [][]string
+
+	records, err := ReadCSVColumns(strings.NewReader(data), []string{"value", "name"}, func(fields []string) error {
+		if fields[0] == "bad" {
+			return errors.New("bad value")
+		}
+		rows = append(rows, slices.Clone(fields))
+		return nil
+	})
+
+	assert.EqualError(t, err, "invalid record on line 3: not enough fields in the record\ninvalid record on line 4: bad value")
+	assert.Equal(t, 3, records)
+	assert.Equal(t, [][]string{{"1", "a"}}, rows)
+}
+
+func TestReadCSVColumns_MissingColumns(t *testing.T) {
+	records, err := ReadCSVColumns(strings.NewReader("name\na\n"), []string{"date", "name", "rate"}, func([]string) error {
+		return nil
+	})
+
+	assert.EqualError(t, err, "column date is not found in the header\ncolumn rate is not found in the header")
+	assert.Equal(t, 0, records)
+}
@@ -1,87 +1,221 @@
 package fileParser
 
 import (
+	"context"
 	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/shopspring/decimal"
 )
 
-// Constants for CSV file
+// Constants for CSV file, the optional columns after the LTVs are found by their names in the header
 const (
 	fieldsNumber    = 10
 	userIDIndex     = 0
 	campaignIDIndex = 1
 	countryIndex    = 2
 	startLtvIndex   = 3
+	ltvDays         = fieldsNumber - startLtvIndex
+	currencyColumn  = "currency"
 )
 
 var (
-	ErrCantReadHeader  = errors.New("can't read header row")
-	ErrNotEnoughFields = errors.New("not enough fields in the record")
+	ErrCantReadHeader   = errors.New("can't read header row")
+	ErrNotEnoughFields  = errors.New("not enough fields in the record")
+	ErrInvalidRecord    = errors.New("invalid record on line %d: %w")
+	ErrIncompleteStream = errors.New("stream %s should have LTV columns of all %d days")
 )
 
+// streamColumn matches the LTV columns of the revenue streams, e.g. IapLtv1 or ads_ltv7
+var streamColumn = regexp.MustCompile(`(?i)^([a-z]+?)_?ltv([1-7])$`)
+
+// csvLayout contains the indexes of the optional columns of the file
+type csvLayout struct {
+	fields int
+	// currency is -1 if there is no currency column
+	currency int
+	// streams contains the indexes of the LTV columns of every stream
+	streams map[string][]int
+}
+
+// csvStreamBuffer is the number of parsed records which could wait for the consumer
+const csvStreamBuffer = 1024
+
+// defaultCSVLayout is the layout of the files without the optional columns
+var defaultCSVLayout = csvLayout{fields: fieldsNumber, currency: -1}
+
+// CSVParser reads revenues from the file at Path or, if it is set, from Reader. Invalid records are
+// skipped and logged, in the strict mode they are reported as errors all at once
 type CSVParser struct {
-	Path string
+	Path   string
+	Reader io.Reader
+	Strict bool
+}
+
+func (p CSVParser) Parse(ctx context.Context) ([]Revenues, error) {
+	records := make(chan Revenues, csvStreamBuffer)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- p.ParseStream(ctx, records)
+	}()
+	revenues := make([]Revenues, 0)
+	for rec := range records {
+		revenues = append(revenues, rec)
+	}
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return revenues, nil
 }
 
-func (p CSVParser) Parse() ([]Revenues, error) {
-	records, err := parseCSV(p.Path)
+// ParseStream sends the records to the channel as they are read and closes it at the end. In the strict mode
+// the invalid records are still reported all at once after the whole file is read
+func (p CSVParser) ParseStream(ctx context.Context, records chan<- Revenues) error {
+	defer close(records)
+	r := p.Reader
+	if r == nil {
+		file, err := os.Open(p.Path)
+		if err != nil {
+			return fmt.Errorf(ErrParsingError.Error(), fmt.Errorf(ErrCantOpenFile.Error(), p.Path))
+		}
+		defer file.Close()
+		r = file
+	}
+	csvReader := csv.NewReader(contextReader{ctx: ctx, r: r})
+	header, err := csvReader.Read()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf(ErrParsingError.Error(), ctxErr)
+		}
+		return fmt.Errorf(ErrParsingError.Error(), ErrCantReadHeader)
+	}
+	layout, err := parseCSVHeader(header)
 	if err != nil {
-		return nil, fmt.Errorf(ErrParsingError.Error(), err)
+		return fmt.Errorf(ErrParsingError.Error(), err)
 	}
-	revenues := make([]Revenues, 0, len(records))
-	for _, record := range records {
-		revenue, err := convertCSVRecordToRevenues(record)
+	var errs []error
+	// records start on the second line after the header
+	for line := 2; ; line++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf(ErrParsingError.Error(), fmt.Errorf(ErrCantReadData.Error(), err))
+		}
+		revenue, err := convertCSVRecordToRevenues(record, layout)
+		if err != nil && p.Strict {
+			errs = append(errs, fmt.Errorf(ErrInvalidRecord.Error(), line, err))
+			continue
+		}
 		// If there is an error, we just skip the record and log it, to not break the whole process
 		if err != nil {
 			log.Printf("Record %v contains errors(%v) and could not be processed.", record, err)
 			continue
 		}
-		revenues = append(revenues, *revenue)
+		select {
+		case records <- *revenue:
+		case <-ctx.Done():
+			return fmt.Errorf(ErrParsingError.Error(), ctx.Err())
+		}
 	}
-	return revenues, nil
+	if err = errors.Join(errs...); err != nil {
+		return fmt.Errorf(ErrParsingError.Error(), err)
+	}
+	return nil
 }
 
-func parseCSV(path string) ([][]string, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf(ErrCantOpenFile.Error(), path)
+// parseCSVHeader finds the currency column and the LTV columns of the revenue streams after the LTVs,
+// other columns are ignored
+func parseCSVHeader(header []string) (csvLayout, error) {
+	layout := csvLayout{fields: max(len(header), fieldsNumber), currency: -1}
+	for i := fieldsNumber; i < len(header); i++ {
+		name := strings.TrimSpace(header[i])
+		if strings.EqualFold(name, currencyColumn) {
+			layout.currency = i
+			continue
+		}
+		match := streamColumn.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		stream := strings.ToLower(match[1])
+		if layout.streams == nil {
+			layout.streams = make(map[string][]int)
+		}
+		if layout.streams[stream] == nil {
+			layout.streams[stream] = make([]int, ltvDays)
+		}
+		day, _ := strconv.Atoi(match[2])
+		layout.streams[stream][day-1] = i
 	}
-	defer file.Close()
-
-	csvReader := csv.NewReader(file)
-	// Read and skip header row
-	_, err = csvReader.Read()
-	if err != nil {
-		return nil, ErrCantReadHeader
+	streams := make([]string, 0, len(layout.streams))
+	for stream := range layout.streams {
+		streams = append(streams, stream)
 	}
-	records, err := csvReader.ReadAll()
-	if err != nil {
-		return nil, fmt.Errorf(ErrCantReadData.Error(), err)
+	slices.Sort(streams)
+	var errs []error
+	for _, stream := range streams {
+		// the optional columns go after the LTVs, so the zero index means the column is missing
+		if slices.Contains(layout.streams[stream], 0) {
+			errs = append(errs, fmt.Errorf(ErrIncompleteStream.Error(), stream, ltvDays))
+		}
 	}
-	return records, nil
+	return layout, errors.Join(errs...)
 }
 
-func convertCSVRecordToRevenues(record []string) (*Revenues, error) {
-	if len(record) != fieldsNumber {
+func convertCSVRecordToRevenues(record []string, layout csvLayout) (*Revenues, error) {
+	if len(record) != layout.fields {
 		return nil, ErrNotEnoughFields
 	}
 	campaignID := record[campaignIDIndex]
 	country := record[countryIndex]
-	var ltv = make([]decimal.Decimal, 0, len(record)-startLtvIndex)
-	for i := 3; i < len(record); i++ {
-		ltvValue, err := decimal.NewFromString(record[i])
+	var currency string
+	if layout.currency >= 0 {
+		currency = strings.TrimSpace(record[layout.currency])
+	}
+	ltv, err := convertCSVLtvs(record[startLtvIndex:fieldsNumber])
+	if err != nil {
+		return nil, err
+	}
+	var streams map[string][]decimal.Decimal
+	for stream, indexes := range layout.streams {
+		values := make([]string, 0, len(indexes))
+		for _, i := range indexes {
+			values = append(values, record[i])
+		}
+		streamLtv, err := convertCSVLtvs(values)
+		if err != nil {
+			return nil, err
+		}
+		if streams == nil {
+			streams = make(map[string][]decimal.Decimal, len(layout.streams))
+		}
+		streams[stream] = streamLtv
+	}
+	return &Revenues{Revenues: ltv, Country: country, CampaignID: campaignID, UsersCount: 1, Currency: currency, Streams: streams}, nil
+}
+
+// convertCSVLtvs converts the LTVs of the days, missing days are filled by normalizeLtv
+func convertCSVLtvs(values []string) ([]decimal.Decimal, error) {
+	ltv := make([]decimal.Decimal, 0, len(values))
+	for _, v := range values {
+		ltvValue, err := decimal.NewFromString(strings.TrimSpace(v))
 		if err != nil {
 			return nil, err
 		}
 		ltv = append(ltv, ltvValue)
-		normalizeLtv(ltv)
 	}
-	return &Revenues{Revenues: ltv, Country: country, CampaignID: campaignID, UsersCount: 1}, nil
+	normalizeLtv(ltv)
+	return ltv, nil
 }
 
 // normalizeLtv replaces zero values with the previous non-zero value
@@ -1,7 +1,10 @@
 package fileParser
 
 import (
+	"context"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/shopspring/decimal"
@@ -26,6 +29,15 @@ func removeTempCSVFile(path string) error {
 	return os.Remove(path)
 }
 
+func sortedStreams(streams map[string][]decimal.Decimal) []string {
+	names := make([]string, 0, len(streams))
+	for name := range streams {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
 func TestCSVParser_Parse(t *testing.T) {
 	// Sample CSV data
 	csvData := `UserId,CampaignId,Country,Ltv1,Ltv2,Ltv3,Ltv4,Ltv5,Ltv6,Ltv7
@@ -68,7 +80,7 @@ func TestCSVParser_Parse(t *testing.T) {
 		},
 	}
 
-	revenues, err := parser.Parse()
+	revenues, err := parser.Parse(context.Background())
 	if err != nil {
 		t.Fatalf("Error parsing CSV: %v", err)
 	}
@@ -80,7 +92,7 @@ func TestCSVParser_Parse_InvalidFile(t *testing.T) {
 		Path: "invalid_file.csv",
 	}
 
-	revenues, err := parser.Parse()
+	revenues, err := parser.Parse(context.Background())
 	assert.Error(t, err)
 	assert.Equal(t, "parsing error: can't open specified file(invalid_file.csv)", err.Error())
 	assert.Nil(t, revenues)
@@ -101,7 +113,7 @@ func TestCSVParser_Parse_EmptyCSV(t *testing.T) {
 		Path: tempEmptyCSVFilePath,
 	}
 
-	revenues, err := parser.Parse()
+	revenues, err := parser.Parse(context.Background())
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, ErrCantReadHeader)
 	assert.Nil(t, revenues)
@@ -123,7 +135,7 @@ func TestCSVParser_Parse_InvalidCSV_WrongSeparator(t *testing.T) {
 		Path: tempEmptyCSVFilePath,
 	}
 
-	revenues, err := parser.Parse()
+	revenues, err := parser.Parse(context.Background())
 	assert.Error(t, err)
 	assert.Nil(t, revenues)
 }
@@ -133,7 +145,7 @@ func TestConvertCSVRecordToRevenues_InvalidLTV(t *testing.T) {
 	invalidLTVRecord := []string{"1", "campaign_id", "US", "1.5499697874482206", "2.252663605698363", "2.2986363323452683",
 		"2.8840086432719603", "3.696001808588305", "invalid_ltv", "4.696001808588305"}
 
-	revenues, err := convertCSVRecordToRevenues(invalidLTVRecord)
+	revenues, err := convertCSVRecordToRevenues(invalidLTVRecord, defaultCSVLayout)
 	assert.Error(t, err)
 	assert.Equal(t, "can't convert invalid_ltv to decimal", err.Error())
 	assert.Nil(t, revenues)
@@ -143,7 +155,91 @@ func TestConvertCSVRecordToRevenues_MissingFields(t *testing.T) {
 	// Test conversion with missing fields in CSV record
 	missingFieldsRecord := []string{"1", "campaign_id"}
 
-	revenues, err := convertCSVRecordToRevenues(missingFieldsRecord)
+	revenues, err := convertCSVRecordToRevenues(missingFieldsRecord, defaultCSVLayout)
 	assert.Error(t, err, ErrNotEnoughFields)
 	assert.Nil(t, revenues)
 }
+
+func TestCSVParser_Parse_Reader(t *testing.T) {
+	csvData := `UserId,CampaignId,Country,Ltv1,Ltv2,Ltv3,Ltv4,Ltv5,Ltv6,Ltv7
+1,campaign,TR,1,2,3,4,5,6,7`
+	parser := CSVParser{Reader: strings.NewReader(csvData)}
+
+	revenues, err := parser.Parse(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, revenues, 1)
+	assert.Equal(t, "TR", revenues[0].Country)
+	assert.Equal(t, "campaign", revenues[0].CampaignID)
+}
+
+func TestCSVParser_Parse_Currency(t *testing.T) {
+	csvData := `UserId,CampaignId,Country,Ltv1,Ltv2,Ltv3,Ltv4,Ltv5,Ltv6,Ltv7,Currency
+1,campaign,TR,1,2,3,4,5,6,7, TRY
+2,campaign,US,1,2,3,4,5,6,7,`
+	parser := CSVParser{Reader: strings.NewReader(csvData)}
+
+	revenues, err := parser.Parse(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, revenues, 2)
+	assert.Equal(t, "TRY", revenues[0].Currency)
+	assert.Len(t, revenues[0].Revenues, 7)
+	assert.Equal(t, "", revenues[1].Currency)
+}
+
+func TestCSVParser_Parse_Streams(t *testing.T) {
+	csvData := `UserId,CampaignId,Country,Ltv1,Ltv2,Ltv3,Ltv4,Ltv5,Ltv6,Ltv7,Currency,IapLtv1,IapLtv2,IapLtv3,IapLtv4,IapLtv5,IapLtv6,IapLtv7,` +
+		`ads_ltv1,ads_ltv2,ads_ltv3,ads_ltv4,ads_ltv5,ads_ltv6,ads_ltv7,Note
+1,campaign,TR,2,3,4,5,6,7,8,TRY,1,1,1,1,1,1,1,1,2,3,4,5,0,0,first`
+	parser := CSVParser{Reader: strings.NewReader(csvData)}
+
+	revenues, err := parser.Parse(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, revenues, 1)
+	assert.Equal(t, "TRY", revenues[0].Currency)
+	assert.Equal(t, []string{"ads", "iap"}, sortedStreams(revenues[0].Streams))
+	assert.Equal(t, "8", revenues[0].Revenues[6].String())
+	assert.Equal(t, "1", revenues[0].Streams["iap"][6].String())
+	// zero LTVs of the streams are replaced with the previous values as well
+	assert.Equal(t, "5", revenues[0].Streams["ads"][6].String())
+}
+
+func TestCSVParser_Parse_IncompleteStreams(t *testing.T) {
+	csvData := `UserId,CampaignId,Country,Ltv1,Ltv2,Ltv3,Ltv4,Ltv5,Ltv6,Ltv7,IapLtv1,IapLtv2
+1,campaign,TR,2,3,4,5,6,7,8,1,1`
+	parser := CSVParser{Reader: strings.NewReader(csvData)}
+
+	revenues, err := parser.Parse(context.Background())
+
+	assert.Nil(t, revenues)
+	assert.EqualError(t, err, "parsing error: stream iap should have LTV columns of all 7 days")
+}
+
+func TestCSVParser_Parse_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	parser := CSVParser{Reader: strings.NewReader("UserId,CampaignId,Country,Ltv1,Ltv2,Ltv3,Ltv4,Ltv5,Ltv6,Ltv7\n")}
+
+	revenues, err := parser.Parse(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, revenues)
+}
+
+func TestCSVParser_Parse_Strict(t *testing.T) {
+	data := "UserId,CampaignId,Country,Ltv1,Ltv2,Ltv3,Ltv4,Ltv5,Ltv6,Ltv7\n" +
+		"1,a,US,1,2,x,4,5,6,7\n" +
+		"2,a,US,1,2,3,4,5,6,7\n" +
+		"3,a,US,1,2,3,4,5,6,y\n"
+
+	revenues, err := CSVParser{Reader: strings.NewReader(data)}.Parse(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, revenues, 1)
+
+	revenues, err = CSVParser{Reader: strings.NewReader(data), Strict: true}.Parse(context.Background())
+	assert.Nil(t, revenues)
+	assert.EqualError(t, err, "parsing error: invalid record on line 2: can't convert x to decimal\n"+
+		"invalid record on line 4: can't convert y to decimal")
+}
@@ -1,21 +1,27 @@
 package fileParser
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/shopspring/decimal"
 )
 
 var (
-	ErrJSONParsing = errors.New("json parsing error: %w")
+	ErrJSONParsing   = errors.New("json parsing error: %w")
+	ErrInvalidStream = errors.New("stream %s of record %d should have %d LTVs")
 )
 
+// JSONParser reads revenues from the file at Path or, if it is set, from Reader
 type JSONParser struct {
-	Path string
+	Path   string
+	Reader io.Reader
 }
 
 type jsonData struct {
@@ -29,17 +35,31 @@ type jsonData struct {
 	Ltv6       decimal.Decimal `json:"Ltv6"`
 	Ltv7       decimal.Decimal `json:"Ltv7"`
 	Users      int64           `json:"Users"`
+	Currency   string          `json:"Currency"`
+	// Streams contain the LTVs of the days by the revenue type, e.g. {"iap": [0.1, 0.2, ...]}
+	Streams map[string][]decimal.Decimal `json:"Streams"`
 }
 
-func (p JSONParser) Parse() ([]Revenues, error) {
-	data, err := parseJSONFile(p.Path)
+func (p JSONParser) Parse(ctx context.Context) ([]Revenues, error) {
+	var data []jsonData
+	var err error
+	if p.Reader != nil {
+		data, err = readJSON(ctx, p.Reader)
+	} else {
+		data, err = parseJSONFile(ctx, p.Path)
+	}
 	if err != nil {
 		return nil, fmt.Errorf(ErrParsingError.Error(), err)
 	}
 	revenues := make([]Revenues, 0, len(data))
-	for _, rec := range data {
+	var errs []error
+	for i, rec := range data {
+		errs = append(errs, validateJSONStreams(i, rec))
 		revenues = append(revenues, convertJSONDataToRevenue(rec))
 	}
+	if err = errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf(ErrParsingError.Error(), err)
+	}
 	return revenues, nil
 }
 
@@ -48,17 +68,47 @@ func convertJSONDataToRevenue(d jsonData) Revenues {
 	for i := 0; i < len(ltvs); i++ {
 		ltvs[i] = ltvs[i].Mul(decimal.NewFromInt(d.Users))
 	}
-	return Revenues{Revenues: ltvs, Country: d.Country, CampaignID: d.CampaignID, UsersCount: d.Users}
+	var streams map[string][]decimal.Decimal
+	for stream, values := range d.Streams {
+		if streams == nil {
+			streams = make(map[string][]decimal.Decimal, len(d.Streams))
+		}
+		streamLtvs := make([]decimal.Decimal, 0, len(values))
+		for _, v := range values {
+			streamLtvs = append(streamLtvs, v.Mul(decimal.NewFromInt(d.Users)))
+		}
+		streams[strings.ToLower(stream)] = streamLtvs
+	}
+	return Revenues{Revenues: ltvs, Country: d.Country, CampaignID: d.CampaignID, UsersCount: d.Users, Currency: d.Currency, Streams: streams}
 }
 
-func parseJSONFile(path string) ([]jsonData, error) {
+// validateJSONStreams checks that every stream of the record has the LTVs of all the days, records start from 1
+func validateJSONStreams(i int, d jsonData) error {
+	streams := make([]string, 0, len(d.Streams))
+	for stream := range d.Streams {
+		streams = append(streams, stream)
+	}
+	slices.Sort(streams)
+	var errs []error
+	for _, stream := range streams {
+		if len(d.Streams[stream]) != ltvDays {
+			errs = append(errs, fmt.Errorf(ErrInvalidStream.Error(), stream, i+1, ltvDays))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func parseJSONFile(ctx context.Context, path string) ([]jsonData, error) {
 	jsonFile, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf(ErrCantOpenFile.Error(), path)
 	}
 	defer jsonFile.Close()
+	return readJSON(ctx, jsonFile)
+}
 
-	byteValue, err := io.ReadAll(jsonFile)
+func readJSON(ctx context.Context, r io.Reader) ([]jsonData, error) {
+	byteValue, err := io.ReadAll(contextReader{ctx: ctx, r: r})
 	if err != nil {
 		return nil, fmt.Errorf(ErrCantReadData.Error(), err)
 	}
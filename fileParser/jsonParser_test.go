@@ -1,7 +1,9 @@
 package fileParser
 
 import (
+	"context"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/shopspring/decimal"
@@ -62,7 +64,7 @@ func TestJSONParser_Parse(t *testing.T) {
 		},
 	}
 
-	revenues, err := parser.Parse()
+	revenues, err := parser.Parse(context.Background())
 	if err != nil {
 		t.Fatalf("Error parsing JSON: %v", err)
 	}
@@ -86,7 +88,7 @@ func TestJSONParser_Parse_Invalid_JSON(t *testing.T) {
 			Path: tempJSONFilePath,
 		}
 
-		revenues, err := parser.Parse()
+		revenues, err := parser.Parse(context.Background())
 		assert.Nil(t, err)
 		assert.Empty(t, revenues)
 	})
@@ -106,7 +108,7 @@ func TestJSONParser_Parse_Invalid_JSON(t *testing.T) {
 			Path: tempJSONFilePath,
 		}
 
-		revenues, err := parser.Parse()
+		revenues, err := parser.Parse(context.Background())
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid character")
 		assert.Empty(t, revenues)
@@ -120,9 +122,66 @@ func TestJSONParser_Parse_Invalid_JSON(t *testing.T) {
 			Path: invalidPath,
 		}
 
-		revenues, err := parser.Parse()
+		revenues, err := parser.Parse(context.Background())
 		assert.Error(t, err)
 		assert.Equal(t, "parsing error: can't open specified file(invalid/path/to/file.json)", err.Error())
 		assert.Empty(t, revenues)
 	})
 }
+
+func TestJSONParser_Parse_Reader(t *testing.T) {
+	jsonData := `[{"CampaignId": "campaign", "Country": "TR", "Ltv1": 1, "Ltv2": 2, "Ltv3": 3, "Ltv4": 4, "Ltv5": 5, "Ltv6": 6, "Ltv7": 7, "Users": 2}]`
+	parser := JSONParser{Reader: strings.NewReader(jsonData)}
+
+	revenues, err := parser.Parse(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, revenues, 1)
+	assert.Equal(t, int64(2), revenues[0].UsersCount)
+	assert.True(t, decimal.NewFromInt(14).Equal(revenues[0].Revenues[6]))
+}
+
+func TestJSONParser_Parse_Currency(t *testing.T) {
+	jsonData := `[{"CampaignId": "campaign", "Country": "DE", "Ltv1": 1, "Ltv2": 2, "Ltv3": 3, "Ltv4": 4, "Ltv5": 5, "Ltv6": 6, "Ltv7": 7, "Users": 2, "Currency": "EUR"}]`
+	parser := JSONParser{Reader: strings.NewReader(jsonData)}
+
+	revenues, err := parser.Parse(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", revenues[0].Currency)
+}
+
+func TestJSONParser_Parse_Streams(t *testing.T) {
+	jsonData := `[{"CampaignId": "campaign", "Country": "DE", "Ltv1": 1, "Ltv2": 2, "Ltv3": 3, "Ltv4": 4, "Ltv5": 5, "Ltv6": 6, "Ltv7": 7, "Users": 2,
+		"Streams": {"IAP": [1, 1, 1, 1, 1, 1, 1], "ads": [0, 1, 2, 3, 4, 5, 6]}}]`
+	parser := JSONParser{Reader: strings.NewReader(jsonData)}
+
+	revenues, err := parser.Parse(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ads", "iap"}, sortedStreams(revenues[0].Streams))
+	// stream LTVs are multiplied by the users count like the total ones
+	assert.True(t, decimal.NewFromInt(2).Equal(revenues[0].Streams["iap"][6]))
+	assert.True(t, decimal.NewFromInt(12).Equal(revenues[0].Streams["ads"][6]))
+}
+
+func TestJSONParser_Parse_InvalidStreams(t *testing.T) {
+	jsonData := `[{"Country": "DE", "Users": 1}, {"Country": "DE", "Users": 1, "Streams": {"iap": [1, 2], "ads": []}}]`
+	parser := JSONParser{Reader: strings.NewReader(jsonData)}
+
+	revenues, err := parser.Parse(context.Background())
+
+	assert.Nil(t, revenues)
+	assert.EqualError(t, err, "parsing error: stream ads of record 2 should have 7 LTVs\nstream iap of record 2 should have 7 LTVs")
+}
+
+func TestJSONParser_Parse_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	parser := JSONParser{Reader: strings.NewReader("[]")}
+
+	revenues, err := parser.Parse(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, revenues)
+}
@@ -0,0 +1,19 @@
+package fileParser
+
+import (
+	"context"
+	"fmt"
+)
+
+// MemoryParser returns revenues that are already in memory, it allows to run the pipeline without a file
+type MemoryParser struct {
+	Revenues []Revenues
+}
+
+func (p MemoryParser) Parse(ctx context.Context) ([]Revenues, error) {
+	err := ctx.Err()
+	if err != nil {
+		return nil, fmt.Errorf(ErrParsingError.Error(), err)
+	}
+	return p.Revenues, nil
+}
@@ -1,7 +1,9 @@
 package fileParser
 
 import (
+	"context"
 	"errors"
+	"io"
 
 	"github.com/shopspring/decimal"
 )
@@ -17,8 +19,33 @@ type Revenues struct {
 	Country    string
 	CampaignID string
 	UsersCount int64
+	// Currency is the optional ISO code of the currency of the revenues, empty if it is not specified in the source
+	Currency string
+	// Streams are the optional revenues by the revenue type, e.g. iap, ads or subscriptions. Revenues are the total
+	// of the record and the streams are its breakdown, they do not have to cover the whole total
+	Streams map[string][]decimal.Decimal
 }
 
 type FileParser interface {
-	Parse() ([]Revenues, error)
+	Parse(ctx context.Context) ([]Revenues, error)
+}
+
+// StreamParser sends the records to the channel as they are read, so they could be aggregated without keeping
+// all of them in memory. The channel is closed once parsing finishes, also if it fails
+type StreamParser interface {
+	ParseStream(ctx context.Context, records chan<- Revenues) error
+}
+
+// contextReader stops reading once the context is done, so parsing of a huge file could be aborted
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr contextReader) Read(p []byte) (int, error) {
+	err := cr.ctx.Err()
+	if err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/config"
+	flagsParser "github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/pklimuk/ltv-predictor/predictor"
+)
+
+// fit fits the parameters of the model for every key and saves them to the model file
+func fit(args []string) int {
+	flags, parseErr := flagsParser.ParseCommand(flagsParser.FitCommand, args, os.LookupEnv)
+	if listComponents(flags) {
+		return exitOK
+	}
+	appConfig, err := config.CreateAppConfig(flags)
+	if flags.ModelFile == "" {
+		err = errors.Join(err, fmt.Errorf(config.ErrConfigError.Error(), config.ErrModelFileNotSpecified))
+	}
+	err = errors.Join(parseErr, err)
+	if err != nil {
+		log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+		return exitUsage
+	}
+	fitter, ok := appConfig.Predictor.(predictor.Fitter)
+	if !ok {
+		log.Printf("Model %s could not be saved, it has no parameters to fit", flags.Model)
+		return exitUsage
+	}
+
+	ctx, cancel := newContext(flags.Timeout)
+	defer cancel()
+
+	_, ltvs, err := newProcessor(appConfig).Aggregate(ctx)
+	if err != nil {
+		log.Printf("An error occurred during processing:\n\t%s", indent(err))
+		return exitFailure
+	}
+	params, err := fitter.Fit(ctx, ltvs)
+	var partialErr *predictor.PartialError
+	if err != nil && !errors.As(err, &partialErr) {
+		log.Printf("An error occurred during fitting:\n\t%s", indent(err))
+		return exitFailure
+	}
+	model := predictor.Model{
+		Version:     predictor.ModelVersion,
+		Model:       flags.Model,
+		Aggregation: flags.AggregateBy,
+		Source:      flags.Source,
+		FittedAt:    time.Now().UTC(),
+		Params:      params,
+	}
+	err = outputPrinter.WriteFileAtomic(flags.ModelFile, func(w io.Writer) error {
+		return predictor.WriteModel(w, model)
+	})
+	if err != nil {
+		log.Printf("An error occurred during saving:\n\t%s", indent(err))
+		return exitFailure
+	}
+	fmt.Printf("%s fitted for %d keys by %s is saved to %s\n", flags.Model, len(params), flags.AggregateBy, flags.ModelFile)
+	if partialErr != nil {
+		printFailures(os.Stdout, partialErr.Failures)
+		return exitPartialSuccess
+	}
+	return exitOK
+}
+
+// printFailures prints the reasons of the failed keys in the sorted order
+func printFailures(w io.Writer, failures predictor.Failures) {
+	keys := make([]string, 0, len(failures))
+	for k := range failures {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s: failed: %v\n", k, failures[k])
+	}
+}
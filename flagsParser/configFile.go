@@ -0,0 +1,159 @@
+package flagsParser
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileKeys maps keys of the configuration file to the flags they set, nested keys are joined with dots
+var configFileKeys = map[string]string{
+	"source":                 "source",
+	"strict":                 "strict",
+	"aggregate":              "aggregate",
+	"shards":                 "shards",
+	"timeout":                "timeout",
+	"model.name":             "model",
+	"model.predictionLength": "predictionLength",
+	"model.workers":          "workers",
+	"model.keepGoing":        "keepGoing",
+	"model.file":             "modelFile",
+	"model.streams":          "streamModels",
+	"backtest.holdout":       "holdout",
+	"filters.countries":      "countries",
+	"filters.campaigns":      "campaigns",
+	"filters.minUsers":       "minUsers",
+	"spend.file":             "spend",
+	"currency.target":        "currency",
+	"currency.rates":         "rates",
+	"currency.date":          "ratesDate",
+	"bids.targetROAS":        "targetROAS",
+	"bids.targetMargin":      "targetMargin",
+	"bids.confidence":        "confidence",
+	"history.dir":            "history",
+	"history.threshold":      "threshold",
+	"alerts.threshold":       "alertThreshold",
+	"alerts.zScore":          "alertZScore",
+	"alerts.window":          "alertWindow",
+	"notify.url":             "webhook",
+	"notify.format":          "webhookFormat",
+	"notify.timeout":         "webhookTimeout",
+	"notify.retries":         "webhookRetries",
+	"notify.top":             "webhookTop",
+	"output.format":          "output",
+	"output.path":            "out",
+	"output.columns":         "columns",
+	"output.sort":            "sort",
+	"output.round":           "round",
+	"output.rich":            "rich",
+	"output.template":        "template",
+	"serve.addr":             "addr",
+	"serve.grpcAddr":         "grpcAddr",
+	"serve.maxBodySize":      "maxBodySize",
+	"serve.shutdownTimeout":  "shutdownTimeout",
+}
+
+// componentsSection is the section of the configuration file with the parameters of the components by the component name
+const componentsSection = "components."
+
+// applyConfigFile sets the flags to the values of the configuration file and f.Components to its components section,
+// all invalid keys and values are reported
+func applyConfigFile(f *Flags, flagSet *flag.FlagSet, path string) []error {
+	values, err := readConfigFile(path)
+	if err != nil {
+		return []error{fmt.Errorf(ErrConfigFileError.Error(), err)}
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var errs []error
+	for _, k := range keys {
+		if component, ok := strings.CutPrefix(k, componentsSection); ok {
+			// names of the parsers contain dots, e.g. ".csv", so the parameter is the last part of the key
+			i := strings.LastIndex(component, ".")
+			if i <= 0 || i == len(component)-1 {
+				errs = append(errs, fmt.Errorf(ErrConfigFileError.Error(), fmt.Errorf(ErrUnknownConfigKey.Error(), k)))
+				continue
+			}
+			name, parameter := component[:i], component[i+1:]
+			if f.Components == nil {
+				f.Components = make(map[string]map[string]string)
+			}
+			if f.Components[name] == nil {
+				f.Components[name] = make(map[string]string)
+			}
+			f.Components[name][parameter] = values[k]
+			continue
+		}
+		name, ok := configFileKeys[k]
+		if !ok {
+			errs = append(errs, fmt.Errorf(ErrConfigFileError.Error(), fmt.Errorf(ErrUnknownConfigKey.Error(), k)))
+			continue
+		}
+		err = flagSet.Set(name, values[k])
+		if err != nil {
+			errs = append(errs, fmt.Errorf(ErrConfigFileError.Error(), fmt.Errorf(ErrInvalidConfigKey.Error(), k, err)))
+		}
+	}
+	return errs
+}
+
+// readConfigFile reads the YAML or TOML file and returns its values by the flattened key
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var content map[string]any
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &content)
+	case ".toml":
+		err = toml.Unmarshal(data, &content)
+	default:
+		return nil, fmt.Errorf(ErrUnsupportedFormat.Error(), ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	flatten("", content, values)
+	return values, nil
+}
+
+// flatten joins nested keys with dots, lists are converted into comma separated values as the flags expect them
+func flatten(prefix string, content map[string]any, values map[string]string) {
+	for k, v := range content {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			flatten(k, v, values)
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[k] = strings.Join(items, ",")
+		case time.Time:
+			// unquoted dates are decoded as times, the flags expect them in the format they are written in
+			values[k] = v.Format(time.RFC3339)
+			if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+				values[k] = v.Format(time.DateOnly)
+			}
+		default:
+			values[k] = fmt.Sprint(v)
+		}
+	}
+}
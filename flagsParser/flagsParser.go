@@ -1,29 +1,321 @@
 package flagsParser
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
 )
 
-const DefaultPredictionLength = 60
+const (
+	DefaultPredictionLength = 60
+	DefaultModel            = "linearExtrapolation"
+	DefaultAggregateBy      = "country"
+	DefaultAddr             = ":8080"
+	DefaultMaxBodySize      = 32 << 20
+	DefaultShutdownTimeout  = 10 * time.Second
+	DefaultWorkers          = 1
+	DefaultShards           = 1
+	DefaultHoldout          = 3
+	DefaultCurrency         = "USD"
+	DefaultThreshold        = 0.1
+	DefaultAlertWindow      = 7
+	DefaultWebhookFormat    = "json"
+	DefaultWebhookTimeout   = 10 * time.Second
+	DefaultWebhookRetries   = 3
+	DefaultWebhookTop       = 5
+)
+
+const envPrefix = "LTV_"
+
+// Components prints the registered models, aggregations and formats in the help message. It is set by main,
+// as the components are registered in packages which depend on flagsParser
+var Components func(w io.Writer) error
+
+var (
+	ErrInvalidEnv        = errors.New("invalid environment variable(%s): %w")
+	ErrConfigFileError   = errors.New("config file error: %w")
+	ErrUnknownConfigKey  = errors.New("unknown key(%s)")
+	ErrInvalidConfigKey  = errors.New("invalid value of %s: %w")
+	ErrUnsupportedFormat = errors.New("configuration file format is not supported(%s)")
+)
 
 type Flags struct {
+	Config           string
 	Model            string
 	Source           string
 	AggregateBy      string
 	PredictionLength int64
+	Output           string
+	Columns          string
+	SortBy           string
+	Round            int64
+	Rich             bool
+	OutputPath       string
+	Template         string
+	Timeout          time.Duration
+	Workers          int
+	Shards           int
+	KeepGoing        bool
+	Countries        string
+	Campaigns        string
+	MinUsers         int64
+	Holdout          int64
+	Strict           bool
+	ModelFile        string
+	History          string
+	Threshold        float64
+	AlertThreshold   float64
+	AlertZScore      float64
+	AlertWindow      int
+	Webhook          string
+	WebhookFormat    string
+	WebhookTimeout   time.Duration
+	WebhookRetries   int
+	WebhookTop       int
+	Spend            string
+	StreamModels     string
+	Currency         string
+	Rates            string
+	RatesDate        string
+	TargetROAS       float64
+	TargetMargin     float64
+	Confidence       float64
+	Addr             string
+	GRPCAddr         string
+	MaxBodySize      int64
+	ShutdownTimeout  time.Duration
+	// Components are the parameters of the components section of the configuration file by the component name
+	Components map[string]map[string]string
+	// Args are the arguments left after the flags
+	Args []string
+	// set are the names of the flags specified on the command line or in the environment
+	set map[string]bool
+}
+
+// Value returns the value of the flag in the format it is written on the command line
+func (f *Flags) Value(name string) (string, bool) {
+	values := &Flags{}
+	fl := newFlagSet(values).Lookup(name)
+	if fl == nil {
+		return "", false
+	}
+	// the flag set has reset the values to the defaults, so the values of f are copied after it is created
+	*values = *f
+	return fl.Value.String(), true
+}
+
+// IsSet reports whether the flag was specified on the command line or in the environment
+func (f *Flags) IsSet(name string) bool {
+	return f.set[name]
+}
+
+// Command is a subcommand of the predictor together with the flags it accepts
+type Command struct {
+	Name        string
+	Description string
+	Flags       []string
+}
+
+var (
+	PredictCommand = Command{
+		Name:        "predict",
+		Description: "predict LTVs and print them in the selected format, used when no command is specified",
+		Flags: []string{"config", "source", "strict", "model", "streamModels", "aggregate", "predictionLength", "workers", "shards", "keepGoing",
+			"countries", "campaigns", "minUsers", "currency", "rates", "ratesDate", "modelFile", "spend", "history", "alertThreshold", "alertZScore",
+			"alertWindow", "webhook", "webhookFormat", "webhookTimeout", "webhookRetries", "webhookTop", "output", "columns", "sort", "round", "rich", "out", "template", "timeout"},
+	}
+	BacktestCommand = Command{
+		Name:        "backtest",
+		Description: "predict the last observed LTV from the earlier days and compare the prediction with the observed value",
+		Flags: []string{"config", "source", "strict", "model", "aggregate", "holdout", "workers", "shards", "keepGoing",
+			"countries", "campaigns", "minUsers", "currency", "rates", "ratesDate", "timeout"},
+	}
+	ValidateCommand = Command{
+		Name:        "validate",
+		Description: "check that the source file could be parsed and aggregated without predicting, invalid records are reported",
+		Flags:       []string{"config", "source", "aggregate", "shards", "countries", "campaigns", "minUsers", "currency", "rates", "ratesDate", "timeout"},
+	}
+	FitCommand = Command{
+		Name:        "fit",
+		Description: "fit the parameters of the model for every key and save them to the model file",
+		Flags: []string{"config", "source", "strict", "model", "aggregate", "workers", "shards", "keepGoing",
+			"countries", "campaigns", "minUsers", "currency", "rates", "ratesDate", "modelFile", "timeout"},
+	}
+	ScoreCommand = Command{
+		Name:        "score",
+		Description: "compare the curves of the saved model with the observed LTVs without refitting",
+		Flags: []string{"config", "source", "strict", "aggregate", "shards", "countries", "campaigns", "minUsers", "currency", "rates", "ratesDate",
+			"modelFile", "timeout"},
+	}
+	DiffCommand = Command{
+		Name:        "diff",
+		Description: "compare predictions of two runs saved to the history key by key, the last two runs if the ids are not specified",
+		Flags:       []string{"config", "history", "threshold"},
+	}
+	BidsCommand = Command{
+		Name:        "bids",
+		Description: "recommend the maximum CPI of every key which meets the target ROAS or margin, optionally from the lower bound of the prediction",
+		Flags: []string{"config", "source", "strict", "model", "aggregate", "predictionLength", "workers", "shards", "keepGoing",
+			"countries", "campaigns", "minUsers", "currency", "rates", "ratesDate", "modelFile", "spend", "targetROAS", "targetMargin", "confidence",
+			"output", "round", "out", "timeout"},
+	}
+	InspectCommand = Command{
+		Name:        "inspect",
+		Description: "print the users count, the revenue, the LTVs and their day-over-day growth of every key without predicting",
+		Flags:       []string{"config", "source", "strict", "aggregate", "shards", "countries", "campaigns", "minUsers", "currency", "rates", "ratesDate", "round", "timeout"},
+	}
+	ServeCommand = Command{
+		Name:        "serve",
+		Description: "serve predictions over HTTP and gRPC",
+		Flags:       []string{"config", "addr", "grpcAddr", "maxBodySize", "shutdownTimeout", "rates", "ratesDate"},
+	}
+)
+
+// Defaults returns the flags set to their default values
+func Defaults() *Flags {
+	f := &Flags{}
+	newFlagSet(f)
+	return f
+}
+
+// ParseFlags parses the flags of the predict command, see Parse
+func ParseFlags() (*Flags, error) {
+	return Parse(os.Args[1:], os.LookupEnv)
+}
+
+// Parse parses the flags of the predict command, see ParseCommand
+func Parse(args []string, lookupEnv func(string) (string, bool)) (*Flags, error) {
+	return ParseCommand(PredictCommand, args, lookupEnv)
+}
+
+// ParseCommand resolves every setting from the first source it is specified in: the command line flags,
+// the environment variables(LTV_ followed by the flag name in upper snake case, e.g. LTV_PREDICTION_LENGTH),
+// the configuration file and the default values. Parameters of the components are read from the components section
+// of the file, e.g. components.linearRegression.workers, and stored in Flags.Components. Invalid values of the file and the environment variables
+// are reported all at once, the flags are returned together with the errors, so the commands could report them
+// along with the validation errors. The configuration file could contain settings of other commands, they are ignored
+func ParseCommand(command Command, args []string, lookupEnv func(string) (string, bool)) (*Flags, error) {
+	f := &Flags{}
+	all := newFlagSet(f)
+	flagSet := flag.NewFlagSet(command.Name, flag.ExitOnError)
+	for _, name := range command.Flags {
+		fl := all.Lookup(name)
+		flagSet.Var(fl.Value, fl.Name, fl.Usage)
+	}
+	flagSet.Usage = func() {
+		w := flagSet.Output()
+		fmt.Fprintf(w, "Usage of %s %s:\n  %s\n\n", filepath.Base(os.Args[0]), command.Name, command.Description)
+		flagSet.PrintDefaults()
+		if Components != nil && slices.Contains(command.Flags, "model") {
+			fmt.Fprintln(w)
+			_ = Components(w)
+		}
+	}
+	// ExitOnError is used, so the error is always nil
+	_ = flagSet.Parse(args)
+
+	path := f.Config
+	if path == "" {
+		path, _ = lookupEnv(EnvName("config"))
+	}
+	var errs []error
+	if path != "" {
+		errs = append(errs, applyConfigFile(f, all, path)...)
+	}
+	flagSet.VisitAll(func(fl *flag.Flag) {
+		value, ok := lookupEnv(EnvName(fl.Name))
+		if !ok {
+			return
+		}
+		err := flagSet.Set(fl.Name, value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf(ErrInvalidEnv.Error(), EnvName(fl.Name), err))
+		}
+	})
+	// flags are parsed once again to override the values taken from the file and the environment
+	_ = flagSet.Parse(args)
+	f.Args = flagSet.Args()
+	// the file sets the values through the flag set of all the flags, so only the flags and the environment are visited
+	f.set = make(map[string]bool)
+	flagSet.Visit(func(fl *flag.Flag) {
+		f.set[fl.Name] = true
+	})
+	return f, errors.Join(errs...)
+}
+
+// newFlagSet defines all the flags, commands take the ones they accept from it
+func newFlagSet(f *Flags) *flag.FlagSet {
+	flagSet := flag.NewFlagSet("all", flag.ContinueOnError)
+	flagSet.StringVar(&f.Config, "config", "", "Path to the YAML or TOML configuration file")
+	flagSet.StringVar(&f.Model, "model", DefaultModel, "Model to use for prediction, \"list\" prints the available models")
+	flagSet.StringVar(&f.Source, "source", "", "Path to the source file")
+	flagSet.StringVar(&f.AggregateBy, "aggregate", DefaultAggregateBy, "Field to aggregate by, \"list\" prints the available aggregations")
+	flagSet.Int64Var(&f.PredictionLength, "predictionLength", DefaultPredictionLength, "Length of prediction in days")
+	flagSet.StringVar(&f.Output, "output", "console", "Output format, \"list\" prints the available formats")
+	flagSet.StringVar(&f.Columns, "columns", "key,predicted", "Comma separated list of columns for table outputs(key,users,ltv7,predicted,uplift,model,error,alert,spend,installs,cpi,roas,profit,payback,streams)")
+	flagSet.StringVar(&f.SortBy, "sort", "key", "Sort order of console and table outputs(key|predicted|users)")
+	flagSet.Int64Var(&f.Round, "round", 2, "Number of decimal places in console, table, html and inspect outputs")
+	flagSet.BoolVar(&f.Rich, "rich", false, "Print an aligned table with sparklines of LTV curves in console output")
+	flagSet.StringVar(&f.OutputPath, "out", "", "Path to the output file, output is printed to stdout if not specified")
+	flagSet.StringVar(&f.Template, "template", "", "Path to the text/template file used by template output")
+	flagSet.DurationVar(&f.Timeout, "timeout", 0, "Maximum duration of the run, e.g. 30s or 5m, not limited if not specified")
+	flagSet.IntVar(&f.Workers, "workers", DefaultWorkers, "Number of keys predicted concurrently")
+	flagSet.IntVar(&f.Shards, "shards", DefaultShards, "Number of parts the input is split into and aggregated concurrently")
+	flagSet.BoolVar(&f.KeepGoing, "keepGoing", false, "Keep predicting other keys if some of them fail and report the failed keys")
+	flagSet.StringVar(&f.Countries, "countries", "", "Comma separated list of countries to predict, all countries if not specified")
+	flagSet.StringVar(&f.Campaigns, "campaigns", "", "Comma separated list of campaigns to predict, all campaigns if not specified")
+	flagSet.Int64Var(&f.MinUsers, "minUsers", 0, "Minimum number of users of a key, keys with fewer users are not predicted")
+	flagSet.BoolVar(&f.Strict, "strict", false, "Fail on invalid records of CSV files instead of skipping them")
+	flagSet.StringVar(&f.ModelFile, "modelFile", "", "Path to the model file, fit saves the fitted parameters to it, predict and score use them instead of fitting")
+	flagSet.StringVar(&f.StreamModels, "streamModels", "", "Comma separated models of the revenue streams, e.g. ads=linearRegression,iap=linearExtrapolation, other streams are predicted by the model")
+	flagSet.StringVar(&f.Currency, "currency", DefaultCurrency, "Currency the revenues are converted into with the rates file, records without a currency are expected to be in it")
+	flagSet.StringVar(&f.Rates, "rates", "", "Path to the CSV file with the exchange rates to USD by date, revenues are converted only if it is set")
+	flagSet.StringVar(&f.RatesDate, "ratesDate", "", "Date(YYYY-MM-DD) of the exchange rates the revenues are converted with, the latest rates are used if not specified")
+	flagSet.StringVar(&f.Spend, "spend", "", "Path to the CSV file with the spend and installs of every key, adds CPI, ROAS, profit and payback day to the output")
+	flagSet.Float64Var(&f.TargetROAS, "targetROAS", 0, "Target ROAS of the bids, e.g. 1.3 for 130%, either it or targetMargin should be set")
+	flagSet.Float64Var(&f.TargetMargin, "targetMargin", 0, "Target margin of the revenue of the bids, e.g. 0.2 for 20%, either it or targetROAS should be set")
+	flagSet.Float64Var(&f.Confidence, "confidence", 0, "Confidence of the lower bound of the prediction the bids are based on, e.g. 0.9, the prediction is used if 0")
+	flagSet.StringVar(&f.History, "history", "", "Path to the directory with the history of predictions, every run of predict is saved to it if specified")
+	flagSet.Float64Var(&f.Threshold, "threshold", DefaultThreshold, "Relative change of a prediction highlighted by diff, e.g. 0.1 for 10%")
+	flagSet.Float64Var(&f.AlertThreshold, "alertThreshold", 0, "Relative deviation from the trailing average of the history which raises an alert, e.g. 0.3 for 30%, not checked if 0")
+	flagSet.Float64Var(&f.AlertZScore, "alertZScore", 0, "Deviation from the trailing average of the history in standard deviations which raises an alert, not checked if 0")
+	flagSet.IntVar(&f.AlertWindow, "alertWindow", DefaultAlertWindow, "Number of the latest runs of the history the trailing average is computed from")
+	flagSet.StringVar(&f.Webhook, "webhook", "", "URL the summary of every run of predict is posted to, nothing is posted if not specified")
+	flagSet.StringVar(&f.WebhookFormat, "webhookFormat", DefaultWebhookFormat, "Format of the summary posted to the webhook(json|slack)")
+	flagSet.DurationVar(&f.WebhookTimeout, "webhookTimeout", DefaultWebhookTimeout, "Maximum duration of every attempt to post to the webhook, not limited if 0")
+	flagSet.IntVar(&f.WebhookRetries, "webhookRetries", DefaultWebhookRetries, "Number of retries of the webhook after network errors, 5xx and 429 statuses")
+	flagSet.IntVar(&f.WebhookTop, "webhookTop", DefaultWebhookTop, "Number of the highest predictions included in the summary posted to the webhook")
+	flagSet.Int64Var(&f.Holdout, "holdout", DefaultHoldout, "Number of the last observed days which are predicted by backtest")
+	flagSet.StringVar(&f.Addr, "addr", DefaultAddr, "Address to listen on")
+	flagSet.StringVar(&f.GRPCAddr, "grpcAddr", "", "Address of the gRPC service, the service is not started if not specified")
+	flagSet.Int64Var(&f.MaxBodySize, "maxBodySize", DefaultMaxBodySize, "Maximum size of the request body in bytes")
+	flagSet.DurationVar(&f.ShutdownTimeout, "shutdownTimeout", DefaultShutdownTimeout, "Time given to in-flight requests to finish on shutdown")
+	return flagSet
 }
 
-func ParseFlags() *Flags {
-	model := flag.String("model", "linearExtrapolation", "Model to use for prediction(linearExtrapolation|linearRegression)")
-	source := flag.String("source", "", "Path to the source file")
-	aggregateBy := flag.String("aggregate", "country", "Field to aggregate by(country|campaign)")
-	predictionLength := flag.Int64("predictionLength", DefaultPredictionLength, "Length of prediction in days")
-	flag.Parse()
-	flags := Flags{
-		Model:            *model,
-		Source:           *source,
-		AggregateBy:      *aggregateBy,
-		PredictionLength: *predictionLength,
+// EnvName returns the name of the environment variable of the flag, words start at the capital letters
+// and a run of capitals is one word, e.g. targetROAS becomes LTV_TARGET_ROAS
+func EnvName(flagName string) string {
+	var sb strings.Builder
+	sb.WriteString(envPrefix)
+	runes := []rune(flagName)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := !unicode.IsUpper(runes[i-1])
+			// the last capital of a run starts the next word, e.g. the T of ROASTarget
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToUpper(r))
 	}
-	return &flags
+	return sb.String()
 }
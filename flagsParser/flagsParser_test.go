@@ -0,0 +1,212 @@
+package flagsParser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createConfigFile(t *testing.T, name, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func lookupEnv(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+func TestParse_Defaults(t *testing.T) {
+	flags, err := Parse(nil, lookupEnv(nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultModel, flags.Model)
+	assert.Equal(t, int64(DefaultPredictionLength), flags.PredictionLength)
+	assert.Equal(t, int64(2), flags.Round)
+	assert.Equal(t, DefaultWorkers, flags.Workers)
+}
+
+func TestParse_Precedence(t *testing.T) {
+	path := createConfigFile(t, "config.yaml", `
+source: data.csv
+aggregate: campaign
+timeout: 5m
+model:
+  name: linearRegression
+  predictionLength: 90
+  workers: 8
+  file: model.json
+filters:
+  countries: [US, DE]
+  minUsers: 100
+output:
+  format: csv
+  columns: [key, users, predicted]
+  round: 0
+`)
+	env := map[string]string{
+		"LTV_PREDICTION_LENGTH": "30",
+		"LTV_WORKERS":           "4",
+	}
+
+	flags, err := Parse([]string{"-config", path, "-workers", "16"}, lookupEnv(env))
+
+	assert.NoError(t, err)
+	// file values
+	assert.Equal(t, "data.csv", flags.Source)
+	assert.Equal(t, "campaign", flags.AggregateBy)
+	assert.Equal(t, 5*time.Minute, flags.Timeout)
+	assert.Equal(t, "linearRegression", flags.Model)
+	assert.Equal(t, "model.json", flags.ModelFile)
+	assert.Equal(t, "US,DE", flags.Countries)
+	assert.Equal(t, int64(100), flags.MinUsers)
+	assert.Equal(t, "csv", flags.Output)
+	assert.Equal(t, "key,users,predicted", flags.Columns)
+	assert.Equal(t, int64(0), flags.Round)
+	// environment overrides the file
+	assert.Equal(t, int64(30), flags.PredictionLength)
+	// flags override the environment
+	assert.Equal(t, 16, flags.Workers)
+	// defaults
+	assert.Equal(t, "key", flags.SortBy)
+}
+
+func TestParse_TOMLFromEnv(t *testing.T) {
+	path := createConfigFile(t, "config.toml", `
+source = "data.json"
+
+[model]
+name = "linearRegression"
+keepGoing = true
+
+[output]
+format = "markdown"
+`)
+
+	flags, err := Parse(nil, lookupEnv(map[string]string{"LTV_CONFIG": path}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "data.json", flags.Source)
+	assert.Equal(t, "linearRegression", flags.Model)
+	assert.True(t, flags.KeepGoing)
+	assert.Equal(t, "markdown", flags.Output)
+}
+
+func TestParse_Dates(t *testing.T) {
+	for name, data := range map[string]string{
+		"config.yaml": "currency:\n  rates: rates.csv\n  date: 2023-10-01\n",
+		"config.toml": "[currency]\nrates = \"rates.csv\"\ndate = 2023-10-01\n",
+	} {
+		path := createConfigFile(t, name, data)
+
+		flags, err := Parse([]string{"-config", path}, lookupEnv(nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "rates.csv", flags.Rates)
+		assert.Equal(t, "2023-10-01", flags.RatesDate, name)
+		assert.Equal(t, DefaultCurrency, flags.Currency)
+	}
+}
+
+func TestParse_ReportsAllErrors(t *testing.T) {
+	path := createConfigFile(t, "config.yml", `
+model:
+  workers: many
+output:
+  colour: red
+`)
+
+	_, err := Parse([]string{"-config", path}, lookupEnv(map[string]string{"LTV_ROUND": "two"}))
+
+	assert.EqualError(t, err, "config file error: invalid value of model.workers: parse error\n"+
+		"config file error: unknown key(output.colour)\n"+
+		"invalid environment variable(LTV_ROUND): parse error")
+}
+
+func TestParse_UnsupportedConfigFile(t *testing.T) {
+	path := createConfigFile(t, "config.ini", "source=data.csv")
+
+	_, err := Parse([]string{"-config", path}, lookupEnv(nil))
+
+	assert.EqualError(t, err, "config file error: configuration file format is not supported(.ini)")
+}
+
+func TestParseCommand_Serve(t *testing.T) {
+	path := createConfigFile(t, "config.yaml", `
+serve:
+  addr: :9090
+  grpcAddr: :9091
+`)
+	env := map[string]string{"LTV_SHUTDOWN_TIMEOUT": "30s"}
+
+	flags, err := ParseCommand(ServeCommand, []string{"-config", path, "-grpcAddr", ":9092"}, lookupEnv(env))
+
+	assert.NoError(t, err)
+	assert.Equal(t, ":9090", flags.Addr)
+	assert.Equal(t, ":9092", flags.GRPCAddr)
+	assert.Equal(t, int64(DefaultMaxBodySize), flags.MaxBodySize)
+	assert.Equal(t, 30*time.Second, flags.ShutdownTimeout)
+}
+
+func TestParseCommand_Backtest(t *testing.T) {
+	path := createConfigFile(t, "config.yaml", `
+source: data.csv
+strict: true
+backtest:
+  holdout: 5
+`)
+
+	flags, err := ParseCommand(BacktestCommand, []string{"-config", path}, lookupEnv(nil))
+
+	assert.NoError(t, err)
+	assert.True(t, flags.Strict)
+	assert.Equal(t, int64(5), flags.Holdout)
+}
+
+func TestParse_Components(t *testing.T) {
+	path := createConfigFile(t, "config.yaml", `
+model:
+  workers: 2
+components:
+  linearRegression:
+    workers: 8
+    keepGoing: true
+  .csv:
+    strict: true
+  broken: 1
+`)
+
+	flags, err := Parse([]string{"-config", path, "-keepGoing=false"}, lookupEnv(nil))
+
+	assert.EqualError(t, err, "config file error: unknown key(components.broken)")
+	assert.Equal(t, map[string]map[string]string{
+		"linearRegression": {"workers": "8", "keepGoing": "true"},
+		".csv":             {"strict": "true"},
+	}, flags.Components)
+	assert.False(t, flags.IsSet("workers"))
+	assert.True(t, flags.IsSet("keepGoing"))
+	assert.True(t, flags.IsSet("config"))
+	value, ok := flags.Value("workers")
+	assert.True(t, ok)
+	assert.Equal(t, "2", value)
+	_, ok = flags.Value("unknown")
+	assert.False(t, ok)
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "LTV_MODEL", EnvName("model"))
+	assert.Equal(t, "LTV_PREDICTION_LENGTH", EnvName("predictionLength"))
+	assert.Equal(t, "LTV_MIN_USERS", EnvName("minUsers"))
+	// a run of capitals is one word
+	assert.Equal(t, "LTV_TARGET_ROAS", EnvName("targetROAS"))
+	assert.Equal(t, "LTV_GRPC_ADDR", EnvName("grpcAddr"))
+	assert.Equal(t, "LTV_ROAS_TARGET", EnvName("ROASTarget"))
+	assert.Equal(t, "LTV_SPEND_CPI", EnvName("spendCPI"))
+	assert.Equal(t, "LTV_ALERT_Z_SCORE", EnvName("alertZScore"))
+}
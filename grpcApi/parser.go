@@ -0,0 +1,71 @@
+package grpcApi
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+)
+
+// recvBuffer is the number of records which could wait for the aggregator when they are all collected by Parse
+const recvBuffer = 1024
+
+// recvParser converts the records of the stream as they are received, so they are aggregated without keeping
+// the whole stream in memory. The first message is received before the config is created from its options
+type recvParser struct {
+	stream Predictor_PredictServer
+	first  *PredictRequest
+	// invalid is the error of the records sent by the client, it is reported as an invalid argument
+	invalid error
+}
+
+func (p *recvParser) Parse(ctx context.Context) ([]fileParser.Revenues, error) {
+	records := make(chan fileParser.Revenues, recvBuffer)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- p.ParseStream(ctx, records)
+	}()
+	revenues := make([]fileParser.Revenues, 0)
+	for rec := range records {
+		revenues = append(revenues, rec)
+	}
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return revenues, nil
+}
+
+// ParseStream sends the records of every message to the channel until the client closes the stream
+func (p *recvParser) ParseStream(ctx context.Context, records chan<- fileParser.Revenues) error {
+	defer close(records)
+	count := 0
+	for req := p.first; ; {
+		for _, r := range req.GetRecords() {
+			revenues, err := r.ToRevenues()
+			if err != nil {
+				p.invalid = err
+				return fmt.Errorf(fileParser.ErrParsingError.Error(), err)
+			}
+			select {
+			case records <- *revenues:
+			case <-ctx.Done():
+				return fmt.Errorf(fileParser.ErrParsingError.Error(), ctx.Err())
+			}
+			count++
+		}
+		var err error
+		req, err = p.stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if count == 0 {
+		p.invalid = ErrNoRecords
+		return fmt.Errorf(fileParser.ErrParsingError.Error(), ErrNoRecords)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package grpcApi
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStream returns the messages and then io.EOF
+type testStream struct {
+	Predictor_PredictServer
+	messages []*PredictRequest
+}
+
+func (s *testStream) Recv() (*PredictRequest, error) {
+	if len(s.messages) == 0 {
+		return nil, io.EOF
+	}
+	req := s.messages[0]
+	s.messages = s.messages[1:]
+	return req, nil
+}
+
+func TestRecvParser_Parse(t *testing.T) {
+	stream := &testStream{messages: []*PredictRequest{{}, {Records: []*Record{{Country: "US", Revenues: []string{"2"}, UsersCount: 1}}}}}
+	parser := &recvParser{stream: stream, first: &PredictRequest{Records: []*Record{{Country: "TR", Revenues: []string{"1"}, UsersCount: 1}}}}
+
+	revenues, err := parser.Parse(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []fileParser.Revenues{
+		{Country: "TR", Revenues: []decimal.Decimal{decimal.NewFromInt(1)}, UsersCount: 1},
+		{Country: "US", Revenues: []decimal.Decimal{decimal.NewFromInt(2)}, UsersCount: 1},
+	}, revenues)
+	assert.NoError(t, parser.invalid)
+}
+
+func TestRecvParser_ParseStream_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		first       *PredictRequest
+		messages    []*PredictRequest
+		expectedErr string
+	}{
+		{"No records", &PredictRequest{}, []*PredictRequest{{}}, "stream does not contain any records"},
+		{"Invalid record", &PredictRequest{Records: []*Record{{Revenues: []string{"1"}}}}, []*PredictRequest{{Records: []*Record{{Revenues: []string{"abc"}}}}},
+			"invalid record: can't convert abc to decimal"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parser := &recvParser{stream: &testStream{messages: test.messages}, first: test.first}
+			records := make(chan fileParser.Revenues, 10)
+
+			err := parser.ParseStream(context.Background(), records)
+
+			assert.EqualError(t, err, "parsing error: "+test.expectedErr)
+			assert.EqualError(t, parser.invalid, test.expectedErr)
+		})
+	}
+}
+
+func TestNewService(t *testing.T) {
+	service, err := NewService("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, service.rates)
+
+	_, err = NewService("missing.csv", "01.10.2023")
+	assert.EqualError(t, err, "config error: rates date should be in the YYYY-MM-DD format\nopen missing.csv: no such file or directory")
+}
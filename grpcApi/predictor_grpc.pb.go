@@ -0,0 +1,145 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: predictor.proto
+
+package grpcApi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Predictor_Predict_FullMethodName = "/ltvpredictor.v1.Predictor/Predict"
+)
+
+// PredictorClient is the client API for Predictor service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PredictorClient interface {
+	// Predict receives records in batches, the first message should contain options.
+	Predict(ctx context.Context, opts ...grpc.CallOption) (Predictor_PredictClient, error)
+}
+
+type predictorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPredictorClient(cc grpc.ClientConnInterface) PredictorClient {
+	return &predictorClient{cc}
+}
+
+func (c *predictorClient) Predict(ctx context.Context, opts ...grpc.CallOption) (Predictor_PredictClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Predictor_ServiceDesc.Streams[0], Predictor_Predict_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &predictorPredictClient{stream}
+	return x, nil
+}
+
+type Predictor_PredictClient interface {
+	Send(*PredictRequest) error
+	CloseAndRecv() (*PredictResponse, error)
+	grpc.ClientStream
+}
+
+type predictorPredictClient struct {
+	grpc.ClientStream
+}
+
+func (x *predictorPredictClient) Send(m *PredictRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *predictorPredictClient) CloseAndRecv() (*PredictResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(PredictResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PredictorServer is the server API for Predictor service.
+// All implementations must embed UnimplementedPredictorServer
+// for forward compatibility
+type PredictorServer interface {
+	// Predict receives records in batches, the first message should contain options.
+	Predict(Predictor_PredictServer) error
+	mustEmbedUnimplementedPredictorServer()
+}
+
+// UnimplementedPredictorServer must be embedded to have forward compatible implementations.
+type UnimplementedPredictorServer struct {
+}
+
+func (UnimplementedPredictorServer) Predict(Predictor_PredictServer) error {
+	return status.Errorf(codes.Unimplemented, "method Predict not implemented")
+}
+func (UnimplementedPredictorServer) mustEmbedUnimplementedPredictorServer() {}
+
+// UnsafePredictorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PredictorServer will
+// result in compilation errors.
+type UnsafePredictorServer interface {
+	mustEmbedUnimplementedPredictorServer()
+}
+
+func RegisterPredictorServer(s grpc.ServiceRegistrar, srv PredictorServer) {
+	s.RegisterService(&Predictor_ServiceDesc, srv)
+}
+
+func _Predictor_Predict_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PredictorServer).Predict(&predictorPredictServer{stream})
+}
+
+type Predictor_PredictServer interface {
+	SendAndClose(*PredictResponse) error
+	Recv() (*PredictRequest, error)
+	grpc.ServerStream
+}
+
+type predictorPredictServer struct {
+	grpc.ServerStream
+}
+
+func (x *predictorPredictServer) SendAndClose(m *PredictResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *predictorPredictServer) Recv() (*PredictRequest, error) {
+	m := new(PredictRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Predictor_ServiceDesc is the grpc.ServiceDesc for Predictor service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Predictor_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ltvpredictor.v1.Predictor",
+	HandlerType: (*PredictorServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Predict",
+			Handler:       _Predictor_Predict_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "predictor.proto",
+}
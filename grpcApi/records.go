@@ -0,0 +1,73 @@
+package grpcApi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidRecord = errors.New("invalid record: %w")
+	ErrInvalidStream = errors.New("stream %s should have %d revenues")
+)
+
+// NewRecord converts revenues of the parser into the record sent to the server
+func NewRecord(r fileParser.Revenues) *Record {
+	record := &Record{
+		CampaignId: r.CampaignID,
+		Country:    r.Country,
+		Revenues:   decimalStrings(r.Revenues),
+		UsersCount: r.UsersCount,
+		Currency:   r.Currency,
+	}
+	if len(r.Streams) > 0 {
+		record.Streams = make(map[string]*StreamRevenues, len(r.Streams))
+		for name, revenues := range r.Streams {
+			record.Streams[name] = &StreamRevenues{Revenues: decimalStrings(revenues)}
+		}
+	}
+	return record
+}
+
+// ToRevenues converts the record into revenues used by aggregators, names of the streams are lower cased like by the parsers
+func (r *Record) ToRevenues() (*fileParser.Revenues, error) {
+	revenues, err := toDecimals(r.GetRevenues())
+	if err != nil {
+		return nil, fmt.Errorf(ErrInvalidRecord.Error(), err)
+	}
+	result := &fileParser.Revenues{
+		Revenues:   revenues,
+		Country:    r.GetCountry(),
+		CampaignID: r.GetCampaignId(),
+		UsersCount: r.GetUsersCount(),
+		Currency:   r.GetCurrency(),
+	}
+	if len(r.GetStreams()) > 0 {
+		result.Streams = make(map[string][]decimal.Decimal, len(r.GetStreams()))
+		for name, stream := range r.GetStreams() {
+			if len(stream.GetRevenues()) != len(revenues) {
+				return nil, fmt.Errorf(ErrInvalidRecord.Error(), fmt.Errorf(ErrInvalidStream.Error(), name, len(revenues)))
+			}
+			result.Streams[strings.ToLower(name)], err = toDecimals(stream.GetRevenues())
+			if err != nil {
+				return nil, fmt.Errorf(ErrInvalidRecord.Error(), err)
+			}
+		}
+	}
+	return result, nil
+}
+
+func toDecimals(values []string) ([]decimal.Decimal, error) {
+	result := make([]decimal.Decimal, 0, len(values))
+	for _, v := range values {
+		d, err := decimal.NewFromString(v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package grpcApi
+
+import (
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord_Revenues(t *testing.T) {
+	revenues := fileParser.Revenues{
+		Revenues:   []decimal.Decimal{decimal.NewFromFloat(1.25), decimal.NewFromInt(3)},
+		Country:    "TR",
+		CampaignID: "c1",
+		UsersCount: 2,
+	}
+
+	record := NewRecord(revenues)
+	assert.Equal(t, []string{"1.25", "3"}, record.Revenues)
+	result, err := record.ToRevenues()
+
+	assert.NoError(t, err)
+	assert.Equal(t, revenues, *result)
+
+	_, err = (&Record{Revenues: []string{"abc"}}).ToRevenues()
+	assert.EqualError(t, err, "invalid record: can't convert abc to decimal")
+}
+
+func TestRecord_Streams(t *testing.T) {
+	revenues := fileParser.Revenues{
+		Revenues:   []decimal.Decimal{decimal.NewFromInt(2), decimal.NewFromInt(4)},
+		Country:    "TR",
+		UsersCount: 1,
+		Currency:   "EUR",
+		Streams:    map[string][]decimal.Decimal{"ads": {decimal.NewFromInt(1), decimal.NewFromInt(3)}},
+	}
+
+	record := NewRecord(revenues)
+	assert.Equal(t, []string{"1", "3"}, record.Streams["ads"].Revenues)
+	result, err := record.ToRevenues()
+
+	assert.NoError(t, err)
+	assert.Equal(t, revenues, *result)
+
+	record.Streams = map[string]*StreamRevenues{"IAP": {Revenues: []string{"1", "2"}}}
+	result, err = record.ToRevenues()
+	assert.NoError(t, err)
+	assert.Contains(t, result.Streams, "iap")
+
+	record.Streams["IAP"].Revenues = []string{"1"}
+	_, err = record.ToRevenues()
+	assert.EqualError(t, err, "invalid record: stream IAP should have 2 revenues")
+}
@@ -0,0 +1,233 @@
+package grpcApi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/config"
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/pklimuk/ltv-predictor/processor"
+	"github.com/pklimuk/ltv-predictor/roas"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative predictor.proto
+
+var (
+	ErrNoRecords    = errors.New("stream does not contain any records")
+	ErrServerError  = errors.New("gRPC server error: %w")
+	ErrNoRates      = errors.New("currency could not be converted, the server is started without the rates")
+	ErrInvalidSpend = errors.New("invalid spend of key %s: %w")
+)
+
+// Server runs the streamed records through the same pipeline as the predict command, so the filters, the keep going
+// mode, the currency conversion, the spend and the revenue streams work the same way. The zero value serves
+// the predictions without the currency conversion
+type Server struct {
+	UnimplementedPredictorServer
+	// rates convert the revenues, currency of the options is rejected if they are nil
+	rates *config.Rates
+}
+
+// NewService creates the service which converts the revenues with the rates file, the file is read once instead of
+// on every call. ratesDate selects the rates, the latest rates are used if it is empty
+func NewService(rates, ratesDate string) (*Server, error) {
+	if rates == "" {
+		return &Server{}, nil
+	}
+	flags := flagsParser.Defaults()
+	flags.Rates = rates
+	flags.RatesDate = ratesDate
+	r, err := config.LoadRates(flags)
+	if err != nil {
+		return nil, fmt.Errorf(config.ErrConfigError.Error(), err)
+	}
+	return &Server{rates: r}, nil
+}
+
+// NewServer creates a gRPC server with the Predictor service registered
+func NewServer(service *Server, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	RegisterPredictorServer(s, service)
+	return s
+}
+
+func (s *Server) Predict(stream Predictor_PredictServer) error {
+	// the config is created from the options of the first message, the records are aggregated as they arrive
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, ErrNoRecords.Error())
+	}
+	if err != nil {
+		return err
+	}
+	parser := &recvParser{stream: stream, first: req}
+	appConfig, err := s.createAppConfig(req.GetOptions(), parser)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	spend, err := optionsToSpend(req.GetOptions())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	p := processor.Processor{
+		Parser:           appConfig.Parser,
+		Aggregator:       appConfig.Aggregator,
+		Filter:           appConfig.Filter,
+		Predictor:        appConfig.Predictor,
+		Model:            appConfig.Model,
+		AggregateBy:      appConfig.AggregateBy,
+		PredictionLength: appConfig.PredictionLength,
+		Spend:            spend,
+		Streams:          appConfig.Streams,
+	}
+	report, err := p.Run(stream.Context())
+	// the parser is done once Run returns, so the error of the records is set if they were invalid
+	if parser.invalid != nil {
+		return status.Error(codes.InvalidArgument, parser.invalid.Error())
+	}
+	// failures of the keep going mode are returned in the response
+	var partialErr *predictor.PartialError
+	if err != nil && !errors.As(err, &partialErr) {
+		return statusError(stream.Context(), codes.FailedPrecondition, err)
+	}
+	return stream.SendAndClose(newPredictResponse(*report))
+}
+
+// statusError converts the error into a status with the code, errors caused by cancellation of
+// the stream are reported as Canceled or DeadlineExceeded instead
+func statusError(ctx context.Context, code codes.Code, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return status.Error(code, err.Error())
+}
+
+// createAppConfig creates the config of the options, the settings which are not in the options have the default values
+func (s *Server) createAppConfig(o *PredictOptions, parser fileParser.FileParser) (*config.AppConfig, error) {
+	flags := flagsParser.Defaults()
+	if o.GetModel() != "" {
+		flags.Model = o.GetModel()
+	}
+	if o.GetAggregate() != "" {
+		flags.AggregateBy = o.GetAggregate()
+	}
+	if o.GetPredictionLength() != 0 {
+		flags.PredictionLength = o.GetPredictionLength()
+	}
+	flags.Countries = strings.Join(o.GetCountries(), ",")
+	flags.Campaigns = strings.Join(o.GetCampaigns(), ",")
+	flags.MinUsers = o.GetMinUsers()
+	flags.KeepGoing = o.GetKeepGoing()
+	flags.StreamModels = strings.Join(o.GetStreamModels(), ",")
+	if o.GetCurrency() != "" {
+		if s.rates == nil {
+			return nil, fmt.Errorf(config.ErrConfigError.Error(), ErrNoRates)
+		}
+		flags.Currency = o.GetCurrency()
+	}
+	return config.CreateParserAppConfig(flags, parser, s.rates)
+}
+
+// optionsToSpend converts the spend of the options, the spend of the same key is summed like in the spend file
+func optionsToSpend(o *PredictOptions) (roas.SpendByKey, error) {
+	if len(o.GetSpend()) == 0 {
+		return nil, nil
+	}
+	result := make(roas.SpendByKey)
+	var errs []error
+	for _, s := range o.GetSpend() {
+		amount, err := decimal.NewFromString(s.GetSpend())
+		if err != nil {
+			errs = append(errs, fmt.Errorf(ErrInvalidSpend.Error(), s.GetKey(), err))
+			continue
+		}
+		total := result[s.GetKey()]
+		total.Spend = total.Spend.Add(amount)
+		total.Installs += s.GetInstalls()
+		result[s.GetKey()] = total
+	}
+	errs = append(errs, result.Validate())
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf(roas.ErrSpendError.Error(), err)
+	}
+	return result, nil
+}
+
+// newPredictResponse converts the report into the response, predictions and failures are sorted by key
+func newPredictResponse(r outputPrinter.Report) *PredictResponse {
+	resp := &PredictResponse{
+		Model:            r.Model,
+		Aggregation:      r.Aggregation,
+		PredictionLength: r.PredictionLength,
+		RecordsCount:     int64(r.Quality.Records),
+		GeneratedAt:      r.GeneratedAt.UTC().Format(time.RFC3339),
+	}
+	for _, key := range r.Keys() {
+		prediction := &Prediction{
+			Key:       key,
+			Users:     r.Revenues[key].UsersCount,
+			Ltvs:      decimalStrings(r.LTVs[key]),
+			Predicted: r.Predictions[key].String(),
+			Streams:   streamPredictions(r, key),
+		}
+		if m, ok := r.ROAS[key]; ok {
+			prediction.Roas = &ROAS{
+				Spend:      m.Spend.String(),
+				Installs:   m.Installs,
+				Cpi:        m.CPI.String(),
+				Roas:       m.ROAS.String(),
+				Profit:     m.Profit.String(),
+				PaybackDay: m.PaybackDay,
+			}
+		}
+		resp.Predictions = append(resp.Predictions, prediction)
+	}
+	for _, key := range r.FailedKeys() {
+		resp.Failures = append(resp.Failures, &Failure{
+			Key:   key,
+			Users: r.Revenues[key].UsersCount,
+			Error: r.Failures[key].Error(),
+		})
+	}
+	return resp
+}
+
+// streamPredictions returns the predictions of the streams of the key sorted by name, streams without the revenues
+// of the key are skipped
+func streamPredictions(r outputPrinter.Report, key string) []*StreamPrediction {
+	var result []*StreamPrediction
+	for _, name := range r.StreamNames() {
+		stream := r.Streams[name]
+		prediction := &StreamPrediction{Stream: name, Model: stream.Model}
+		if err, ok := stream.Failures[key]; ok {
+			prediction.Error = err.Error()
+		} else if predicted, ok := stream.Predictions[key]; ok {
+			prediction.Predicted = predicted.String()
+		} else {
+			continue
+		}
+		prediction.Ltvs = decimalStrings(stream.LTVs[key])
+		result = append(result, prediction)
+	}
+	return result
+}
+
+func decimalStrings(values []decimal.Decimal) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		result = append(result, v.String())
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+package grpcClient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/pklimuk/ltv-predictor/grpcApi"
+	"google.golang.org/grpc"
+)
+
+const DefaultBatchSize = 1000
+
+var (
+	ErrClientError      = errors.New("client error: %w")
+	ErrBatchSizeInvalid = errors.New("batch size should be greater than 0")
+)
+
+// Client calls the Predictor service
+type Client struct {
+	client grpcApi.PredictorClient
+	// ownConn is set when the connection was created by Dial and should be closed by the client
+	ownConn *grpc.ClientConn
+}
+
+// PredictStream sends records to the server in batches, the server predicts them once the stream is closed
+type PredictStream struct {
+	stream      grpcApi.Predictor_PredictClient
+	options     *grpcApi.PredictOptions
+	optionsSent bool
+}
+
+// Dial connects to the server
+func Dial(target string, opts ...grpc.DialOption) (*Client, error) {
+	conn, err := grpc.Dial(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf(ErrClientError.Error(), err)
+	}
+	return &Client{client: grpcApi.NewPredictorClient(conn), ownConn: conn}, nil
+}
+
+// Close closes the connection if it was created by Dial
+func (c *Client) Close() error {
+	if c.ownConn == nil {
+		return nil
+	}
+	return c.ownConn.Close()
+}
+
+// NewClient creates a client on top of an existing connection
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{client: grpcApi.NewPredictorClient(conn)}
+}
+
+// NewPredictStream opens a stream, options are sent with the first batch of records
+func (c *Client) NewPredictStream(ctx context.Context, options *grpcApi.PredictOptions) (*PredictStream, error) {
+	stream, err := c.client.Predict(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(ErrClientError.Error(), err)
+	}
+	return &PredictStream{stream: stream, options: options}, nil
+}
+
+// Send sends one batch of records
+func (s *PredictStream) Send(records []fileParser.Revenues) error {
+	req := &grpcApi.PredictRequest{Records: make([]*grpcApi.Record, 0, len(records))}
+	if !s.optionsSent {
+		req.Options = s.options
+		s.optionsSent = true
+	}
+	for _, r := range records {
+		req.Records = append(req.Records, grpcApi.NewRecord(r))
+	}
+	err := s.stream.Send(req)
+	if err == io.EOF {
+		// the server has already finished the stream, the reason is returned by RecvMsg
+		err = s.stream.RecvMsg(&grpcApi.PredictResponse{})
+	}
+	if err != nil {
+		return fmt.Errorf(ErrClientError.Error(), err)
+	}
+	return nil
+}
+
+// CloseAndReceive finishes sending and waits for predictions
+func (s *PredictStream) CloseAndReceive() (*grpcApi.PredictResponse, error) {
+	if !s.optionsSent {
+		err := s.Send(nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	resp, err := s.stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf(ErrClientError.Error(), err)
+	}
+	return resp, nil
+}
+
+// Predict sends all the records in batches of batchSize and returns predictions
+func (c *Client) Predict(ctx context.Context, options *grpcApi.PredictOptions, records []fileParser.Revenues, batchSize int) (*grpcApi.PredictResponse, error) {
+	if batchSize <= 0 {
+		return nil, ErrBatchSizeInvalid
+	}
+	stream, err := c.NewPredictStream(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	for start := 0; start < len(records); start += batchSize {
+		err = stream.Send(records[start:min(start+batchSize, len(records))])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stream.CloseAndReceive()
+}
@@ -0,0 +1,173 @@
+package grpcClient
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/pklimuk/ltv-predictor/grpcApi"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func startTestServer(t *testing.T, service *grpcApi.Server) *Client {
+	listener := bufconn.Listen(1 << 20)
+	server := grpcApi.NewServer(service)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	client, err := Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func createRevenues(country string, users int64, values ...int64) fileParser.Revenues {
+	revenues := make([]decimal.Decimal, 0, len(values))
+	for _, v := range values {
+		revenues = append(revenues, decimal.NewFromInt(v))
+	}
+	return fileParser.Revenues{Revenues: revenues, Country: country, CampaignID: "campaign-" + country, UsersCount: users}
+}
+
+func TestClient_Predict(t *testing.T) {
+	client := startTestServer(t, &grpcApi.Server{})
+	records := []fileParser.Revenues{
+		createRevenues("TR", 1, 1, 2, 3),
+		createRevenues("US", 1, 2, 4, 6),
+		createRevenues("TR", 1, 1, 2, 3),
+	}
+
+	resp, err := client.Predict(context.Background(), &grpcApi.PredictOptions{PredictionLength: 10}, records, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "linearExtrapolation", resp.Model)
+	assert.Equal(t, "country", resp.Aggregation)
+	assert.Equal(t, int64(10), resp.PredictionLength)
+	assert.Equal(t, int64(3), resp.RecordsCount)
+	assert.NotEmpty(t, resp.GeneratedAt)
+	assert.Equal(t, []*grpcApi.Prediction{
+		{Key: "TR", Users: 2, Ltvs: []string{"1", "2", "3"}, Predicted: "10"},
+		{Key: "US", Users: 1, Ltvs: []string{"2", "4", "6"}, Predicted: "20"},
+	}, resp.Predictions)
+}
+
+func TestClient_Predict_Pipeline(t *testing.T) {
+	client := startTestServer(t, &grpcApi.Server{})
+	tr := createRevenues("TR", 1, 1, 2, 3)
+	tr.Streams = map[string][]decimal.Decimal{"IAP": {decimal.NewFromFloat(0.5), decimal.NewFromInt(1), decimal.NewFromFloat(1.5)}}
+	records := []fileParser.Revenues{tr, createRevenues("US", 1, 2, 4, 6), createRevenues("DE", 1, 1), createRevenues("PL", 1, 1, 2, 3)}
+	options := &grpcApi.PredictOptions{
+		PredictionLength: 10,
+		Countries:        []string{"TR", "US", "DE"},
+		KeepGoing:        true,
+		StreamModels:     []string{"iap=linearRegression"},
+		Spend:            []*grpcApi.Spend{{Key: "TR", Spend: "5", Installs: 1}},
+	}
+
+	resp, err := client.Predict(context.Background(), options, records, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), resp.RecordsCount)
+	assert.Len(t, resp.Predictions, 2)
+	assert.Equal(t, "TR", resp.Predictions[0].Key)
+	assert.Equal(t, "10", resp.Predictions[0].Predicted)
+	assert.Equal(t, "2", resp.Predictions[0].Roas.Roas)
+	assert.Equal(t, int64(5), resp.Predictions[0].Roas.PaybackDay)
+	assert.Len(t, resp.Predictions[0].Streams, 1)
+	assert.Equal(t, "iap", resp.Predictions[0].Streams[0].Stream)
+	assert.Equal(t, "linearRegression", resp.Predictions[0].Streams[0].Model)
+	assert.Equal(t, "5", resp.Predictions[0].Streams[0].Predicted)
+	assert.Equal(t, "US", resp.Predictions[1].Key)
+	assert.Nil(t, resp.Predictions[1].Roas)
+	assert.Empty(t, resp.Predictions[1].Streams)
+	assert.Len(t, resp.Failures, 1)
+	assert.Equal(t, "DE", resp.Failures[0].Key)
+	assert.Equal(t, int64(1), resp.Failures[0].Users)
+	assert.Equal(t, "not enough data to make prediction", resp.Failures[0].Error)
+}
+
+func TestClient_Predict_Currency(t *testing.T) {
+	rates := filepath.Join(t.TempDir(), "rates.csv")
+	assert.NoError(t, os.WriteFile(rates, []byte("date,currency,rate\n2023-10-01,EUR,1.05\n"), 0o644))
+	service, err := grpcApi.NewService(rates, "")
+	assert.NoError(t, err)
+	client := startTestServer(t, service)
+	tr := createRevenues("TR", 1, 1, 2, 3)
+	tr.Currency = "EUR"
+
+	resp, err := client.Predict(context.Background(), &grpcApi.PredictOptions{PredictionLength: 10, Currency: "USD"}, []fileParser.Revenues{tr}, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.05", "2.1", "3.15"}, resp.Predictions[0].Ltvs)
+	assert.Equal(t, "10.5", resp.Predictions[0].Predicted)
+}
+
+func TestClient_PredictStream(t *testing.T) {
+	client := startTestServer(t, &grpcApi.Server{})
+	stream, err := client.NewPredictStream(context.Background(), &grpcApi.PredictOptions{Aggregate: "campaign", Model: "linearRegression"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, stream.Send([]fileParser.Revenues{createRevenues("TR", 2, 2, 4)}))
+	assert.NoError(t, stream.Send(nil))
+	assert.NoError(t, stream.Send([]fileParser.Revenues{createRevenues("TR", 2, 2, 4)}))
+	resp, err := stream.CloseAndReceive()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "linearRegression", resp.Model)
+	assert.Equal(t, int64(2), resp.RecordsCount)
+	assert.Len(t, resp.Predictions, 1)
+	assert.Equal(t, "campaign-TR", resp.Predictions[0].Key)
+	assert.Equal(t, int64(4), resp.Predictions[0].Users)
+	assert.Equal(t, "60", resp.Predictions[0].Predicted)
+}
+
+func TestClient_Predict_Errors(t *testing.T) {
+	client := startTestServer(t, &grpcApi.Server{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		options      *grpcApi.PredictOptions
+		records      []fileParser.Revenues
+		expectedCode codes.Code
+		expectedMsg  string
+	}{
+		{"Unknown model", &grpcApi.PredictOptions{Model: "unknown"}, []fileParser.Revenues{createRevenues("TR", 1, 1, 2)}, codes.InvalidArgument, "config error: unknown model"},
+		{"No records", &grpcApi.PredictOptions{}, nil, codes.InvalidArgument, "stream does not contain any records"},
+		{"Different length", &grpcApi.PredictOptions{}, []fileParser.Revenues{createRevenues("TR", 1, 1, 2), createRevenues("TR", 1, 1)}, codes.FailedPrecondition, "aggregator error: ltv and revenues slices have different length"},
+		{"Currency without rates", &grpcApi.PredictOptions{Currency: "EUR"}, []fileParser.Revenues{createRevenues("TR", 1, 1, 2)}, codes.InvalidArgument,
+			"config error: currency could not be converted, the server is started without the rates"},
+		{"Invalid spend", &grpcApi.PredictOptions{Spend: []*grpcApi.Spend{{Key: "TR", Spend: "abc", Installs: 1}, {Key: "US", Spend: "1"}}},
+			[]fileParser.Revenues{createRevenues("TR", 1, 1, 2)}, codes.InvalidArgument,
+			"spend error: invalid spend of key TR: can't convert abc to decimal\ninstalls of key US should be greater than 0"},
+		{"Not enough data", &grpcApi.PredictOptions{}, []fileParser.Revenues{createRevenues("TR", 1, 1)}, codes.FailedPrecondition, "predictor error: TR: not enough data to make prediction"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := client.Predict(ctx, test.options, test.records, 1)
+
+			assert.Equal(t, test.expectedCode, status.Code(err))
+			assert.EqualError(t, err, "client error: rpc error: code = "+test.expectedCode.String()+" desc = "+test.expectedMsg)
+		})
+	}
+
+	_, err := client.Predict(ctx, &grpcApi.PredictOptions{}, nil, 0)
+	assert.ErrorIs(t, err, ErrBatchSizeInvalid)
+}
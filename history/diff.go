@@ -0,0 +1,138 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// Change compares the predictions of a key in two runs
+type Change struct {
+	Key string
+	// Old and New are nil if the key is missing in the run or its prediction failed
+	Old *decimal.Decimal
+	New *decimal.Decimal
+	// Relative is the change divided by the old prediction, nil if the old prediction is missing or zero
+	Relative *decimal.Decimal
+	// Large is set if the relative change exceeds the threshold or the key could be predicted in only one of the runs
+	Large bool
+}
+
+// Diff compares the predictions of every key of both runs, changes are sorted by key
+func Diff(old, new *Run, threshold decimal.Decimal) []Change {
+	keys := make([]string, 0, len(old.Keys)+len(new.Keys))
+	for k := range old.Keys {
+		keys = append(keys, k)
+	}
+	for k := range new.Keys {
+		if _, ok := old.Keys[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	changes := make([]Change, 0, len(keys))
+	for _, k := range keys {
+		change := Change{Key: k, Old: old.Keys[k].Predicted, New: new.Keys[k].Predicted}
+		switch {
+		case change.Old == nil || change.New == nil:
+			change.Large = change.Old != change.New
+		case change.Old.IsZero():
+			change.Large = !change.New.IsZero()
+		default:
+			relative := change.New.Sub(*change.Old).Div(*change.Old)
+			change.Relative = &relative
+			change.Large = relative.Abs().GreaterThan(threshold)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// PrintDiff prints the metadata of both runs and their differences followed by an aligned table of the changes,
+// large changes are marked with "!"
+func PrintDiff(w io.Writer, old, new *Run, changes []Change, threshold decimal.Decimal) error {
+	for _, run := range []struct {
+		title string
+		run   *Run
+	}{{"Old", old}, {"New", new}} {
+		_, err := fmt.Fprintf(w, "%s: %s %s(%s) %s by %s, %d days\n", run.title, run.run.ID, run.run.Source, shortHash(run.run.SourceHash),
+			run.run.Model, run.run.Aggregation, run.run.PredictionLength)
+		if err != nil {
+			return err
+		}
+	}
+	var notes []string
+	if old.SourceHash != new.SourceHash {
+		notes = append(notes, "input data changed")
+	}
+	if old.Model != new.Model {
+		notes = append(notes, fmt.Sprintf("model changed from %s to %s", old.Model, new.Model))
+	}
+	if old.Aggregation != new.Aggregation {
+		notes = append(notes, fmt.Sprintf("aggregation changed from %s to %s", old.Aggregation, new.Aggregation))
+	}
+	if old.PredictionLength != new.PredictionLength {
+		notes = append(notes, fmt.Sprintf("prediction length changed from %d to %d days", old.PredictionLength, new.PredictionLength))
+	}
+	if len(notes) > 0 {
+		_, err := fmt.Fprintf(w, "Note: %s\n", strings.Join(notes, ", "))
+		if err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, err = fmt.Fprintln(tw, "KEY\tOLD\tNEW\tCHANGE\tCHANGE %\t\t")
+	if err != nil {
+		return err
+	}
+	large := 0
+	for _, c := range changes {
+		change, relative, mark := "-", "-", ""
+		if c.Old != nil && c.New != nil {
+			change = c.New.Sub(*c.Old).StringFixed(2)
+		}
+		if c.Relative != nil {
+			relative = c.Relative.Mul(hundred).StringFixed(1)
+		}
+		if c.Large {
+			mark = "!"
+			large++
+		}
+		_, err = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t\n", c.Key, formatPrediction(c.Old), formatPrediction(c.New), change, relative, mark)
+		if err != nil {
+			return err
+		}
+	}
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "\n%d of %d keys changed by more than %s%%\n", large, len(changes), threshold.Mul(hundred).String())
+	return err
+}
+
+func formatPrediction(p *decimal.Decimal) string {
+	if p == nil {
+		return "-"
+	}
+	return p.StringFixed(2)
+}
+
+func shortHash(hash string) string {
+	if hash == "" {
+		return "unknown input"
+	}
+	return hash[:min(len(hash), 12)]
+}
@@ -0,0 +1,71 @@
+package history
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func prediction(v float64) *decimal.Decimal {
+	d := decimal.NewFromFloat(v)
+	return &d
+}
+
+func TestDiff(t *testing.T) {
+	old := &Run{ID: "1", Source: "data.csv", SourceHash: "0123456789abcdef", Model: "linearRegression", Aggregation: "country",
+		PredictionLength: 60, Keys: map[string]KeyResult{
+			"US": {Predicted: prediction(10)},
+			"DE": {Predicted: prediction(10)},
+			"FR": {Predicted: prediction(5)},
+			"IT": {Error: "not enough data"},
+		}}
+	new := &Run{ID: "2", Source: "data.csv", SourceHash: "fedcba9876543210", Model: "linearExtrapolation", Aggregation: "country",
+		PredictionLength: 60, Keys: map[string]KeyResult{
+			"US": {Predicted: prediction(10.5)},
+			"DE": {Predicted: prediction(5)},
+			"IT": {Predicted: prediction(3)},
+			"JP": {Predicted: prediction(1)},
+		}}
+	threshold := decimal.NewFromFloat(0.1)
+
+	changes := Diff(old, new, threshold)
+
+	assert.Len(t, changes, 5)
+	assert.Equal(t, []string{"DE", "FR", "IT", "JP", "US"}, []string{changes[0].Key, changes[1].Key, changes[2].Key, changes[3].Key, changes[4].Key})
+	assert.True(t, decimal.NewFromFloat(-0.5).Equal(*changes[0].Relative))
+	assert.True(t, changes[0].Large)
+	// removed, previously failed and added keys are large changes without relative change
+	for _, c := range changes[1:4] {
+		assert.Nil(t, c.Relative)
+		assert.True(t, c.Large)
+	}
+	assert.True(t, decimal.NewFromFloat(0.05).Equal(*changes[4].Relative))
+	assert.False(t, changes[4].Large)
+
+	var buf bytes.Buffer
+	assert.NoError(t, PrintDiff(&buf, old, new, changes, threshold))
+	assert.Equal(t, "Old: 1 data.csv(0123456789ab) linearRegression by country, 60 days\n"+
+		"New: 2 data.csv(fedcba987654) linearExtrapolation by country, 60 days\n"+
+		"Note: input data changed, model changed from linearRegression to linearExtrapolation\n"+
+		"\n"+
+		"  KEY    OLD    NEW  CHANGE  CHANGE %   \n"+
+		"   DE  10.00   5.00   -5.00     -50.0  !\n"+
+		"   FR   5.00      -       -         -  !\n"+
+		"   IT      -   3.00       -         -  !\n"+
+		"   JP      -   1.00       -         -  !\n"+
+		"   US  10.00  10.50    0.50       5.0   \n"+
+		"\n4 of 5 keys changed by more than 10%\n", buf.String())
+}
+
+func TestDiff_ZeroPrediction(t *testing.T) {
+	old := &Run{Keys: map[string]KeyResult{"US": {Predicted: prediction(0)}, "DE": {Predicted: prediction(0)}}}
+	new := &Run{Keys: map[string]KeyResult{"US": {Predicted: prediction(0)}, "DE": {Predicted: prediction(1)}}}
+
+	changes := Diff(old, new, decimal.NewFromFloat(0.1))
+
+	assert.True(t, changes[0].Large)
+	assert.False(t, changes[1].Large)
+	assert.Nil(t, changes[1].Relative)
+}
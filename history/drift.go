@@ -0,0 +1,115 @@
+package history
+
+import (
+	"errors"
+	"io/fs"
+	"math"
+	"slices"
+
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/shopspring/decimal"
+)
+
+// minZScoreRuns is the minimum number of previous values the standard deviation is computed from
+const minZScoreRuns = 3
+
+// DriftDetector compares the predictions and the last observed LTVs of the report with their trailing
+// averages over the previous runs of the same model, aggregation and prediction length
+type DriftDetector struct {
+	Store Store
+	// Window is the maximum number of the latest comparable runs the averages are computed from
+	Window int
+	// Threshold is the maximum relative deviation from the average, it is not checked if zero
+	Threshold decimal.Decimal
+	// ZScore is the maximum deviation from the average in standard deviations, it is not checked if zero
+	ZScore decimal.Decimal
+}
+
+// Detect returns the alerts of the report sorted by key and metric, nothing is detected if the history is empty
+func (d DriftDetector) Detect(report outputPrinter.Report) ([]outputPrinter.Alert, error) {
+	current := NewRun(report, "")
+	runs, err := d.previousRuns(current)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(current.Keys))
+	for k := range current.Keys {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var alerts []outputPrinter.Alert
+	for _, k := range keys {
+		result := current.Keys[k]
+		var predicted, ltv7 []decimal.Decimal
+		for _, run := range runs {
+			previous, ok := run.Keys[k]
+			if !ok {
+				continue
+			}
+			if previous.Predicted != nil {
+				predicted = append(predicted, *previous.Predicted)
+			}
+			ltv7 = append(ltv7, previous.LTV7)
+		}
+		if result.Predicted != nil {
+			if alert, ok := d.check(k, outputPrinter.AlertMetricPredicted, *result.Predicted, predicted); ok {
+				alerts = append(alerts, alert)
+			}
+		}
+		if alert, ok := d.check(k, outputPrinter.AlertMetricLTV7, result.LTV7, ltv7); ok {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+// previousRuns loads the latest Window runs comparable with the current one from the newest to the oldest
+func (d DriftDetector) previousRuns(current Run) ([]*Run, error) {
+	ids, err := d.Store.IDs()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var runs []*Run
+	for i := len(ids) - 1; i >= 0 && len(runs) < d.Window; i-- {
+		run, err := d.Store.Load(ids[i])
+		if err != nil {
+			return nil, err
+		}
+		if run.Model == current.Model && run.Aggregation == current.Aggregation && run.PredictionLength == current.PredictionLength {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// check compares the value with the average of the previous values and reports whether it deviates too much
+func (d DriftDetector) check(key, metric string, value decimal.Decimal, previous []decimal.Decimal) (outputPrinter.Alert, bool) {
+	if len(previous) == 0 {
+		return outputPrinter.Alert{}, false
+	}
+	count := decimal.NewFromInt(int64(len(previous)))
+	average := decimal.Sum(previous[0], previous[1:]...).Div(count)
+	alert := outputPrinter.Alert{Key: key, Metric: metric, Value: value, Average: average, Runs: len(previous)}
+	raised := false
+	if !average.IsZero() {
+		alert.Deviation = value.Sub(average).Div(average)
+		raised = d.Threshold.IsPositive() && alert.Deviation.Abs().GreaterThan(d.Threshold)
+	}
+	if len(previous) >= minZScoreRuns {
+		squares := decimal.Zero
+		for _, v := range previous {
+			squares = squares.Add(v.Sub(average).Pow(decimal.NewFromInt(2)))
+		}
+		variance, _ := squares.Div(count.Sub(decimal.NewFromInt(1))).Float64()
+		// the z-score is not defined if all the previous values are equal
+		if variance > 0 {
+			alert.ZScore = value.Sub(average).Div(decimal.NewFromFloat(math.Sqrt(variance)))
+			raised = raised || d.ZScore.IsPositive() && alert.ZScore.Abs().GreaterThan(d.ZScore)
+		}
+	}
+	return alert, raised
+}
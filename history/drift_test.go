@@ -0,0 +1,99 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// createDriftReport creates a report with the predictions and the last observed LTVs of US and DE
+func createDriftReport(model string, day int, usPredicted, usLTV7, dePredicted, deLTV7 float64) outputPrinter.Report {
+	return outputPrinter.Report{
+		Model:            model,
+		Aggregation:      "country",
+		GeneratedAt:      time.Date(2023, 11, day, 10, 0, 0, 0, time.UTC),
+		PredictionLength: 60,
+		Predictions:      predictor.PredictedLTVs{"US": decimal.NewFromFloat(usPredicted), "DE": decimal.NewFromFloat(dePredicted)},
+		LTVs: aggregator.AggregatedLTVsByKey{
+			"US": {decimal.NewFromFloat(usLTV7)},
+			"DE": {decimal.NewFromFloat(deLTV7)},
+		},
+	}
+}
+
+func createDriftStore(t *testing.T) Store {
+	store := Store{Dir: t.TempDir()}
+	for _, report := range []outputPrinter.Report{
+		createDriftReport("linearRegression", 1, 10, 2, 10, 1),
+		createDriftReport("linearRegression", 2, 11, 2, 10, 1.1),
+		// runs of other models are not comparable
+		createDriftReport("linearExtrapolation", 3, 100, 2, 100, 1),
+		createDriftReport("linearRegression", 4, 12, 2, 10, 0.9),
+	} {
+		assert.NoError(t, store.Save(NewRun(report, "")))
+	}
+	return store
+}
+
+func TestDriftDetector_Detect_Threshold(t *testing.T) {
+	detector := DriftDetector{Store: createDriftStore(t), Window: 7, Threshold: decimal.NewFromFloat(0.2)}
+
+	alerts, err := detector.Detect(createDriftReport("linearRegression", 5, 11.5, 1, 20, 1))
+
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 2)
+	assert.Equal(t, "DE", alerts[0].Key)
+	assert.Equal(t, outputPrinter.AlertMetricPredicted, alerts[0].Metric)
+	assert.True(t, decimal.NewFromInt(10).Equal(alerts[0].Average))
+	assert.True(t, decimal.NewFromInt(1).Equal(alerts[0].Deviation))
+	// the z-score is not defined as all the previous predictions are equal
+	assert.True(t, alerts[0].ZScore.IsZero())
+	assert.Equal(t, 3, alerts[0].Runs)
+	assert.Equal(t, "US", alerts[1].Key)
+	assert.Equal(t, outputPrinter.AlertMetricLTV7, alerts[1].Metric)
+	assert.True(t, decimal.NewFromFloat(-0.5).Equal(alerts[1].Deviation))
+}
+
+func TestDriftDetector_Detect_ZScore(t *testing.T) {
+	detector := DriftDetector{Store: createDriftStore(t), Window: 7, ZScore: decimal.NewFromInt(3)}
+
+	alerts, err := detector.Detect(createDriftReport("linearRegression", 5, 15, 1, 20, 1))
+
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "US", alerts[0].Key)
+	assert.Equal(t, outputPrinter.AlertMetricPredicted, alerts[0].Metric)
+	assert.True(t, decimal.NewFromInt(4).Equal(alerts[0].ZScore))
+}
+
+func TestDriftDetector_Detect_Window(t *testing.T) {
+	store := createDriftStore(t)
+	report := createDriftReport("linearRegression", 5, 11, 2, 10, 1.1)
+
+	// 1.1 deviates by 10% from the average of all the runs
+	alerts, err := DriftDetector{Store: store, Window: 7, Threshold: decimal.NewFromFloat(0.2)}.Detect(report)
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	// and by 22% from the latest run
+	alerts, err = DriftDetector{Store: store, Window: 1, Threshold: decimal.NewFromFloat(0.2)}.Detect(report)
+	assert.NoError(t, err)
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "DE", alerts[0].Key)
+	assert.Equal(t, 1, alerts[0].Runs)
+}
+
+func TestDriftDetector_Detect_NoHistory(t *testing.T) {
+	detector := DriftDetector{Store: Store{Dir: filepath.Join(t.TempDir(), "missing")}, Window: 7, Threshold: decimal.NewFromFloat(0.2)}
+
+	alerts, err := detector.Detect(createDriftReport("linearRegression", 5, 10, 2, 10, 1))
+
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+}
@@ -0,0 +1,185 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// idLayout makes the ids of the runs sorted in the chronological order
+	idLayout = "20060102T150405.000Z"
+	fileExt  = ".json"
+)
+
+var (
+	ErrHistoryError = errors.New("history error: %w")
+	ErrRunNotFound  = errors.New("run %s is not found")
+)
+
+// Run contains the predictions of a single run together with the data needed to explain them later
+type Run struct {
+	ID               string               `json:"id"`
+	Timestamp        time.Time            `json:"timestamp"`
+	Source           string               `json:"source"`
+	SourceHash       string               `json:"sourceHash,omitempty"`
+	Model            string               `json:"model"`
+	Aggregation      string               `json:"aggregation"`
+	PredictionLength int64                `json:"predictionLength"`
+	Keys             map[string]KeyResult `json:"keys"`
+}
+
+type KeyResult struct {
+	Users int64 `json:"users"`
+	// LTV7 is the LTV of the last observed day
+	LTV7 decimal.Decimal `json:"ltv7"`
+	// Predicted is nil if the prediction of the key failed
+	Predicted *decimal.Decimal `json:"predicted,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// NewRun creates a run from the report, sourceHash identifies the exact input of the run
+func NewRun(report outputPrinter.Report, sourceHash string) Run {
+	run := Run{
+		ID:               report.GeneratedAt.UTC().Format(idLayout),
+		Timestamp:        report.GeneratedAt,
+		Source:           report.Source,
+		SourceHash:       sourceHash,
+		Model:            report.Model,
+		Aggregation:      report.Aggregation,
+		PredictionLength: report.PredictionLength,
+		Keys:             make(map[string]KeyResult, len(report.Predictions)+len(report.Failures)),
+	}
+	keyResult := func(k string) KeyResult {
+		result := KeyResult{Users: report.Revenues[k].UsersCount}
+		if ltvs := report.LTVs[k]; len(ltvs) > 0 {
+			result.LTV7 = ltvs[len(ltvs)-1]
+		}
+		return result
+	}
+	for k, predicted := range report.Predictions {
+		predicted := predicted
+		result := keyResult(k)
+		result.Predicted = &predicted
+		run.Keys[k] = result
+	}
+	for k, failure := range report.Failures {
+		result := keyResult(k)
+		result.Error = failure.Error()
+		run.Keys[k] = result
+	}
+	return run
+}
+
+// HashFile returns the hex encoded SHA-256 of the file content
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Store keeps every run in a separate JSON file of the directory named by the id of the run
+type Store struct {
+	Dir string
+}
+
+// Save writes the run to the store creating the directory if needed
+func (s Store) Save(run Run) error {
+	err := os.MkdirAll(s.Dir, 0o755)
+	if err != nil {
+		return fmt.Errorf(ErrHistoryError.Error(), err)
+	}
+	err = outputPrinter.WriteFileAtomic(filepath.Join(s.Dir, run.ID+fileExt), func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(run)
+	})
+	if err != nil {
+		return fmt.Errorf(ErrHistoryError.Error(), err)
+	}
+	return nil
+}
+
+// IDs returns the ids of the saved runs from the oldest to the newest
+func (s Store) IDs() ([]string, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, fmt.Errorf(ErrHistoryError.Error(), err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		// temporary files of interrupted writes start with a dot
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != fileExt {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, fileExt))
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// Load reads the run with the id
+func (s Store) Load(id string) (*Run, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, id+fileExt))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf(ErrHistoryError.Error(), fmt.Errorf(ErrRunNotFound.Error(), id))
+	}
+	if err != nil {
+		return nil, fmt.Errorf(ErrHistoryError.Error(), err)
+	}
+	var run Run
+	err = json.Unmarshal(data, &run)
+	if err != nil {
+		return nil, fmt.Errorf(ErrHistoryError.Error(), fmt.Errorf("%s: %w", id, err))
+	}
+	return &run, nil
+}
+
+// Last returns up to n latest runs from the oldest to the newest
+func (s Store) Last(n int) ([]*Run, error) {
+	ids, err := s.IDs()
+	if err != nil {
+		return nil, err
+	}
+	ids = ids[max(0, len(ids)-n):]
+	runs := make([]*Run, 0, len(ids))
+	for _, id := range ids {
+		run, err := s.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// Recorder saves the reports of the processor to the store
+type Recorder struct {
+	Store Store
+	// SourceHash is the hash of the input the reports are generated from, see HashFile
+	SourceHash string
+}
+
+func (r Recorder) Record(report outputPrinter.Report) error {
+	return r.Store.Save(NewRun(report, r.SourceHash))
+}
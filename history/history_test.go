@@ -0,0 +1,94 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestReport(generatedAt time.Time) outputPrinter.Report {
+	return outputPrinter.Report{
+		Model:            "linearRegression",
+		Aggregation:      "country",
+		Source:           "data.csv",
+		GeneratedAt:      generatedAt,
+		PredictionLength: 60,
+		Predictions:      predictor.PredictedLTVs{"US": decimal.NewFromInt(10), "DE": decimal.NewFromInt(20)},
+		Revenues:         aggregator.AggregatedRevenuesByKey{"US": {UsersCount: 100}, "DE": {UsersCount: 50}, "FR": {UsersCount: 3}},
+		LTVs: aggregator.AggregatedLTVsByKey{
+			"US": {decimal.NewFromInt(1), decimal.NewFromInt(2)},
+			"FR": {decimal.NewFromInt(1)},
+		},
+		Failures: predictor.Failures{"FR": errors.New("not enough data")},
+	}
+}
+
+func TestNewRun(t *testing.T) {
+	run := NewRun(createTestReport(time.Date(2023, 11, 5, 10, 0, 0, 0, time.UTC)), "abc")
+
+	assert.Equal(t, "20231105T100000.000Z", run.ID)
+	assert.Equal(t, "abc", run.SourceHash)
+	assert.Equal(t, "linearRegression", run.Model)
+	assert.Len(t, run.Keys, 3)
+	assert.Equal(t, int64(100), run.Keys["US"].Users)
+	assert.True(t, decimal.NewFromInt(2).Equal(run.Keys["US"].LTV7))
+	assert.True(t, decimal.NewFromInt(10).Equal(*run.Keys["US"].Predicted))
+	assert.True(t, decimal.NewFromInt(20).Equal(*run.Keys["DE"].Predicted))
+	assert.Nil(t, run.Keys["FR"].Predicted)
+	assert.Equal(t, "not enough data", run.Keys["FR"].Error)
+}
+
+func TestStore(t *testing.T) {
+	store := Store{Dir: filepath.Join(t.TempDir(), "history")}
+	first := time.Date(2023, 11, 5, 10, 0, 0, 0, time.UTC)
+
+	_, err := store.IDs()
+	assert.Error(t, err)
+
+	for i := 2; i >= 0; i-- {
+		assert.NoError(t, store.Save(NewRun(createTestReport(first.Add(time.Duration(i)*24*time.Hour)), "abc")))
+	}
+	ids, err := store.IDs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"20231105T100000.000Z", "20231106T100000.000Z", "20231107T100000.000Z"}, ids)
+
+	runs, err := store.Last(2)
+	assert.NoError(t, err)
+	assert.Len(t, runs, 2)
+	assert.Equal(t, "20231106T100000.000Z", runs[0].ID)
+	assert.True(t, first.Add(48*time.Hour).Equal(runs[1].Timestamp))
+	assert.True(t, decimal.NewFromInt(10).Equal(*runs[1].Keys["US"].Predicted))
+
+	_, err = store.Load("missing")
+	assert.EqualError(t, err, "history error: run missing is not found")
+}
+
+func TestStore_IDs_SkipsOtherFiles(t *testing.T) {
+	store := Store{Dir: t.TempDir()}
+	for _, name := range []string{"20231105T100000.000Z.json", ".20231106T100000.000Z.json.tmp1", "notes.txt"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(store.Dir, name), []byte("{}"), 0o600))
+	}
+
+	ids, err := store.IDs()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"20231105T100000.000Z"}, ids)
+}
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("abc"), 0o600))
+
+	hash, err := HashFile(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", hash)
+}
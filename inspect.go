@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/pklimuk/ltv-predictor/config"
+	flagsParser "github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/inspector"
+)
+
+// inspect prints the users count, the revenue and the aggregated LTV curve of every key without predicting
+func inspect(args []string) int {
+	flags, parseErr := flagsParser.ParseCommand(flagsParser.InspectCommand, args, os.LookupEnv)
+	appConfig, err := config.CreateAppConfig(flags)
+	if flags.Round < 0 {
+		err = errors.Join(err, fmt.Errorf(config.ErrConfigError.Error(), config.ErrRoundNegative))
+	}
+	err = errors.Join(parseErr, err)
+	if err != nil {
+		log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+		return exitUsage
+	}
+
+	ctx, cancel := newContext(flags.Timeout)
+	defer cancel()
+
+	revenues, ltvs, err := newProcessor(appConfig).Aggregate(ctx)
+	if err != nil {
+		log.Printf("An error occurred during processing:\n\t%s", indent(err))
+		return exitFailure
+	}
+	err = inspector.Print(os.Stdout, inspector.Inspect(revenues, ltvs), int32(flags.Round))
+	if err != nil {
+		log.Printf("An error occurred during printing: %s", err)
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,84 @@
+package inspector
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/shopspring/decimal"
+)
+
+// Curve contains the aggregated data of one key as the predictor receives it
+type Curve struct {
+	Key   string
+	Users int64
+	// Revenue is the summed revenue of all the users on the last observed day
+	Revenue decimal.Decimal
+	LTVs    []decimal.Decimal
+	// Growth is the relative change of the LTV compared to the previous day in percents, the first day
+	// and days following a zero LTV have no growth
+	Growth []*decimal.Decimal
+}
+
+var hundred = decimal.NewFromInt(100)
+
+// Inspect combines the aggregated revenues and LTVs of every key, curves are sorted by key
+func Inspect(ar aggregator.AggregatedRevenuesByKey, al aggregator.AggregatedLTVsByKey) []Curve {
+	curves := make([]Curve, 0, len(al))
+	for k, ltvs := range al {
+		curve := Curve{Key: k, Users: ar[k].UsersCount, LTVs: ltvs, Growth: make([]*decimal.Decimal, len(ltvs))}
+		if revenues := ar[k].Revenues; len(revenues) > 0 {
+			curve.Revenue = revenues[len(revenues)-1]
+		}
+		for i := 1; i < len(ltvs); i++ {
+			if ltvs[i-1].IsZero() {
+				continue
+			}
+			growth := ltvs[i].Sub(ltvs[i-1]).Div(ltvs[i-1]).Mul(hundred)
+			curve.Growth[i] = &growth
+		}
+		curves = append(curves, curve)
+	}
+	slices.SortFunc(curves, func(a, b Curve) int { return strings.Compare(a.Key, b.Key) })
+	return curves
+}
+
+// Print prints the users count and the revenue of every key followed by an aligned table of its
+// LTVs and their growth, values are rounded to the given number of decimal places
+func Print(w io.Writer, curves []Curve, round int32) error {
+	for i, curve := range curves {
+		if i > 0 {
+			_, err := fmt.Fprintln(w)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := fmt.Fprintf(w, "%s: %d users, revenue %s\n", curve.Key, curve.Users, curve.Revenue.StringFixed(round))
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		_, err = fmt.Fprintln(tw, "DAY\tLTV\tGROWTH %\t")
+		if err != nil {
+			return err
+		}
+		for day, ltv := range curve.LTVs {
+			growth := "-"
+			if curve.Growth[day] != nil {
+				growth = curve.Growth[day].StringFixed(1)
+			}
+			_, err = fmt.Fprintf(tw, "%d\t%s\t%s\t\n", day+1, ltv.StringFixed(round), growth)
+			if err != nil {
+				return err
+			}
+		}
+		err = tw.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
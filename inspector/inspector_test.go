@@ -0,0 +1,65 @@
+package inspector
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func decimals(values ...int64) []decimal.Decimal {
+	result := make([]decimal.Decimal, 0, len(values))
+	for _, v := range values {
+		result = append(result, decimal.NewFromInt(v))
+	}
+	return result
+}
+
+func TestInspect(t *testing.T) {
+	ar := aggregator.AggregatedRevenuesByKey{
+		"US": {Revenues: decimals(10, 20, 25), UsersCount: 10},
+		"DE": {Revenues: decimals(0, 5, 10), UsersCount: 5},
+	}
+	al := aggregator.AggregatedLTVsByKey{
+		"US": decimals(1, 2, 2),
+		"DE": decimals(0, 1, 2),
+	}
+	al["US"][2] = decimal.NewFromFloat(2.5)
+
+	curves := Inspect(ar, al)
+
+	assert.Len(t, curves, 2)
+	assert.Equal(t, "DE", curves[0].Key)
+	assert.Equal(t, int64(5), curves[0].Users)
+	assert.True(t, decimal.NewFromInt(10).Equal(curves[0].Revenue))
+	// growth after a zero LTV is not defined
+	assert.Nil(t, curves[0].Growth[0])
+	assert.Nil(t, curves[0].Growth[1])
+	assert.True(t, decimal.NewFromInt(100).Equal(*curves[0].Growth[2]))
+	assert.True(t, decimal.NewFromInt(25).Equal(*curves[1].Growth[2]))
+
+	var buf bytes.Buffer
+	assert.NoError(t, Print(&buf, curves, 2))
+	assert.Equal(t, "DE: 5 users, revenue 10.00\n"+
+		"  DAY   LTV  GROWTH %\n"+
+		"    1  0.00         -\n"+
+		"    2  1.00         -\n"+
+		"    3  2.00     100.0\n"+
+		"\n"+
+		"US: 10 users, revenue 25.00\n"+
+		"  DAY   LTV  GROWTH %\n"+
+		"    1  1.00         -\n"+
+		"    2  2.00     100.0\n"+
+		"    3  2.50      25.0\n", buf.String())
+}
+
+func TestInspect_Empty(t *testing.T) {
+	curves := Inspect(nil, nil)
+
+	assert.Empty(t, curves)
+	var buf bytes.Buffer
+	assert.NoError(t, Print(&buf, curves, 2))
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,208 @@
+// Package ltv is the library API of the predictor. It runs the same pipeline as the command line
+// tool on in-memory records and returns structured results instead of printing them.
+//
+//	result, err := ltv.Predict(ctx, records, ltv.WithModel("linearRegression"), ltv.WithAggregation("campaign"), ltv.WithHorizon(90))
+package ltv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/config"
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/pklimuk/ltv-predictor/processor"
+	"github.com/shopspring/decimal"
+)
+
+// Record contains cumulative revenues of a user or a group of users for every observed day
+type Record struct {
+	Country    string
+	CampaignID string
+	UsersCount int64
+	// Revenues are the cumulative revenues of the users, the first one is of the first day after the install
+	Revenues []decimal.Decimal
+}
+
+// Options are the settings of a prediction
+type Options struct {
+	// Model is the model used for prediction(linearExtrapolation|linearRegression)
+	Model string
+	// Aggregation is the field records are aggregated by(country|campaign)
+	Aggregation string
+	// Horizon is the day the LTV is predicted for
+	Horizon int64
+	// Workers is the number of keys predicted concurrently
+	Workers int
+	// KeepGoing makes Predict return the predictions of the other keys together with *predictor.PartialError
+	// if predictions of some keys failed instead of failing the whole prediction
+	KeepGoing bool
+}
+
+// Option configures a prediction
+type Option func(o *Options)
+
+// Result contains predictions sorted by key and the parameters they were made with
+type Result struct {
+	Model       string
+	Aggregation string
+	Horizon     int64
+	GeneratedAt time.Time
+	Predictions []Prediction
+	// Failures contains the keys which could not be predicted in the keep going mode sorted by key
+	Failures []Failure
+}
+
+// Prediction contains the predicted LTV of a key together with the observed LTVs it is based on
+type Prediction struct {
+	Key       string
+	Users     int64
+	LTVs      []decimal.Decimal
+	Predicted decimal.Decimal
+}
+
+// Failure contains the reason the prediction of a key failed
+type Failure struct {
+	Key   string
+	Users int64
+	Err   error
+}
+
+// DefaultOptions returns the options Predict starts from before applying the options passed to it
+func DefaultOptions() Options {
+	return Options{
+		Model:       flagsParser.DefaultModel,
+		Aggregation: flagsParser.DefaultAggregateBy,
+		Horizon:     flagsParser.DefaultPredictionLength,
+		Workers:     flagsParser.DefaultWorkers,
+	}
+}
+
+// WithModel sets the model used for prediction(linearExtrapolation|linearRegression), linearExtrapolation by default
+func WithModel(model string) Option {
+	return func(o *Options) {
+		o.Model = model
+	}
+}
+
+// WithAggregation sets the field records are aggregated by(country|campaign), country by default
+func WithAggregation(aggregation string) Option {
+	return func(o *Options) {
+		o.Aggregation = aggregation
+	}
+}
+
+// WithHorizon sets the day the LTV is predicted for, 60 by default
+func WithHorizon(days int64) Option {
+	return func(o *Options) {
+		o.Horizon = days
+	}
+}
+
+// WithWorkers sets the number of keys predicted concurrently, keys are predicted one by one by default
+func WithWorkers(workers int) Option {
+	return func(o *Options) {
+		o.Workers = workers
+	}
+}
+
+// WithKeepGoing makes Predict return the predictions of the other keys if predictions of some keys failed, see Options
+func WithKeepGoing() Option {
+	return func(o *Options) {
+		o.KeepGoing = true
+	}
+}
+
+// Predict aggregates the records and predicts LTV for every key. In the keep going mode the result is returned
+// together with *predictor.PartialError if predictions of some keys failed, the failures are listed in the result
+func Predict(ctx context.Context, records []Record, opts ...Option) (*Result, error) {
+	o := DefaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	agg, pred, err := createComponents(o)
+	if err != nil {
+		return nil, err
+	}
+	p := processor.Processor{
+		Parser:           fileParser.MemoryParser{Revenues: toRevenues(records)},
+		Aggregator:       agg,
+		Predictor:        pred,
+		Model:            o.Model,
+		AggregateBy:      o.Aggregation,
+		PredictionLength: o.Horizon,
+	}
+	report, err := p.Run(ctx)
+	var partialErr *predictor.PartialError
+	if err != nil && !errors.As(err, &partialErr) {
+		return nil, err
+	}
+
+	result := &Result{
+		Model:       report.Model,
+		Aggregation: report.Aggregation,
+		Horizon:     report.PredictionLength,
+		GeneratedAt: report.GeneratedAt,
+		Predictions: make([]Prediction, 0, len(report.Predictions)),
+	}
+	for _, key := range report.Keys() {
+		result.Predictions = append(result.Predictions, Prediction{
+			Key:       key,
+			Users:     report.Revenues[key].UsersCount,
+			LTVs:      report.LTVs[key],
+			Predicted: report.Predictions[key],
+		})
+	}
+	for _, key := range report.FailedKeys() {
+		result.Failures = append(result.Failures, Failure{
+			Key:   key,
+			Users: report.Revenues[key].UsersCount,
+			Err:   report.Failures[key],
+		})
+	}
+	return result, err
+}
+
+// createComponents creates the aggregator and the predictor registered with the names of the options,
+// all invalid options are reported at once
+func createComponents(o Options) (aggregator.Aggregator, predictor.Predictor, error) {
+	var errs []error
+	var agg aggregator.Aggregator
+	aggregatorComponent, err := config.Aggregators.Get(o.Aggregation)
+	if err == nil {
+		agg, err = aggregatorComponent.Factory(config.Options{})
+	}
+	errs = append(errs, err)
+
+	var pred predictor.Predictor
+	predictorComponent, err := config.Predictors.Get(o.Model)
+	if err == nil {
+		pred, err = predictorComponent.Factory(config.Options{
+			"workers":   strconv.Itoa(o.Workers),
+			"keepGoing": strconv.FormatBool(o.KeepGoing),
+		})
+	}
+	errs = append(errs, err, config.ValidatePredictionLength(o.Horizon))
+	if err = errors.Join(errs...); err != nil {
+		return nil, nil, fmt.Errorf(config.ErrConfigError.Error(), err)
+	}
+	return agg, pred, nil
+}
+
+func toRevenues(records []Record) []fileParser.Revenues {
+	result := make([]fileParser.Revenues, 0, len(records))
+	for _, r := range records {
+		result = append(result, fileParser.Revenues{
+			Revenues:   r.Revenues,
+			Country:    r.Country,
+			CampaignID: r.CampaignID,
+			UsersCount: r.UsersCount,
+		})
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package ltv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func createRecord(country, campaign string, users int64, values ...int64) Record {
+	revenues := make([]decimal.Decimal, 0, len(values))
+	for _, v := range values {
+		revenues = append(revenues, decimal.NewFromInt(v))
+	}
+	return Record{Revenues: revenues, Country: country, CampaignID: campaign, UsersCount: users}
+}
+
+func TestPredict(t *testing.T) {
+	records := []Record{
+		createRecord("TR", "c1", 1, 1, 2, 3),
+		createRecord("US", "c1", 1, 2, 4, 6),
+		createRecord("TR", "c2", 1, 1, 2, 3),
+	}
+
+	t.Run("Default options", func(t *testing.T) {
+		result, err := Predict(context.Background(), records)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "linearExtrapolation", result.Model)
+		assert.Equal(t, "country", result.Aggregation)
+		assert.Equal(t, int64(60), result.Horizon)
+		assert.False(t, result.GeneratedAt.IsZero())
+		assert.Len(t, result.Predictions, 2)
+		assert.Equal(t, "TR", result.Predictions[0].Key)
+		assert.Equal(t, int64(2), result.Predictions[0].Users)
+		assert.True(t, decimal.NewFromInt(60).Equal(result.Predictions[0].Predicted))
+		assert.True(t, decimal.NewFromInt(120).Equal(result.Predictions[1].Predicted))
+	})
+
+	t.Run("Custom options", func(t *testing.T) {
+		result, err := Predict(context.Background(), records, WithModel("linearRegression"), WithAggregation("campaign"), WithHorizon(10))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "linearRegression", result.Model)
+		assert.Equal(t, "campaign", result.Aggregation)
+		assert.Equal(t, int64(10), result.Horizon)
+		assert.Equal(t, []string{"c1", "c2"}, []string{result.Predictions[0].Key, result.Predictions[1].Key})
+		assert.True(t, decimal.NewFromInt(15).Equal(result.Predictions[0].Predicted))
+	})
+}
+
+func TestPredict_KeepGoing(t *testing.T) {
+	records := []Record{
+		createRecord("TR", "c1", 2, 2, 4, 6),
+		createRecord("US", "c1", 1, 1),
+	}
+
+	result, err := Predict(context.Background(), records, WithKeepGoing(), WithWorkers(2), WithHorizon(10))
+
+	var partialErr *predictor.PartialError
+	assert.ErrorAs(t, err, &partialErr)
+	assert.Len(t, partialErr.Failures, 1)
+	assert.Len(t, result.Predictions, 1)
+	assert.Equal(t, "TR", result.Predictions[0].Key)
+	assert.True(t, decimal.NewFromInt(10).Equal(result.Predictions[0].Predicted))
+	assert.Equal(t, []Failure{{Key: "US", Users: 1, Err: predictor.ErrNotEnoughData}}, result.Failures)
+
+	_, err = Predict(context.Background(), records, WithHorizon(10))
+	assert.EqualError(t, err, "predictor error: US: not enough data to make prediction")
+}
+
+func TestPredict_Errors(t *testing.T) {
+	records := []Record{createRecord("TR", "c1", 1, 1, 2, 3)}
+
+	_, err := Predict(context.Background(), records, WithModel("unknown"))
+	assert.EqualError(t, err, "config error: unknown model")
+
+	_, err = Predict(context.Background(), records, WithModel("unknown"), WithAggregation("city"), WithHorizon(0))
+	assert.EqualError(t, err, "config error: unknown aggregation field\nunknown model\nprediction length should be greater than 0")
+
+	_, err = Predict(context.Background(), records, WithHorizon(5))
+	assert.EqualError(t, err, "config error: prediction length should be greater than 7")
+
+	_, err = Predict(context.Background(), nil)
+	assert.EqualError(t, err, "aggregator error: no data to aggregate")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Predict(ctx, records)
+	assert.ErrorIs(t, err, context.Canceled)
+}
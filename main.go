@@ -1,31 +1,119 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/pklimuk/ltv-predictor/config"
 	flagsParser "github.com/pklimuk/ltv-predictor/flagsParser"
 	"github.com/pklimuk/ltv-predictor/processor"
 )
 
+// Exit codes shared by all the commands
+const (
+	exitOK      = 0
+	exitFailure = 1
+	// exitUsage is also used by the flag package for invalid flags
+	exitUsage = 2
+	// exitPartialSuccess is used if some keys failed in the keep going mode
+	exitPartialSuccess = 3
+	// exitInvalidData is used by validate if the source file is invalid
+	exitInvalidData = 4
+	// exitAlerts is used if predictions deviate from the history
+	exitAlerts = 5
+)
+
+type command struct {
+	flagsParser.Command
+	run func(args []string) int
+}
+
+var commands = []command{
+	{flagsParser.PredictCommand, predict},
+	{flagsParser.BacktestCommand, backtest},
+	{flagsParser.FitCommand, fit},
+	{flagsParser.ScoreCommand, score},
+	{flagsParser.DiffCommand, diff},
+	{flagsParser.ValidateCommand, validate},
+	{flagsParser.BidsCommand, recommendBids},
+	{flagsParser.InspectCommand, inspect},
+	{flagsParser.ServeCommand, serve},
+}
+
 func main() {
-	flags := flagsParser.ParseFlags()
+	flagsParser.Components = config.Describe
+	os.Exit(run(os.Args[1:]))
+}
 
-	appConfig, err := config.CreateAppConfig(flags)
-	if err != nil {
-		log.Fatalf("An error occurred during configuration:\n\t%v", err)
+// run executes the command named by the first argument, predict is used if the arguments start with a flag
+func run(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return predict(args)
+	}
+	if args[0] == "help" {
+		usage(os.Stdout)
+		return exitOK
 	}
+	for _, c := range commands {
+		if c.Name == args[0] {
+			return c.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	usage(os.Stderr)
+	return exitUsage
+}
 
-	processor := processor.Processor{
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s [command] [flags]\n\nCommands:\n", filepath.Base(os.Args[0]))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.Name, c.Description)
+	}
+	_ = tw.Flush()
+	fmt.Fprintf(w, "\nRun \"%s <command> -help\" to see the flags of the command.\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(w, "Exit codes: %d - success, %d - failure, %d - invalid usage, %d - some keys failed, %d - invalid source file, %d - alerts raised\n",
+		exitOK, exitFailure, exitUsage, exitPartialSuccess, exitInvalidData, exitAlerts)
+}
+
+// newContext returns a context cancelled on SIGINT, SIGTERM or after the timeout if it is positive
+func newContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+func newProcessor(appConfig *config.AppConfig) *processor.Processor {
+	return &processor.Processor{
 		Parser:           appConfig.Parser,
 		Aggregator:       appConfig.Aggregator,
+		Filter:           appConfig.Filter,
 		Predictor:        appConfig.Predictor,
+		Model:            appConfig.Model,
+		AggregateBy:      appConfig.AggregateBy,
+		Source:           appConfig.Source,
 		PredictionLength: appConfig.PredictionLength,
 		OutputPrinter:    appConfig.OutputPrinter,
+		OutputPath:       appConfig.OutputPath,
+		Streams:          appConfig.Streams,
 	}
+}
 
-	err = processor.Process()
-	if err != nil {
-		log.Fatalf("An error occurred during processing:\n\t%v", err)
-	}
+// indent indents every line of the error, so errors joined by errors.Join are aligned in the log
+func indent(err error) string {
+	return strings.ReplaceAll(err.Error(), "\n", "\n\t")
 }
@@ -0,0 +1,128 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+)
+
+const (
+	FormatJSON  = "json"
+	FormatSlack = "slack"
+	// DefaultBackoff is the delay before the first retry used by the command line
+	DefaultBackoff = time.Second
+	// maxErrorBodyBytes limits the part of the response body kept in the error
+	maxErrorBodyBytes = 512
+)
+
+var (
+	ErrNotifierError    = errors.New("notifier error: %w")
+	ErrUnknownFormat    = errors.New("unknown webhook format(%s)")
+	ErrUnexpectedStatus = errors.New("unexpected status(%d): %s")
+)
+
+// Notifier posts the summary of the report to the webhook after every run
+type Notifier struct {
+	URL string
+	// Format is FormatJSON or FormatSlack, FormatJSON is used if empty
+	Format string
+	// Client is http.DefaultClient if nil
+	Client *http.Client
+	// Timeout limits every attempt, not limited if 0
+	Timeout time.Duration
+	// Retries is the number of attempts made after the first one failed with a network error, 5xx or 429 status
+	Retries int
+	// Backoff is the delay before the first retry, it is doubled after every retry
+	Backoff time.Duration
+	// Top is the number of the highest predictions included in the summary
+	Top int
+}
+
+// statusError is returned if the webhook responded with a status other than 2xx
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf(ErrUnexpectedStatus.Error(), e.code, e.body)
+}
+
+// retryable reports whether the request could succeed if it is sent again
+func (e *statusError) retryable() bool {
+	return e.code >= http.StatusInternalServerError || e.code == http.StatusTooManyRequests
+}
+
+// Notify posts the summary of the report and retries the failed attempts
+func (n Notifier) Notify(ctx context.Context, report outputPrinter.Report) error {
+	body, err := n.body(report)
+	if err != nil {
+		return fmt.Errorf(ErrNotifierError.Error(), err)
+	}
+	backoff := n.Backoff
+	for attempt := 0; ; attempt++ {
+		err = n.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && !statusErr.retryable() || attempt >= n.Retries || ctx.Err() != nil {
+			return fmt.Errorf(ErrNotifierError.Error(), err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf(ErrNotifierError.Error(), ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (n Notifier) body(report outputPrinter.Report) ([]byte, error) {
+	summary := NewSummary(report, n.Top)
+	switch n.Format {
+	case "", FormatJSON:
+		return json.Marshal(summary)
+	case FormatSlack:
+		return json.Marshal(NewSlackMessage(summary))
+	default:
+		return nil, fmt.Errorf(ErrUnknownFormat.Error(), n.Format)
+	}
+}
+
+func (n Notifier) post(ctx context.Context, body []byte) error {
+	if n.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.Timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// a part of the body is kept as it usually explains the status
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return &statusError{code: resp.StatusCode, body: string(bytes.TrimSpace(b))}
+	}
+	// the body is drained so the connection could be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
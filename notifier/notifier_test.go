@@ -0,0 +1,237 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestReport() outputPrinter.Report {
+	return outputPrinter.Report{
+		Model:            "linearExtrapolation",
+		Aggregation:      "country",
+		Source:           "data.csv",
+		GeneratedAt:      time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC),
+		PredictionLength: 60,
+		Predictions: predictor.PredictedLTVs{
+			"DE": decimal.NewFromInt(30),
+			"FR": decimal.NewFromInt(10),
+			"US": decimal.NewFromInt(50),
+		},
+		Revenues: aggregator.AggregatedRevenuesByKey{
+			"DE": {UsersCount: 20},
+			"FR": {UsersCount: 5},
+			"US": {UsersCount: 100},
+			"PL": {UsersCount: 1},
+		},
+		LTVs: aggregator.AggregatedLTVsByKey{
+			"DE": {decimal.NewFromInt(5), decimal.NewFromInt(6)},
+			"FR": {decimal.NewFromInt(1), decimal.NewFromInt(2)},
+			"US": {decimal.NewFromInt(7), decimal.NewFromInt(8)},
+			"PL": {decimal.NewFromInt(1)},
+		},
+		Failures: predictor.Failures{"PL": predictor.ErrNotEnoughData},
+		Alerts: []outputPrinter.Alert{{
+			Key:       "US",
+			Metric:    outputPrinter.AlertMetricPredicted,
+			Value:     decimal.NewFromInt(50),
+			Average:   decimal.NewFromInt(25),
+			Deviation: decimal.NewFromInt(1),
+			Runs:      3,
+		}},
+		Quality: outputPrinter.DataQuality{Records: 10, FilteredRecords: 2, FilteredKeys: 1, UnknownCurrencies: map[string]int{"TRY": 3, "GBP": 1}},
+	}
+}
+
+func TestNewSummary(t *testing.T) {
+	summary := NewSummary(createTestReport(), 2)
+
+	assert.Equal(t, 4, summary.Keys)
+	assert.Equal(t, int64(126), summary.Users)
+	assert.Equal(t, []Prediction{
+		{Key: "US", Users: 100, LTV7: "8", Predicted: "50"},
+		{Key: "DE", Users: 20, LTV7: "6", Predicted: "30"},
+	}, summary.TopPredictions)
+	assert.Equal(t, []outputPrinter.JSONFailure{{Key: "PL", Users: 1, Error: predictor.ErrNotEnoughData.Error()}}, summary.Failures)
+	assert.Equal(t, DataQuality{Records: 10, FilteredRecords: 2, FilteredKeys: 1, FailedKeys: 1,
+		UnknownCurrencies: map[string]int{"GBP": 1, "TRY": 3}}, summary.DataQuality)
+	assert.Len(t, summary.Alerts, 1)
+	assert.Equal(t, json.Number("1"), summary.Alerts[0].Deviation)
+}
+
+func TestNewSummary_TopExceedsKeys(t *testing.T) {
+	summary := NewSummary(createTestReport(), 10)
+
+	assert.Len(t, summary.TopPredictions, 3)
+	assert.Equal(t, "FR", summary.TopPredictions[2].Key)
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	var received Summary
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	err := Notifier{URL: server.URL, Top: 1}.Notify(context.Background(), createTestReport())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "linearExtrapolation", received.Model)
+	assert.Equal(t, []Prediction{{Key: "US", Users: 100, LTV7: "8", Predicted: "50"}}, received.TopPredictions)
+	assert.Equal(t, 1, received.DataQuality.FailedKeys)
+	assert.Equal(t, map[string]int{"GBP": 1, "TRY": 3}, received.DataQuality.UnknownCurrencies)
+}
+
+func TestNotifier_Notify_Slack(t *testing.T) {
+	var received SlackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	err := Notifier{URL: server.URL, Format: FormatSlack, Top: 5}.Notify(context.Background(), createTestReport())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "LTV predictions: linearExtrapolation by country, 4 keys, 1 failed, 1 alerts", received.Text)
+	assert.Len(t, received.Blocks, 5)
+	assert.Equal(t, "header", received.Blocks[0].Type)
+	assert.Contains(t, received.Blocks[1].Text.Text, "Records: 10, filtered records: 2, filtered keys: 1, failed keys: 1, unknown currencies: GBP 1, TRY 3")
+	assert.Equal(t, "*Top predictions*\n• `US` 50.00 (LTV7 8.00, 100 users)\n• `DE` 30.00 (LTV7 6.00, 20 users)\n• `FR` 10.00 (LTV7 2.00, 5 users)",
+		received.Blocks[2].Text.Text)
+	assert.Equal(t, "*Failed keys*\n• `PL` "+predictor.ErrNotEnoughData.Error(), received.Blocks[3].Text.Text)
+	assert.Equal(t, "*Alerts*\n• :warning: `US` predicted 50.00 deviates 100.0% from the average 25.00 of 3 runs", received.Blocks[4].Text.Text)
+}
+
+func TestNewSlackMessage_LargeReport(t *testing.T) {
+	report := createTestReport()
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("campaign-%04d", i)
+		report.Revenues[key] = aggregator.AggregatedRevenues{UsersCount: 1}
+		report.Failures[key] = predictor.ErrNotEnoughData
+	}
+
+	message := NewSlackMessage(NewSummary(report, 1000))
+
+	assert.LessOrEqual(t, len(message.Blocks), slackMaxBlocks)
+	for _, b := range message.Blocks {
+		assert.LessOrEqual(t, len(b.Text.Text), slackMaxSectionLength)
+	}
+	failures := strings.Split(message.Blocks[3].Text.Text, "\n")
+	assert.Equal(t, "*Failed keys*", failures[0])
+	assert.Equal(t, "• `PL` "+predictor.ErrNotEnoughData.Error(), failures[1])
+	// every failed key is either listed or counted
+	assert.Equal(t, fmt.Sprintf("…and %d more keys", 1001-(len(failures)-2)), failures[len(failures)-1])
+}
+
+func TestNewSlackMessage_LongTitle(t *testing.T) {
+	report := createTestReport()
+	report.Model = strings.Repeat("é", 200)
+
+	message := NewSlackMessage(NewSummary(report, 1))
+
+	title := []rune(message.Blocks[0].Text.Text)
+	assert.Len(t, title, slackMaxHeaderLength)
+	assert.Equal(t, "LTV predictions: éé", string(title[:19]))
+	assert.Equal(t, "é…", string(title[len(title)-2:]))
+	// the fallback text keeps the whole title
+	assert.Contains(t, message.Text, report.Model+" by country")
+}
+
+func TestNotifier_Notify_RetriesServerErrors(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	err := Notifier{URL: server.URL, Retries: 2, Backoff: time.Millisecond}.Notify(context.Background(), createTestReport())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestNotifier_Notify_RetriesExhausted(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	err := Notifier{URL: server.URL, Retries: 2, Backoff: time.Millisecond}.Notify(context.Background(), createTestReport())
+
+	assert.EqualError(t, err, "notifier error: unexpected status(429): ")
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestNotifier_Notify_ClientErrorNotRetried(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, "invalid_blocks\n")
+	}))
+	defer server.Close()
+
+	err := Notifier{URL: server.URL, Retries: 3, Backoff: time.Millisecond}.Notify(context.Background(), createTestReport())
+
+	assert.EqualError(t, err, "notifier error: unexpected status(400): invalid_blocks")
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestNotifier_Notify_Timeout(t *testing.T) {
+	var attempts atomic.Int32
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	err := Notifier{URL: server.URL, Timeout: 50 * time.Millisecond, Retries: 1, Backoff: time.Millisecond}.Notify(context.Background(), createTestReport())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestNotifier_Notify_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Notifier{URL: server.URL, Retries: 3, Backoff: time.Hour}.Notify(ctx, createTestReport())
+
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestNotifier_Notify_UnknownFormat(t *testing.T) {
+	err := Notifier{URL: "http://localhost", Format: "xml"}.Notify(context.Background(), createTestReport())
+
+	assert.EqualError(t, err, "notifier error: unknown webhook format(xml)")
+}
@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	slackRound = 2
+	// limits of Slack, messages exceeding them are rejected by the webhook
+	slackMaxBlocks        = 50
+	slackMaxHeaderLength  = 150
+	slackMaxSectionLength = 3000
+)
+
+// SlackMessage is the summary in the Block Kit format accepted by Slack incoming webhooks,
+// Text is shown in notifications and by clients which do not support blocks
+type SlackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []SlackBlock `json:"blocks"`
+}
+
+type SlackBlock struct {
+	Type string     `json:"type"`
+	Text *SlackText `json:"text,omitempty"`
+}
+
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// NewSlackMessage renders the summary as a header followed by sections of the predictions, failures and alerts
+func NewSlackMessage(s Summary) SlackMessage {
+	title := fmt.Sprintf("LTV predictions: %s by %s", s.Model, s.Aggregation)
+	text := fmt.Sprintf("%s, %d keys, %d failed, %d alerts", title, s.Keys, len(s.Failures), len(s.Alerts))
+	message := SlackMessage{Text: text, Blocks: []SlackBlock{
+		header(title),
+		section(fmt.Sprintf("*%d* keys, *%d* users, %d-day LTV of `%s` generated at %s\n"+
+			"Records: %d, filtered records: %d, filtered keys: %d, failed keys: %d",
+			s.Keys, s.Users, s.PredictionLength, s.Source, s.GeneratedAt.UTC().Format("2006-01-02 15:04:05 UTC"),
+			s.DataQuality.Records, s.DataQuality.FilteredRecords, s.DataQuality.FilteredKeys, s.DataQuality.FailedKeys) +
+			unknownCurrencies(s.DataQuality.UnknownCurrencies)),
+	}}
+	if len(s.TopPredictions) > 0 {
+		lines := make([]string, 0, len(s.TopPredictions))
+		for _, p := range s.TopPredictions {
+			lines = append(lines, fmt.Sprintf("• `%s` %s (LTV7 %s, %d users)", p.Key, fixed(p.Predicted), fixed(p.LTV7), p.Users))
+		}
+		message.Blocks = append(message.Blocks, listSection("*Top predictions*", lines, "keys"))
+	}
+	if len(s.Failures) > 0 {
+		lines := make([]string, 0, len(s.Failures))
+		for _, f := range s.Failures {
+			lines = append(lines, fmt.Sprintf("• `%s` %s", f.Key, f.Error))
+		}
+		message.Blocks = append(message.Blocks, listSection("*Failed keys*", lines, "keys"))
+	}
+	if len(s.Alerts) > 0 {
+		lines := make([]string, 0, len(s.Alerts))
+		for _, a := range s.Alerts {
+			lines = append(lines, fmt.Sprintf("• :warning: `%s` %s %s deviates %s%% from the average %s of %d runs",
+				a.Key, a.Metric, fixed(a.Value), percent(a.Deviation), fixed(a.Average), a.Runs))
+		}
+		message.Blocks = append(message.Blocks, listSection("*Alerts*", lines, "alerts"))
+	}
+	message.Blocks = message.Blocks[:min(len(message.Blocks), slackMaxBlocks)]
+	return message
+}
+
+// listSection renders the title and the lines as a section, the lines which do not fit into the length limit of
+// the section are replaced with an "…and N more" line
+func listSection(title string, lines []string, noun string) SlackBlock {
+	text := title
+	for i, line := range lines {
+		candidate := text + "\n" + line
+		// the rest of the lines should still fit if they are cut after this one
+		if i < len(lines)-1 {
+			candidate += "\n" + moreLine(len(lines)-i-1, noun)
+		}
+		if len(candidate) > slackMaxSectionLength {
+			return section(text + "\n" + moreLine(len(lines)-i, noun))
+		}
+		text += "\n" + line
+	}
+	return section(text)
+}
+
+// unknownCurrencies lists the counts of the records skipped because of their currency sorted by the currency
+func unknownCurrencies(counts map[string]int) string {
+	if len(counts) == 0 {
+		return ""
+	}
+	currencies := make([]string, 0, len(counts))
+	for c := range counts {
+		currencies = append(currencies, c)
+	}
+	slices.Sort(currencies)
+	parts := make([]string, 0, len(currencies))
+	for _, c := range currencies {
+		parts = append(parts, fmt.Sprintf("%s %d", c, counts[c]))
+	}
+	return ", unknown currencies: " + strings.Join(parts, ", ")
+}
+
+func moreLine(n int, noun string) string {
+	return fmt.Sprintf("…and %d more %s", n, noun)
+}
+
+// header renders the title as a header block, the title exceeding the length limit of the header is cut with "…"
+func header(title string) SlackBlock {
+	if runes := []rune(title); len(runes) > slackMaxHeaderLength {
+		title = string(runes[:slackMaxHeaderLength-1]) + "…"
+	}
+	return SlackBlock{Type: "header", Text: &SlackText{Type: "plain_text", Text: title}}
+}
+
+func section(text string) SlackBlock {
+	return SlackBlock{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: text}}
+}
+
+// fixed rounds the number of the summary for humans, the number is printed as is if it could not be parsed
+func fixed(n json.Number) string {
+	d, err := decimal.NewFromString(n.String())
+	if err != nil {
+		return n.String()
+	}
+	return d.StringFixed(slackRound)
+}
+
+func percent(n json.Number) string {
+	d, err := decimal.NewFromString(n.String())
+	if err != nil {
+		return n.String()
+	}
+	return d.Mul(decimal.NewFromInt(100)).StringFixed(1)
+}
@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/shopspring/decimal"
+)
+
+// Summary is the short version of the report posted to the webhook
+type Summary struct {
+	Model            string                      `json:"model"`
+	Aggregation      string                      `json:"aggregation,omitempty"`
+	Source           string                      `json:"source,omitempty"`
+	GeneratedAt      time.Time                   `json:"generatedAt"`
+	PredictionLength int64                       `json:"predictionLength"`
+	Keys             int                         `json:"keys"`
+	Users            int64                       `json:"users"`
+	TopPredictions   []Prediction                `json:"topPredictions"`
+	Failures         []outputPrinter.JSONFailure `json:"failures,omitempty"`
+	DataQuality      DataQuality                 `json:"dataQuality"`
+	Alerts           []outputPrinter.JSONAlert   `json:"alerts,omitempty"`
+}
+
+type Prediction struct {
+	Key       string      `json:"key"`
+	Users     int64       `json:"users"`
+	LTV7      json.Number `json:"ltv7"`
+	Predicted json.Number `json:"predicted"`
+}
+
+// DataQuality contains the counts of the data which was not predicted
+type DataQuality struct {
+	Records         int `json:"records"`
+	FilteredRecords int `json:"filteredRecords"`
+	FilteredKeys    int `json:"filteredKeys"`
+	FailedKeys      int `json:"failedKeys"`
+	// UnknownCurrencies is the number of records skipped because their currency has no rate, by the currency
+	UnknownCurrencies map[string]int `json:"unknownCurrencies,omitempty"`
+}
+
+// NewSummary creates the summary with top highest predictions of the report
+func NewSummary(report outputPrinter.Report, top int) Summary {
+	summary := Summary{
+		Model:            report.Model,
+		Aggregation:      report.Aggregation,
+		Source:           report.Source,
+		GeneratedAt:      report.GeneratedAt,
+		PredictionLength: report.PredictionLength,
+		Keys:             len(report.Predictions) + len(report.Failures),
+		TopPredictions:   make([]Prediction, 0, min(top, len(report.Predictions))),
+		DataQuality: DataQuality{
+			Records:           report.Quality.Records,
+			FilteredRecords:   report.Quality.FilteredRecords,
+			FilteredKeys:      report.Quality.FilteredKeys,
+			FailedKeys:        len(report.Failures),
+			UnknownCurrencies: report.Quality.UnknownCurrencies,
+		},
+	}
+	for _, revenues := range report.Revenues {
+		summary.Users += revenues.UsersCount
+	}
+
+	keys := report.Keys()
+	// the order of the keys is kept for equal predictions
+	slices.SortStableFunc(keys, func(a, b string) int {
+		return report.Predictions[b].Cmp(report.Predictions[a])
+	})
+	for _, k := range keys[:min(top, len(keys))] {
+		p := Prediction{Key: k, Predicted: json.Number(report.Predictions[k].String()), LTV7: json.Number(decimal.Zero.String())}
+		if revenues, ok := report.Revenues[k]; ok {
+			p.Users = revenues.UsersCount
+		}
+		if ltvs := report.LTVs[k]; len(ltvs) > 0 {
+			p.LTV7 = json.Number(ltvs[len(ltvs)-1].String())
+		}
+		summary.TopPredictions = append(summary.TopPredictions, p)
+	}
+	for _, k := range report.FailedKeys() {
+		f := outputPrinter.JSONFailure{Key: k, Error: report.Failures[k].Error()}
+		if revenues, ok := report.Revenues[k]; ok {
+			f.Users = revenues.UsersCount
+		}
+		summary.Failures = append(summary.Failures, f)
+	}
+	for _, a := range report.Alerts {
+		summary.Alerts = append(summary.Alerts, outputPrinter.JSONAlert{
+			Key:       a.Key,
+			Metric:    a.Metric,
+			Value:     json.Number(a.Value.String()),
+			Average:   json.Number(a.Average.String()),
+			Deviation: json.Number(a.Deviation.String()),
+			ZScore:    json.Number(a.ZScore.String()),
+			Runs:      a.Runs,
+		})
+	}
+	return summary
+}
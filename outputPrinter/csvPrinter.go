@@ -0,0 +1,32 @@
+package outputPrinter
+
+import (
+	"encoding/csv"
+	"io"
+	"slices"
+)
+
+type CSVPrinter struct {
+	Options TableOptions
+}
+
+func (p CSVPrinter) Print(w io.Writer, data Report) error {
+	csvWriter := csv.NewWriter(w)
+	columns := tableColumns(p.Options.Columns, data)
+	// CSV has no room for the alerts section of the other outputs, so the alerts are printed in a column
+	if len(data.Alerts) > 0 && !slices.Contains(columns, ColumnAlert) {
+		columns = append(slices.Clip(columns), ColumnAlert)
+	}
+	err := csvWriter.Write(headers(columns))
+	if err != nil {
+		return err
+	}
+	for _, rw := range append(buildRows(data, p.Options.SortBy), failedRows(data)...) {
+		err = csvWriter.Write(rw.values(columns, p.Options.Round))
+		if err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
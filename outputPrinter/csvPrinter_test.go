@@ -0,0 +1,59 @@
+package outputPrinter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVPrinter_Print(t *testing.T) {
+	printer := CSVPrinter{Options: TableOptions{
+		Columns: []Column{ColumnKey, ColumnUsers, ColumnPredicted},
+		SortBy:  SortByPredicted,
+		Round:   2,
+	}}
+	var buf bytes.Buffer
+
+	err := printer.Print(&buf, createTestReport())
+
+	assert.NoError(t, err)
+	expected := "Key,Users,Predicted LTV\n" +
+		"DE,5,20.00\n" +
+		"US,10,12.35\n" +
+		"TR,20,3.00\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestCSVPrinter_Print_Failures(t *testing.T) {
+	printer := CSVPrinter{Options: TableOptions{Columns: DefaultColumns, SortBy: SortByKey, Round: 2}}
+	var buf bytes.Buffer
+
+	err := printer.Print(&buf, createTestReportWithFailures())
+
+	assert.NoError(t, err)
+	expected := "Key,Predicted LTV,Error\n" +
+		"DE,20.00,\n" +
+		"TR,3.00,\n" +
+		"US,12.35,\n" +
+		"FR,,not enough data to make prediction\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestCSVPrinter_Print_Alerts(t *testing.T) {
+	printer := CSVPrinter{Options: TableOptions{Columns: DefaultColumns, SortBy: SortByKey, Round: 2}}
+	report := createTestReportWithAlerts()
+	report.Alerts = append(report.Alerts, Alert{Key: "DE", Metric: AlertMetricLTV7, Value: decimal.NewFromInt(9),
+		Average: decimal.NewFromInt(6), Deviation: decimal.NewFromFloat(0.5), Runs: 7})
+	var buf bytes.Buffer
+
+	err := printer.Print(&buf, report)
+
+	assert.NoError(t, err)
+	expected := "Key,Predicted LTV,Alert\n" +
+		"DE,20.00,predicted 20.00 deviates -50.0% (z-score -4.2) from the average 40.00 of 7 runs; ltv7 9.00 deviates 50.0% from the average 6.00 of 7 runs\n" +
+		"TR,3.00,\n" +
+		"US,12.35,\n"
+	assert.Equal(t, expected, buf.String())
+}
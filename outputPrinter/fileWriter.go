@@ -0,0 +1,66 @@
+package outputPrinter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var (
+	ErrOutputError       = errors.New("output error: %w")
+	ErrCantCreateTmpFile = errors.New("can't create temporary file in %s: %w")
+)
+
+// WriteFileAtomic writes the output into a temporary file next to the destination and renames
+// it into place only after everything was written successfully, so readers of the destination
+// never see a partially written file
+func WriteFileAtomic(path string, write func(w io.Writer) error) (err error) {
+	dir := filepath.Dir(path)
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf(ErrCantCreateTmpFile.Error(), dir, err)
+	}
+	defer func() {
+		if err != nil {
+			tmpFile.Close()
+			os.Remove(tmpFile.Name())
+		}
+	}()
+
+	err = write(tmpFile)
+	if err != nil {
+		return err
+	}
+	// temporary files are created with 0600 permissions, output files should be readable by other jobs
+	err = tmpFile.Chmod(0o644)
+	if err != nil {
+		return err
+	}
+	err = tmpFile.Sync()
+	if err != nil {
+		return err
+	}
+	err = tmpFile.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), path)
+}
+
+// Print writes the report to stdout when the path is empty and to the file at the path otherwise
+func Print(printer OutputPrinter, path string, data Report) error {
+	var err error
+	if path == "" {
+		err = printer.Print(os.Stdout, data)
+	} else {
+		err = WriteFileAtomic(path, func(w io.Writer) error {
+			return printer.Print(w, data)
+		})
+	}
+	if err != nil {
+		return fmt.Errorf(ErrOutputError.Error(), err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package outputPrinter
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("old"), 0o644))
+
+	err := WriteFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write([]byte("new"))
+		return err
+	})
+
+	assert.NoError(t, err)
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteFileAtomic_WriteError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.csv")
+	assert.NoError(t, os.WriteFile(path, []byte("old"), 0o644))
+
+	err := WriteFileAtomic(path, func(w io.Writer) error {
+		_, _ = w.Write([]byte("partial"))
+		return errors.New("disk is full")
+	})
+
+	assert.EqualError(t, err, "disk is full")
+	// the destination is left untouched and the temporary file is removed
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "old", string(content))
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteFileAtomic_InvalidDirectory(t *testing.T) {
+	err := WriteFileAtomic("invalid/dir/report.csv", func(w io.Writer) error { return nil })
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.ErrorContains(t, err, "can't create temporary file in invalid/dir: ")
+}
+
+func TestPrint_ToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.md")
+
+	err := Print(ConsolePrinter{Round: DefaultRound}, path, createTestReport())
+
+	assert.NoError(t, err)
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "DE: 20\nTR: 3\nUS: 12.35\n", string(content))
+}
+
+func TestPrint_Error(t *testing.T) {
+	err := Print(ConsolePrinter{Round: DefaultRound}, "invalid/dir/report.md", createTestReport())
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.ErrorContains(t, err, "output error: can't create temporary file in invalid/dir: ")
+}
@@ -0,0 +1,152 @@
+package outputPrinter
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Chart dimensions in pixels
+const (
+	chartWidth   = 320
+	chartHeight  = 180
+	chartPadding = 24
+)
+
+//go:embed templates/report.html
+var htmlReportTemplate string
+
+var htmlTemplate = template.Must(template.New("report").Parse(htmlReportTemplate))
+
+// HTMLPrinter renders a self-contained HTML page with a sortable table of predictions and
+// an SVG chart of the observed and predicted LTV curve for every key
+type HTMLPrinter struct {
+	Round int32
+}
+
+type htmlReport struct {
+	Model            string
+	PredictionLength int64
+	TotalUsers       int64
+	Rows             []htmlRow
+	Failures         []htmlFailure
+	Alerts           []Alert
+	// HasROAS adds the ROAS columns to the table
+	HasROAS bool
+}
+
+type htmlFailure struct {
+	Key   string
+	Users int64
+	Error string
+}
+
+type htmlRow struct {
+	Key       string
+	Users     int64
+	FirstLTV  string
+	LastLTV   string
+	Predicted string
+	Uplift    string
+	// CPI, ROAS, Profit and Payback are empty if the key is not in the spend file
+	CPI, ROAS, Profit, Payback string
+	Chart                      svgChart
+}
+
+type svgChart struct {
+	Width, Height            int
+	Left, Right, Top, Bottom int
+	LastDay                  int64
+	MinLabel, MaxLabel       string
+	Observed                 string
+	Predicted                string
+	Points                   []svgPoint
+}
+
+type svgPoint struct {
+	X, Y string
+}
+
+func (p HTMLPrinter) Print(w io.Writer, data Report) error {
+	report := htmlReport{Model: data.Model, PredictionLength: data.PredictionLength, Alerts: data.Alerts, HasROAS: len(data.ROAS) > 0}
+	for _, rw := range buildRows(data, SortByKey) {
+		ltvs := data.LTVs[rw.key]
+		first := decimal.Zero
+		if len(ltvs) > 0 {
+			first = ltvs[0]
+		}
+		report.TotalUsers += rw.users
+		values := rw.values(ROASColumns, p.Round)
+		report.Rows = append(report.Rows, htmlRow{
+			Key:       rw.key,
+			Users:     rw.users,
+			FirstLTV:  first.StringFixed(p.Round),
+			LastLTV:   rw.ltv7.StringFixed(p.Round),
+			Predicted: rw.predicted.StringFixed(p.Round),
+			Uplift:    rw.uplift.StringFixed(p.Round),
+			CPI:       values[0],
+			ROAS:      values[1],
+			Profit:    values[2],
+			Payback:   values[3],
+			Chart:     newSVGChart(ltvs, rw.predicted, data.PredictionLength, p.Round),
+		})
+	}
+	for _, rw := range failedRows(data) {
+		report.Failures = append(report.Failures, htmlFailure{Key: rw.key, Users: rw.users, Error: rw.failure})
+	}
+	return htmlTemplate.Execute(w, report)
+}
+
+// newSVGChart scales the observed LTVs and the predicted value into the chart coordinates,
+// days are placed on the x axis starting from the first day and values on the y axis
+func newSVGChart(ltvs []decimal.Decimal, predicted decimal.Decimal, predictionLength int64, round int32) svgChart {
+	chart := svgChart{
+		Width:   chartWidth,
+		Height:  chartHeight,
+		Left:    chartPadding,
+		Right:   chartWidth - chartPadding/2,
+		Top:     chartPadding / 2,
+		Bottom:  chartHeight - chartPadding,
+		LastDay: max(predictionLength, int64(len(ltvs))),
+	}
+	minValue, maxValue := decimal.Min(decimal.Zero, predicted), predicted
+	for _, v := range ltvs {
+		minValue = decimal.Min(minValue, v)
+		maxValue = decimal.Max(maxValue, v)
+	}
+	if maxValue.Equal(minValue) {
+		maxValue = minValue.Add(decimal.NewFromInt(1))
+	}
+	chart.MinLabel = minValue.StringFixed(round)
+	chart.MaxLabel = maxValue.StringFixed(round)
+
+	minFloat, _ := minValue.Float64()
+	maxFloat, _ := maxValue.Float64()
+	point := func(day int64, value decimal.Decimal) svgPoint {
+		v, _ := value.Float64()
+		x := float64(chart.Left)
+		if chart.LastDay > 1 {
+			x += float64(chart.Right-chart.Left) * float64(day-1) / float64(chart.LastDay-1)
+		}
+		y := float64(chart.Bottom) - float64(chart.Bottom-chart.Top)*(v-minFloat)/(maxFloat-minFloat)
+		return svgPoint{X: fmt.Sprintf("%.1f", x), Y: fmt.Sprintf("%.1f", y)}
+	}
+
+	observed := make([]string, 0, len(ltvs))
+	for i, v := range ltvs {
+		pt := point(int64(i+1), v)
+		chart.Points = append(chart.Points, pt)
+		observed = append(observed, pt.X+","+pt.Y)
+	}
+	chart.Observed = strings.Join(observed, " ")
+	if len(ltvs) > 0 {
+		last := point(int64(len(ltvs)), ltvs[len(ltvs)-1])
+		end := point(predictionLength, predicted)
+		chart.Predicted = last.X + "," + last.Y + " " + end.X + "," + end.Y
+	}
+	return chart
+}
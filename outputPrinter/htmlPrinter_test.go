@@ -0,0 +1,64 @@
+package outputPrinter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLPrinter_Print(t *testing.T) {
+	printer := HTMLPrinter{Round: 2}
+	var buf bytes.Buffer
+
+	err := printer.Print(&buf, createTestReport())
+
+	assert.NoError(t, err)
+	html := buf.String()
+	assert.Contains(t, html, "<td>US</td><td class=\"num\">10</td><td class=\"num\">1.00</td><td class=\"num\">4.00</td><td class=\"num\">12.35</td>")
+	assert.Contains(t, html, "<dt>Users</dt><dd>35</dd>")
+	assert.Contains(t, html, "aria-label=\"LTV curve of DE\"")
+	// the report should not depend on any external resources
+	assert.NotContains(t, html, "src=")
+	assert.NotContains(t, html, "href=")
+}
+
+func TestHTMLPrinter_Print_Alerts(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := HTMLPrinter{Round: 2}.Print(&buf, createTestReportWithAlerts())
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "<h2>Alerts</h2>")
+	assert.Contains(t, buf.String(), "<td>DE</td><td>predicted</td><td class=\"num\">20.00</td><td class=\"num\">40.00</td><td class=\"num\">-0.500</td><td class=\"num\">-4.2</td><td class=\"num\">7</td>")
+}
+
+func TestHTMLPrinter_Print_ROAS(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := HTMLPrinter{Round: 2}.Print(&buf, createTestReportWithROAS())
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "<th data-type=\"num\">ROAS</th>")
+	assert.Contains(t, buf.String(), "<td class=\"num\">5.00</td><td class=\"num\">2.47</td><td class=\"num\">73.45</td><td class=\"num\">9</td></tr>")
+
+	buf.Reset()
+	err = HTMLPrinter{Round: 2}.Print(&buf, createTestReport())
+
+	assert.NoError(t, err)
+	assert.NotContains(t, buf.String(), "ROAS")
+}
+
+func TestNewSVGChart(t *testing.T) {
+	ltvs := []decimal.Decimal{decimal.NewFromInt(0), decimal.NewFromInt(5)}
+
+	chart := newSVGChart(ltvs, decimal.NewFromInt(10), 3, 0)
+
+	assert.Equal(t, int64(3), chart.LastDay)
+	assert.Equal(t, "0", chart.MinLabel)
+	assert.Equal(t, "10", chart.MaxLabel)
+	assert.Equal(t, "24.0,156.0 166.0,84.0", chart.Observed)
+	assert.Equal(t, "166.0,84.0 308.0,12.0", chart.Predicted)
+	assert.Len(t, chart.Points, 2)
+}
@@ -0,0 +1,148 @@
+package outputPrinter
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// JSONPrinter prints the report as a JSON document, numbers are printed with full precision
+type JSONPrinter struct{}
+
+type JSONReport struct {
+	Model            string           `json:"model"`
+	Aggregation      string           `json:"aggregation,omitempty"`
+	Source           string           `json:"source,omitempty"`
+	GeneratedAt      time.Time        `json:"generatedAt"`
+	PredictionLength int64            `json:"predictionLength"`
+	Predictions      []JSONPrediction `json:"predictions"`
+	Failures         []JSONFailure    `json:"failures,omitempty"`
+	Alerts           []JSONAlert      `json:"alerts,omitempty"`
+}
+
+type JSONPrediction struct {
+	Key       string        `json:"key"`
+	Users     int64         `json:"users"`
+	LTVs      []json.Number `json:"ltvs"`
+	Predicted json.Number   `json:"predicted"`
+	ROAS      *JSONROAS     `json:"roas,omitempty"`
+	Streams   []JSONStream  `json:"streams,omitempty"`
+}
+
+// JSONStream contains the prediction of a revenue stream of a key, Predicted is omitted if it failed
+type JSONStream struct {
+	Stream    string        `json:"stream"`
+	Model     string        `json:"model"`
+	LTVs      []json.Number `json:"ltvs,omitempty"`
+	Predicted json.Number   `json:"predicted,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// JSONROAS contains the returns on the spend of a key, see roas.Metrics
+type JSONROAS struct {
+	Spend    json.Number `json:"spend"`
+	Installs int64       `json:"installs"`
+	CPI      json.Number `json:"cpi"`
+	ROAS     json.Number `json:"roas"`
+	Profit   json.Number `json:"profit"`
+	// PaybackDay is omitted if the spend is not paid back within the horizon
+	PaybackDay int64 `json:"paybackDay,omitempty"`
+}
+
+// JSONFailure contains the reason the prediction of a key failed
+type JSONFailure struct {
+	Key   string `json:"key"`
+	Users int64  `json:"users"`
+	Error string `json:"error"`
+}
+
+// JSONAlert contains a value which deviates from the previous runs, see Alert
+type JSONAlert struct {
+	Key       string      `json:"key"`
+	Metric    string      `json:"metric"`
+	Value     json.Number `json:"value"`
+	Average   json.Number `json:"average"`
+	Deviation json.Number `json:"deviation"`
+	ZScore    json.Number `json:"zScore"`
+	Runs      int         `json:"runs"`
+}
+
+func (p JSONPrinter) Print(w io.Writer, data Report) error {
+	report := JSONReport{
+		Model:            data.Model,
+		Aggregation:      data.Aggregation,
+		Source:           data.Source,
+		GeneratedAt:      data.GeneratedAt,
+		PredictionLength: data.PredictionLength,
+		Predictions:      make([]JSONPrediction, 0, len(data.Predictions)),
+	}
+	for _, rw := range buildRows(data, SortByKey) {
+		ltvs := make([]json.Number, 0, len(data.LTVs[rw.key]))
+		for _, v := range data.LTVs[rw.key] {
+			ltvs = append(ltvs, jsonNumber(v))
+		}
+		prediction := JSONPrediction{
+			Key:       rw.key,
+			Users:     rw.users,
+			LTVs:      ltvs,
+			Predicted: jsonNumber(rw.predicted),
+		}
+		if m := rw.spend; m != nil {
+			prediction.ROAS = &JSONROAS{
+				Spend:      jsonNumber(m.Spend),
+				Installs:   m.Installs,
+				CPI:        jsonNumber(m.CPI),
+				ROAS:       jsonNumber(m.ROAS),
+				Profit:     jsonNumber(m.Profit),
+				PaybackDay: m.PaybackDay,
+			}
+		}
+		prediction.Streams = jsonStreams(data, rw.key)
+		report.Predictions = append(report.Predictions, prediction)
+	}
+	for _, rw := range failedRows(data) {
+		report.Failures = append(report.Failures, JSONFailure{Key: rw.key, Users: rw.users, Error: rw.failure})
+	}
+	for _, a := range data.Alerts {
+		report.Alerts = append(report.Alerts, JSONAlert{
+			Key:       a.Key,
+			Metric:    a.Metric,
+			Value:     jsonNumber(a.Value),
+			Average:   jsonNumber(a.Average),
+			Deviation: jsonNumber(a.Deviation),
+			ZScore:    jsonNumber(a.ZScore),
+			Runs:      a.Runs,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// jsonStreams returns the predictions of the streams of the key sorted by name, streams without the revenues
+// of the key are skipped
+func jsonStreams(data Report, key string) []JSONStream {
+	var streams []JSONStream
+	for _, name := range data.StreamNames() {
+		stream := data.Streams[name]
+		result := JSONStream{Stream: name, Model: stream.Model}
+		if err, ok := stream.Failures[key]; ok {
+			result.Error = err.Error()
+		} else if predicted, ok := stream.Predictions[key]; ok {
+			result.Predicted = jsonNumber(predicted)
+		} else {
+			continue
+		}
+		for _, v := range stream.LTVs[key] {
+			result.LTVs = append(result.LTVs, jsonNumber(v))
+		}
+		streams = append(streams, result)
+	}
+	return streams
+}
+
+func jsonNumber(d decimal.Decimal) json.Number {
+	return json.Number(d.String())
+}
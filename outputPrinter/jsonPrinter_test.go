@@ -0,0 +1,89 @@
+package outputPrinter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONPrinter_Print(t *testing.T) {
+	report := createTestReport()
+	report.Aggregation = "country"
+	report.GeneratedAt = time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+
+	err := JSONPrinter{}.Print(&buf, report)
+
+	assert.NoError(t, err)
+	var result JSONReport
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, "linearRegression", result.Model)
+	assert.Equal(t, "country", result.Aggregation)
+	assert.Equal(t, int64(60), result.PredictionLength)
+	assert.Equal(t, report.GeneratedAt, result.GeneratedAt)
+	assert.Equal(t, []JSONPrediction{
+		{Key: "DE", Users: 5, LTVs: []json.Number{"2", "5"}, Predicted: "20"},
+		{Key: "TR", Users: 20, LTVs: []json.Number{"1", "0"}, Predicted: "3"},
+		{Key: "US", Users: 10, LTVs: []json.Number{"1", "4"}, Predicted: "12.345"},
+	}, result.Predictions)
+	assert.Contains(t, buf.String(), `"predicted": 12.345`)
+}
+
+func TestJSONPrinter_Print_Failures(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := JSONPrinter{}.Print(&buf, createTestReportWithFailures())
+
+	assert.NoError(t, err)
+	var result JSONReport
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Len(t, result.Predictions, 3)
+	assert.Equal(t, []JSONFailure{{Key: "FR", Users: 3, Error: "not enough data to make prediction"}}, result.Failures)
+}
+
+func TestJSONPrinter_Print_Alerts(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := JSONPrinter{}.Print(&buf, createTestReportWithAlerts())
+
+	assert.NoError(t, err)
+	var result JSONReport
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, []JSONAlert{{Key: "DE", Metric: "predicted", Value: "20", Average: "40", Deviation: "-0.5", ZScore: "-4.2", Runs: 7}}, result.Alerts)
+}
+
+func TestJSONPrinter_Print_ROAS(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := JSONPrinter{}.Print(&buf, createTestReportWithROAS())
+
+	assert.NoError(t, err)
+	var result JSONReport
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, &JSONROAS{Spend: "250", Installs: 10, CPI: "25", ROAS: "0.8", Profit: "-50"}, result.Predictions[0].ROAS)
+	assert.Nil(t, result.Predictions[1].ROAS)
+	assert.Equal(t, &JSONROAS{Spend: "50", Installs: 10, CPI: "5", ROAS: "2.469", Profit: "73.45", PaybackDay: 9}, result.Predictions[2].ROAS)
+	assert.NotContains(t, buf.String(), "\"paybackDay\": 0")
+}
+
+func TestJSONPrinter_Print_Streams(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := JSONPrinter{}.Print(&buf, createTestReportWithStreams())
+
+	assert.NoError(t, err)
+	var result JSONReport
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, []JSONStream{
+		{Stream: "ads", Model: "linearExtrapolation", Predicted: "5"},
+		{Stream: "iap", Model: "linearRegression", LTVs: []json.Number{"2", "3"}, Predicted: "15"},
+	}, result.Predictions[0].Streams)
+	assert.Nil(t, result.Predictions[1].Streams)
+	assert.Equal(t, []JSONStream{
+		{Stream: "ads", Model: "linearExtrapolation", Error: "not enough data to make prediction"},
+		{Stream: "iap", Model: "linearRegression", Predicted: "8.5"},
+	}, result.Predictions[2].Streams)
+}
@@ -0,0 +1,56 @@
+package outputPrinter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type MarkdownPrinter struct {
+	Options TableOptions
+}
+
+func (p MarkdownPrinter) Print(w io.Writer, data Report) error {
+	columns := tableColumns(p.Options.Columns, data)
+	separators := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if isNumeric(c) {
+			separators = append(separators, "---:")
+		} else {
+			separators = append(separators, "---")
+		}
+	}
+	lines := [][]string{headers(columns), separators}
+	for _, rw := range append(buildRows(data, p.Options.SortBy), failedRows(data)...) {
+		lines = append(lines, rw.values(columns, p.Options.Round))
+	}
+	for _, line := range lines {
+		_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(escapeMarkdown(line), " | "))
+		if err != nil {
+			return err
+		}
+	}
+	if len(data.Alerts) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprint(w, "\n**Alerts**\n\n")
+	if err != nil {
+		return err
+	}
+	for _, a := range data.Alerts {
+		_, err = fmt.Fprintf(w, "- `%s` %s\n", a.Key, a)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeMarkdown escapes pipe characters which would otherwise break the table layout
+func escapeMarkdown(values []string) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = strings.ReplaceAll(v, "|", `\|`)
+	}
+	return result
+}
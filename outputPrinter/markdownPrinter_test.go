@@ -0,0 +1,50 @@
+package outputPrinter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownPrinter_Print(t *testing.T) {
+	printer := MarkdownPrinter{Options: TableOptions{
+		Columns: []Column{ColumnKey, ColumnLTV7, ColumnPredicted},
+		SortBy:  SortByKey,
+		Round:   0,
+	}}
+	var buf bytes.Buffer
+
+	err := printer.Print(&buf, createTestReport())
+
+	assert.NoError(t, err)
+	expected := "| Key | LTV day 7 | Predicted LTV |\n" +
+		"| --- | ---: | ---: |\n" +
+		"| DE | 5 | 20 |\n" +
+		"| TR | 0 | 3 |\n" +
+		"| US | 4 | 12 |\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestMarkdownPrinter_Print_EscapesPipes(t *testing.T) {
+	printer := MarkdownPrinter{Options: TableOptions{Columns: []Column{ColumnKey}, SortBy: SortByKey}}
+	report := Report{Predictions: predictor.PredictedLTVs{"a|b": decimal.NewFromInt(1)}}
+	var buf bytes.Buffer
+
+	err := printer.Print(&buf, report)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "| Key |\n| --- |\n| a\\|b |\n", buf.String())
+}
+
+func TestMarkdownPrinter_Print_Alerts(t *testing.T) {
+	printer := MarkdownPrinter{Options: TableOptions{Columns: []Column{ColumnKey}, SortBy: SortByKey}}
+	var buf bytes.Buffer
+
+	err := printer.Print(&buf, createTestReportWithAlerts())
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "| US |\n\n**Alerts**\n\n- `DE` predicted 20.00 deviates -50.0% (z-score -4.2) from the average 40.00 of 7 runs\n")
+}
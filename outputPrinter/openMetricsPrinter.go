@@ -0,0 +1,114 @@
+package outputPrinter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// OpenMetricsPrinter prints predictions in the OpenMetrics text format, the output could be
+// written into a file for the node_exporter textfile collector or served over HTTP with MetricsHandler
+type OpenMetricsPrinter struct{}
+
+func (p OpenMetricsPrinter) Print(w io.Writer, data Report) error {
+	bw := bufio.NewWriter(w)
+	rows := buildRows(data, SortByKey)
+	horizon := strconv.FormatInt(data.PredictionLength, 10)
+
+	writeMetricFamily(bw, "ltv_predicted", "Predicted LTV at the end of the prediction horizon.")
+	for _, rw := range rows {
+		fmt.Fprintf(bw, "ltv_predicted{key=\"%s\",model=\"%s\",horizon=\"%s\"} %s\n",
+			escapeLabelValue(rw.key), escapeLabelValue(data.Model), horizon, rw.predicted.String())
+	}
+	writeMetricFamily(bw, "ltv_users", "Number of users the prediction is based on.")
+	for _, rw := range rows {
+		fmt.Fprintf(bw, "ltv_users{key=\"%s\"} %d\n", escapeLabelValue(rw.key), rw.users)
+	}
+	writeMetricFamily(bw, "ltv_observed", "Last observed LTV.")
+	for _, rw := range rows {
+		fmt.Fprintf(bw, "ltv_observed{key=\"%s\",day=\"%d\"} %s\n", escapeLabelValue(rw.key), len(data.LTVs[rw.key]), rw.ltv7.String())
+	}
+	if len(data.ROAS) > 0 {
+		writeMetricFamily(bw, "ltv_roas", "Predicted return on ad spend at the end of the prediction horizon.")
+		for _, rw := range rows {
+			if rw.spend != nil {
+				fmt.Fprintf(bw, "ltv_roas{key=\"%s\",horizon=\"%s\"} %s\n", escapeLabelValue(rw.key), horizon, rw.spend.ROAS.String())
+			}
+		}
+		writeMetricFamily(bw, "ltv_cpi", "Cost per install of the spend file.")
+		for _, rw := range rows {
+			if rw.spend != nil {
+				fmt.Fprintf(bw, "ltv_cpi{key=\"%s\"} %s\n", escapeLabelValue(rw.key), rw.spend.CPI.String())
+			}
+		}
+	}
+	if len(data.Streams) > 0 {
+		writeMetricFamily(bw, "ltv_stream_predicted", "Predicted LTV of the revenue stream at the end of the prediction horizon.")
+		for _, rw := range rows {
+			for _, name := range data.StreamNames() {
+				if v, ok := rw.streams[name]; ok {
+					fmt.Fprintf(bw, "ltv_stream_predicted{key=\"%s\",stream=\"%s\",model=\"%s\",horizon=\"%s\"} %s\n",
+						escapeLabelValue(rw.key), escapeLabelValue(name), escapeLabelValue(data.Streams[name].Model), horizon, v.String())
+				}
+			}
+		}
+	}
+	if len(data.Failures) > 0 {
+		writeMetricFamily(bw, "ltv_prediction_failed", "Set for keys which could not be predicted.")
+		for _, rw := range failedRows(data) {
+			fmt.Fprintf(bw, "ltv_prediction_failed{key=\"%s\",reason=\"%s\"} 1\n", escapeLabelValue(rw.key), escapeLabelValue(rw.failure))
+		}
+	}
+	if len(data.Alerts) > 0 {
+		writeMetricFamily(bw, "ltv_alert_deviation", "Relative deviation from the trailing average, set for values which raised an alert.")
+		for _, a := range data.Alerts {
+			fmt.Fprintf(bw, "ltv_alert_deviation{key=\"%s\",metric=\"%s\"} %s\n", escapeLabelValue(a.Key), a.Metric, a.Deviation.String())
+		}
+	}
+	fmt.Fprintln(bw, "# EOF")
+	// bufio.Writer keeps the first error, so it is enough to check it once on flush
+	return bw.Flush()
+}
+
+func writeMetricFamily(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# TYPE %s gauge\n# HELP %s %s\n", name, name, help)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueReplacer.Replace(s)
+}
+
+// MetricsHandler serves the latest report in the OpenMetrics format over HTTP
+type MetricsHandler struct {
+	mu   sync.RWMutex
+	data Report
+}
+
+// Update replaces the report served by the handler
+func (h *MetricsHandler) Update(data Report) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.data = data
+}
+
+func (h *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var buf bytes.Buffer
+	err := OpenMetricsPrinter{}.Print(&buf, h.data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", OpenMetricsContentType)
+	_, _ = buf.WriteTo(w)
+}
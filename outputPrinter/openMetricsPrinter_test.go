@@ -0,0 +1,99 @@
+package outputPrinter
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenMetricsPrinter_Print(t *testing.T) {
+	report := createTestReport()
+	delete(report.Predictions, "TR")
+	var buf bytes.Buffer
+
+	err := OpenMetricsPrinter{}.Print(&buf, report)
+
+	assert.NoError(t, err)
+	expected := `# TYPE ltv_predicted gauge
+# HELP ltv_predicted Predicted LTV at the end of the prediction horizon.
+ltv_predicted{key="DE",model="linearRegression",horizon="60"} 20
+ltv_predicted{key="US",model="linearRegression",horizon="60"} 12.345
+# TYPE ltv_users gauge
+# HELP ltv_users Number of users the prediction is based on.
+ltv_users{key="DE"} 5
+ltv_users{key="US"} 10
+# TYPE ltv_observed gauge
+# HELP ltv_observed Last observed LTV.
+ltv_observed{key="DE",day="2"} 5
+ltv_observed{key="US",day="2"} 4
+# EOF
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestOpenMetricsPrinter_Print_Alerts(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := OpenMetricsPrinter{}.Print(&buf, createTestReportWithAlerts())
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "# TYPE ltv_alert_deviation gauge\n")
+	assert.Contains(t, buf.String(), "ltv_alert_deviation{key=\"DE\",metric=\"predicted\"} -0.5\n# EOF\n")
+}
+
+func TestOpenMetricsPrinter_Print_ROAS(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := OpenMetricsPrinter{}.Print(&buf, createTestReportWithROAS())
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "ltv_roas{key=\"DE\",horizon=\"60\"} 0.8\nltv_roas{key=\"US\",horizon=\"60\"} 2.469\n")
+	assert.Contains(t, buf.String(), "ltv_cpi{key=\"DE\"} 25\nltv_cpi{key=\"US\"} 5\n")
+	assert.NotContains(t, buf.String(), "key=\"TR\",horizon")
+}
+
+func TestOpenMetricsPrinter_Print_Streams(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := OpenMetricsPrinter{}.Print(&buf, createTestReportWithStreams())
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "# TYPE ltv_stream_predicted gauge\n")
+	assert.Contains(t, buf.String(), `ltv_stream_predicted{key="DE",stream="ads",model="linearExtrapolation",horizon="60"} 5
+ltv_stream_predicted{key="DE",stream="iap",model="linearRegression",horizon="60"} 15
+ltv_stream_predicted{key="US",stream="iap",model="linearRegression",horizon="60"} 8.5
+`)
+}
+
+func TestOpenMetricsPrinter_Print_EscapesLabels(t *testing.T) {
+	report := Report{Model: "m", Predictions: predictor.PredictedLTVs{"a\"b\\c\nd": decimal.NewFromInt(1)}}
+	var buf bytes.Buffer
+
+	err := OpenMetricsPrinter{}.Print(&buf, report)
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), `ltv_predicted{key="a\"b\\c\nd",model="m",horizon="0"} 1`)
+}
+
+func TestMetricsHandler(t *testing.T) {
+	handler := &MetricsHandler{}
+	handler.Update(createTestReport())
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, OpenMetricsContentType, resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), `ltv_users{key="TR"} 20`)
+}
@@ -2,24 +2,261 @@ package outputPrinter
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
-	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// outlierFactor defines how many times the prediction should differ from the median
+	// prediction to be highlighted in the rich mode
+	outlierFactor = 2
+	// sparklinePredictedPoints is the number of points used to draw the predicted part of the curve
+	sparklinePredictedPoints = 8
+)
+
+const (
+	ansiReset  = "\033[0m"
+	ansiRed    = "\033[31m"
+	ansiYellow = "\033[33m"
+)
+
+var sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+var (
+	outlierLabels = map[string]string{"high": "▲ high", "low": "▼ low"}
+	outlierColors = map[string]string{"high": ansiRed, "low": ansiYellow}
 )
 
 type OutputPrinter interface {
-	Print(data predictor.PredictedLTVs)
+	Print(w io.Writer, data Report) error
+}
+
+// ConsolePrinter prints predictions as plain "key: value" lines. In the rich mode it prints
+// an aligned table with a sparkline of every curve and highlights unusually high or low
+// predictions, colours are used only when the output is a terminal. Rows are ordered by SortBy
+// and the values are rounded to Round decimal places, failed keys are printed after the predictions
+type ConsolePrinter struct {
+	Rich   bool
+	SortBy SortOrder
+	Round  int32
+}
+
+func (p ConsolePrinter) Print(w io.Writer, data Report) error {
+	if p.Rich {
+		f, ok := w.(*os.File)
+		return p.writeRich(w, data, ok && isTerminal(f))
+	}
+	for _, rw := range buildRows(data, p.SortBy) {
+		k := rw.key
+		spend := ""
+		if m := rw.spend; m != nil {
+			spend = fmt.Sprintf(" (CPI %s, ROAS %s, profit %s, payback day %s)", m.CPI.StringFixed(p.Round), m.ROAS.StringFixed(p.Round),
+				m.Profit.StringFixed(p.Round), paybackDayLabel(m.PaybackDay))
+		}
+		_, err := fmt.Fprintf(w, "%s: %v%s%s\n", k, rw.predicted.Round(p.Round), spend, streamsLabel(data, k, p.Round))
+		if err != nil {
+			return err
+		}
+	}
+	for _, k := range data.FailedKeys() {
+		_, err := fmt.Fprintf(w, "%s: failed: %v\n", k, data.Failures[k])
+		if err != nil {
+			return err
+		}
+	}
+	for _, a := range data.Alerts {
+		_, err := fmt.Fprintf(w, "%s: alert: %s\n", a.Key, a)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p ConsolePrinter) writeRich(w io.Writer, data Report, colors bool) error {
+	rows := buildRows(data, p.SortBy)
+	median := medianPrediction(rows)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	hasROAS := len(data.ROAS) > 0
+	header := "KEY\tUSERS\tLTV DAY 7\tPREDICTED\t"
+	if hasROAS {
+		header += "ROAS\tPAYBACK\t"
+	}
+	_, err := fmt.Fprintln(tw, header+"OBSERVED\tCURVE")
+	if err != nil {
+		return err
+	}
+	for _, rw := range rows {
+		status := outlierStatus(rw.predicted, median)
+		label := outlierLabels[status]
+		if colors && label != "" {
+			label = outlierColors[status] + label + ansiReset
+		}
+		ltvs := data.LTVs[rw.key]
+		spend := ""
+		if hasROAS {
+			spend = strings.Join(rw.values([]Column{ColumnROAS, ColumnPayback}, p.Round), "\t") + "\t"
+		}
+		_, err = fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s%s\t%s\t%s\n", rw.key, rw.users, rw.ltv7.StringFixed(p.Round), rw.predicted.StringFixed(p.Round),
+			spend, observedSparkline(ltvs), curveSparkline(ltvs, rw.predicted, data.PredictionLength), label)
+		if err != nil {
+			return err
+		}
+	}
+	for _, rw := range failedRows(data) {
+		label := "✗ failed: " + rw.failure
+		if colors {
+			label = ansiRed + label + ansiReset
+		}
+		spend := ""
+		if hasROAS {
+			spend = "\t\t"
+		}
+		_, err = fmt.Fprintf(tw, "%s\t%d\t\t\t%s\t\t%s\n", rw.key, rw.users, spend, label)
+		if err != nil {
+			return err
+		}
+	}
+	err = tw.Flush()
+	if err != nil || len(data.Alerts) == 0 {
+		return err
+	}
+	_, err = fmt.Fprintln(w, "\nAlerts:")
+	if err != nil {
+		return err
+	}
+	for _, a := range data.Alerts {
+		label := "⚠ " + a.Key + ": " + a.String()
+		if colors {
+			label = ansiYellow + label + ansiReset
+		}
+		_, err = fmt.Fprintf(w, "  %s\n", label)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// paybackDayLabel formats the payback day for humans
+func paybackDayLabel(day int64) string {
+	if day == 0 {
+		return "-"
+	}
+	return strconv.FormatInt(day, 10)
+}
+
+// observedSparkline draws only the observed LTVs, so the shape of the curve is visible
+// even when the prediction is much higher than the observed values
+func observedSparkline(ltvs []decimal.Decimal) string {
+	if len(ltvs) == 0 {
+		return ""
+	}
+	values := make([]float64, 0, len(ltvs))
+	for _, v := range ltvs {
+		f, _ := v.Float64()
+		values = append(values, f)
+	}
+	return string(sparkline(values))
+}
+
+// curveSparkline draws the observed LTVs followed by the predicted part of the curve, which is
+// linearly interpolated between the last observed value and the prediction
+func curveSparkline(ltvs []decimal.Decimal, predicted decimal.Decimal, predictionLength int64) string {
+	if len(ltvs) == 0 {
+		return ""
+	}
+	values := make([]float64, 0, len(ltvs)+sparklinePredictedPoints)
+	for _, v := range ltvs {
+		f, _ := v.Float64()
+		values = append(values, f)
+	}
+	observed := len(values)
+	last := values[observed-1]
+	end, _ := predicted.Float64()
+	if int64(observed) < predictionLength {
+		for i := 1; i <= sparklinePredictedPoints; i++ {
+			values = append(values, last+(end-last)*float64(i)/sparklinePredictedPoints)
+		}
+	}
+	line := sparkline(values)
+	return string(line[:observed]) + "┊" + string(line[observed:])
+}
+
+func sparkline(values []float64) []rune {
+	minValue, maxValue := slices.Min(values), slices.Max(values)
+	result := make([]rune, len(values))
+	for i, v := range values {
+		idx := 0
+		if maxValue > minValue {
+			idx = int((v - minValue) / (maxValue - minValue) * float64(len(sparklineBlocks)-1))
+		}
+		result[i] = sparklineBlocks[idx]
+	}
+	return result
 }
 
-type ConsolePrinter struct{}
+func medianPrediction(rows []row) decimal.Decimal {
+	if len(rows) == 0 {
+		return decimal.Zero
+	}
+	values := make([]decimal.Decimal, 0, len(rows))
+	for _, rw := range rows {
+		values = append(values, rw.predicted)
+	}
+	slices.SortFunc(values, func(a, b decimal.Decimal) int { return a.Cmp(b) })
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return values[mid-1].Add(values[mid]).Div(decimal.NewFromInt(2))
+	}
+	return values[mid]
+}
+
+// outlierStatus returns "high" or "low" if the prediction differs from the median more
+// than outlierFactor times
+func outlierStatus(predicted, median decimal.Decimal) string {
+	if !median.IsPositive() {
+		return ""
+	}
+	factor := decimal.NewFromInt(outlierFactor)
+	if predicted.GreaterThan(median.Mul(factor)) {
+		return "high"
+	}
+	if predicted.LessThan(median.Div(factor)) {
+		return "low"
+	}
+	return ""
+}
+
+// isTerminal reports whether the file is a terminal and colours could be used,
+// NO_COLOR environment variable disables colours regardless of the terminal
+func isTerminal(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || strings.EqualFold(os.Getenv("TERM"), "dumb") {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
 
-func (p ConsolePrinter) Print(data predictor.PredictedLTVs) {
-	keys := make([]string, 0, len(data))
-	for k := range data {
-		keys = append(keys, k)
+// streamsLabel lists the predictions of the revenue streams of the key, e.g. " (ads 1.5, iap 3)", failed streams are skipped
+func streamsLabel(data Report, key string, round int32) string {
+	values := make([]string, 0, len(data.Streams))
+	for _, name := range data.StreamNames() {
+		if v, ok := data.Streams[name].Predictions[key]; ok {
+			values = append(values, fmt.Sprintf("%s %v", name, v.Round(round)))
+		}
 	}
-	slices.Sort(keys)
-	for _, k := range keys {
-		fmt.Printf("%s: %v\n", k, data[k].Round(2))
+	if len(values) == 0 {
+		return ""
 	}
+	return " (" + strings.Join(values, ", ") + ")"
 }
@@ -0,0 +1,138 @@
+package outputPrinter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsolePrinter_WriteRich(t *testing.T) {
+	report := createTestReport()
+	report.Predictions["FR"] = decimal.NewFromInt(100)
+	report.LTVs["FR"] = []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)}
+	var buf bytes.Buffer
+
+	err := ConsolePrinter{Rich: true, Round: DefaultRound}.writeRich(&buf, report, false)
+
+	assert.NoError(t, err)
+	expected := "KEY  USERS  LTV DAY 7  PREDICTED  OBSERVED  CURVE\n" +
+		"DE   5      5.00       20.00      ▁█        ▁▂┊▂▃▄▅▅▆▇█  \n" +
+		"FR   0      2.00       100.00     ▁█        ▁▁┊▁▂▃▄▅▆▇█  ▲ high\n" +
+		"TR   20     0.00       3.00       █▁        ▃▁┊▁▂▃▄▅▆▇█  ▼ low\n" +
+		"US   10     4.00       12.35      ▁█        ▁▂┊▃▄▄▅▆▆▇█  \n"
+	assert.Equal(t, expected, buf.String())
+	assert.NotContains(t, buf.String(), "\033[")
+}
+
+func TestConsolePrinter_WriteRich_Colors(t *testing.T) {
+	report := createTestReport()
+	var buf bytes.Buffer
+
+	err := ConsolePrinter{Rich: true, Round: DefaultRound}.writeRich(&buf, report, true)
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), ansiYellow+"▼ low"+ansiReset)
+}
+
+func TestConsolePrinter_Print_SortAndRound(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := ConsolePrinter{SortBy: SortByPredicted, Round: 1}.Print(&buf, createTestReport())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "DE: 20\nUS: 12.3\nTR: 3\n", buf.String())
+}
+
+func TestConsolePrinter_Print_Failures(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := ConsolePrinter{Round: DefaultRound}.Print(&buf, createTestReportWithFailures())
+
+	assert.NoError(t, err)
+	expected := "DE: 20\n" +
+		"TR: 3\n" +
+		"US: 12.35\n" +
+		"FR: failed: not enough data to make prediction\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestConsolePrinter_WriteRich_Failures(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := ConsolePrinter{Rich: true, Round: DefaultRound}.writeRich(&buf, createTestReportWithFailures(), false)
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "FR   3                                                   ✗ failed: not enough data to make prediction\n")
+}
+
+func TestConsolePrinter_Print_Alerts(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := ConsolePrinter{Round: DefaultRound}.Print(&buf, createTestReportWithAlerts())
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "DE: alert: predicted 20.00 deviates -50.0% (z-score -4.2) from the average 40.00 of 7 runs\n")
+}
+
+func TestConsolePrinter_WriteRich_Alerts(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := ConsolePrinter{Rich: true, Round: DefaultRound}.writeRich(&buf, createTestReportWithAlerts(), true)
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "\nAlerts:\n  "+ansiYellow+"⚠ DE: predicted 20.00 deviates -50.0%")
+}
+
+func TestConsolePrinter_Print_ROAS(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := ConsolePrinter{Round: DefaultRound}.Print(&buf, createTestReportWithROAS())
+
+	assert.NoError(t, err)
+	expected := "DE: 20 (CPI 25.00, ROAS 0.80, profit -50.00, payback day -)\n" +
+		"TR: 3\n" +
+		"US: 12.35 (CPI 5.00, ROAS 2.47, profit 73.45, payback day 9)\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestConsolePrinter_Print_Streams(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := ConsolePrinter{Round: DefaultRound}.Print(&buf, createTestReportWithStreams())
+
+	assert.NoError(t, err)
+	expected := "DE: 20 (ads 5, iap 15)\n" +
+		"TR: 3\n" +
+		"US: 12.35 (iap 8.5)\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestConsolePrinter_WriteRich_ROAS(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := ConsolePrinter{Rich: true, Round: DefaultRound}.writeRich(&buf, createTestReportWithROAS(), false)
+
+	assert.NoError(t, err)
+	expected := "KEY  USERS  LTV DAY 7  PREDICTED  ROAS  PAYBACK  OBSERVED  CURVE\n" +
+		"DE   5      5.00       20.00      0.80           ▁█        ▁▂┊▂▃▄▅▅▆▇█  \n" +
+		"TR   20     0.00       3.00                      █▁        ▃▁┊▁▂▃▄▅▆▇█  ▼ low\n" +
+		"US   10     4.00       12.35      2.47  9        ▁█        ▁▂┊▃▄▄▅▆▆▇█  \n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestMedianPrediction(t *testing.T) {
+	rows := []row{{predicted: decimal.NewFromInt(1)}, {predicted: decimal.NewFromInt(5)}, {predicted: decimal.NewFromInt(3)}}
+	assert.True(t, decimal.NewFromInt(3).Equal(medianPrediction(rows)))
+
+	rows = append(rows, row{predicted: decimal.NewFromInt(10)})
+	assert.True(t, decimal.NewFromInt(4).Equal(medianPrediction(rows)))
+
+	assert.True(t, decimal.Zero.Equal(medianPrediction(nil)))
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "▁▄█", string(sparkline([]float64{0, 0.5, 1})))
+	assert.Equal(t, "▁▁", string(sparkline([]float64{2, 2})))
+}
@@ -0,0 +1,116 @@
+package outputPrinter
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/pklimuk/ltv-predictor/roas"
+	"github.com/shopspring/decimal"
+)
+
+// Report contains the results of a single run together with the intermediate data
+// printers may need to render additional columns
+type Report struct {
+	Model            string
+	Aggregation      string
+	Source           string
+	GeneratedAt      time.Time
+	PredictionLength int64
+	Predictions      predictor.PredictedLTVs
+	Revenues         aggregator.AggregatedRevenuesByKey
+	LTVs             aggregator.AggregatedLTVsByKey
+	// Failures contains the keys which could not be predicted in the keep going mode
+	Failures predictor.Failures
+	// Alerts contains the values which deviate from the previous runs, sorted by key and metric
+	Alerts []Alert
+	// Quality contains the counts of the input data which was not predicted
+	Quality DataQuality
+	// ROAS contains the returns on the spend of the predicted keys found in the spend file, nil if it is not specified
+	ROAS roas.MetricsByKey
+	// Streams contains the predictions of the revenue streams by name, nil if the records have no breakdown
+	Streams map[string]StreamReport
+}
+
+// StreamReport contains the predictions of a revenue stream, e.g. iap or ads, made by the model of the stream
+type StreamReport struct {
+	Model       string
+	Predictions predictor.PredictedLTVs
+	Revenues    aggregator.AggregatedRevenuesByKey
+	LTVs        aggregator.AggregatedLTVsByKey
+	// Failures contains the keys of the stream which could not be predicted in the keep going mode
+	Failures predictor.Failures
+}
+
+// DataQuality contains the counts of the parsed data and the data excluded by the filters
+type DataQuality struct {
+	Records int
+	// FilteredRecords is the number of records of other countries and campaigns
+	FilteredRecords int
+	// FilteredKeys is the number of keys with fewer users than the minimum
+	FilteredKeys int
+	// UnknownCurrencies is the number of records skipped because their currency has no rate, by the currency
+	UnknownCurrencies map[string]int
+}
+
+// Metrics checked by alerts
+const (
+	AlertMetricPredicted = "predicted"
+	AlertMetricLTV7      = "ltv7"
+)
+
+// Alert reports a value of a key which deviates from its trailing average over the previous runs
+type Alert struct {
+	Key string
+	// Metric is AlertMetricPredicted or AlertMetricLTV7
+	Metric  string
+	Value   decimal.Decimal
+	Average decimal.Decimal
+	// Deviation is the deviation from the average relative to the average, zero if the average is zero
+	Deviation decimal.Decimal
+	// ZScore is the deviation from the average in standard deviations, zero if it could not be computed
+	ZScore decimal.Decimal
+	// Runs is the number of the previous runs the average is computed from
+	Runs int
+}
+
+func (a Alert) String() string {
+	zScore := ""
+	if !a.ZScore.IsZero() {
+		zScore = fmt.Sprintf(" (z-score %s)", a.ZScore.StringFixed(1))
+	}
+	return fmt.Sprintf("%s %s deviates %s%%%s from the average %s of %d runs", a.Metric, a.Value.StringFixed(2),
+		a.Deviation.Mul(decimal.NewFromInt(100)).StringFixed(1), zScore, a.Average.StringFixed(2), a.Runs)
+}
+
+// Keys returns the keys of the predictions in the sorted order
+func (r Report) Keys() []string {
+	keys := make([]string, 0, len(r.Predictions))
+	for k := range r.Predictions {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// StreamNames returns the names of the revenue streams in the sorted order
+func (r Report) StreamNames() []string {
+	names := make([]string, 0, len(r.Streams))
+	for name := range r.Streams {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// FailedKeys returns the keys of the failed predictions in the sorted order
+func (r Report) FailedKeys() []string {
+	keys := make([]string, 0, len(r.Failures))
+	for k := range r.Failures {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,332 @@
+package outputPrinter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pklimuk/ltv-predictor/roas"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ColumnKey       Column = "key"
+	ColumnUsers     Column = "users"
+	ColumnLTV7      Column = "ltv7"
+	ColumnPredicted Column = "predicted"
+	ColumnUplift    Column = "uplift"
+	ColumnModel     Column = "model"
+	ColumnError     Column = "error"
+	ColumnAlert     Column = "alert"
+	ColumnSpend     Column = "spend"
+	ColumnInstalls  Column = "installs"
+	ColumnCPI       Column = "cpi"
+	ColumnROAS      Column = "roas"
+	ColumnProfit    Column = "profit"
+	ColumnPayback   Column = "payback"
+	// ColumnStreams is expanded into a column of the predictions of every revenue stream
+	ColumnStreams Column = "streams"
+)
+
+// streamColumnPrefix prefixes the name of the stream in the columns ColumnStreams is expanded into
+const streamColumnPrefix = "stream:"
+
+const (
+	SortByKey       SortOrder = "key"
+	SortByPredicted SortOrder = "predicted"
+	SortByUsers     SortOrder = "users"
+)
+
+const DefaultRound = 2
+
+var (
+	ErrUnknownColumn    = errors.New("unknown column(%s)")
+	ErrUnknownSortOrder = errors.New("unknown sort order(%s)")
+	ErrNoColumns        = errors.New("at least one column should be selected")
+)
+
+var (
+	AllColumns = []Column{ColumnKey, ColumnUsers, ColumnLTV7, ColumnPredicted, ColumnUplift, ColumnModel, ColumnError, ColumnAlert,
+		ColumnSpend, ColumnInstalls, ColumnCPI, ColumnROAS, ColumnProfit, ColumnPayback, ColumnStreams}
+	DefaultColumns = []Column{ColumnKey, ColumnPredicted}
+	// ROASColumns are added to the selected columns if the report contains the spend and none of the spend columns is selected
+	ROASColumns = []Column{ColumnCPI, ColumnROAS, ColumnProfit, ColumnPayback}
+)
+
+var columnHeaders = map[Column]string{
+	ColumnKey:       "Key",
+	ColumnUsers:     "Users",
+	ColumnLTV7:      "LTV day 7",
+	ColumnPredicted: "Predicted LTV",
+	ColumnUplift:    "Uplift ratio",
+	ColumnModel:     "Model",
+	ColumnError:     "Error",
+	ColumnAlert:     "Alert",
+	ColumnSpend:     "Spend",
+	ColumnInstalls:  "Installs",
+	ColumnCPI:       "CPI",
+	ColumnROAS:      "ROAS",
+	ColumnProfit:    "Predicted profit",
+	ColumnPayback:   "Payback day",
+}
+
+type Column string
+
+type SortOrder string
+
+// TableOptions defines which columns are printed, in which order the rows go and
+// how many decimal places the numeric values are rounded to
+type TableOptions struct {
+	Columns []Column
+	SortBy  SortOrder
+	Round   int32
+}
+
+// row is a single line of the table with all the values that could be printed for a key
+type row struct {
+	key       string
+	users     int64
+	ltv7      decimal.Decimal
+	predicted decimal.Decimal
+	uplift    decimal.Decimal
+	model     string
+	// failure is the reason the prediction failed, other values of such rows are not printed
+	failure string
+	// alert contains the alerts raised for the key joined with "; ", empty if there are none
+	alert string
+	// spend is nil if the key is not in the spend file
+	spend *roas.Metrics
+	// streams contains the predictions of the revenue streams, failed streams are missing
+	streams map[string]decimal.Decimal
+}
+
+// ParseColumns converts a comma separated list of column names into columns
+func ParseColumns(s string) ([]Column, error) {
+	columns := make([]Column, 0)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		column := Column(name)
+		if !slices.Contains(AllColumns, column) {
+			return nil, fmt.Errorf(ErrUnknownColumn.Error(), name)
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, ErrNoColumns
+	}
+	return columns, nil
+}
+
+// ParseSortOrder validates the name of the sort order
+func ParseSortOrder(s string) (SortOrder, error) {
+	switch order := SortOrder(s); order {
+	case SortByKey, SortByPredicted, SortByUsers:
+		return order, nil
+	default:
+		return "", fmt.Errorf(ErrUnknownSortOrder.Error(), s)
+	}
+}
+
+// buildRows combines predictions with the aggregated data and sorts the result
+func buildRows(r Report, sortBy SortOrder) []row {
+	rows := make([]row, 0, len(r.Predictions))
+	alerts := alertsByKey(r.Alerts)
+	for k, predicted := range r.Predictions {
+		rw := row{key: k, predicted: predicted, model: r.Model, alert: alerts[k]}
+		if revenues, ok := r.Revenues[k]; ok {
+			rw.users = revenues.UsersCount
+		}
+		if ltvs := r.LTVs[k]; len(ltvs) > 0 {
+			rw.ltv7 = ltvs[len(ltvs)-1]
+			if !rw.ltv7.IsZero() {
+				rw.uplift = predicted.Div(rw.ltv7)
+			}
+		}
+		if metrics, ok := r.ROAS[k]; ok {
+			rw.spend = &metrics
+		}
+		for name, stream := range r.Streams {
+			if v, ok := stream.Predictions[k]; ok {
+				if rw.streams == nil {
+					rw.streams = make(map[string]decimal.Decimal, len(r.Streams))
+				}
+				rw.streams[name] = v
+			}
+		}
+		rows = append(rows, rw)
+	}
+	slices.SortFunc(rows, func(a, b row) int {
+		// predicted values and users counts are sorted from the highest to the lowest,
+		// ties are resolved by key to keep the output stable
+		switch sortBy {
+		case SortByPredicted:
+			if c := b.predicted.Cmp(a.predicted); c != 0 {
+				return c
+			}
+		case SortByUsers:
+			if a.users != b.users {
+				if a.users > b.users {
+					return -1
+				}
+				return 1
+			}
+		}
+		return strings.Compare(a.key, b.key)
+	})
+	return rows
+}
+
+// alertsByKey joins the alerts of every key in their order
+func alertsByKey(alerts []Alert) map[string]string {
+	result := make(map[string]string)
+	for _, a := range alerts {
+		if result[a.Key] != "" {
+			result[a.Key] += "; "
+		}
+		result[a.Key] += a.String()
+	}
+	return result
+}
+
+// failedRows returns rows of the failed predictions sorted by key, they are printed after the predictions
+func failedRows(r Report) []row {
+	rows := make([]row, 0, len(r.Failures))
+	for _, k := range r.FailedKeys() {
+		rw := row{key: k, model: r.Model, failure: r.Failures[k].Error()}
+		if revenues, ok := r.Revenues[k]; ok {
+			rw.users = revenues.UsersCount
+		}
+		rows = append(rows, rw)
+	}
+	return rows
+}
+
+// tableColumns adds the ROAS columns to the selected ones if the report contains the spend, the stream
+// columns if it contains the revenue streams and the error column if some predictions failed
+func tableColumns(columns []Column, r Report) []Column {
+	if len(r.ROAS) > 0 && !slices.ContainsFunc(columns, isSpend) {
+		columns = append(slices.Clip(columns), ROASColumns...)
+	}
+	if len(r.Streams) > 0 && !slices.Contains(columns, ColumnStreams) {
+		columns = append(slices.Clip(columns), ColumnStreams)
+	}
+	columns = expandStreams(columns, r.StreamNames())
+	if len(r.Failures) == 0 || slices.Contains(columns, ColumnError) {
+		return columns
+	}
+	return append(slices.Clip(columns), ColumnError)
+}
+
+// expandStreams replaces ColumnStreams with a column of every stream, it is removed if there are no streams
+func expandStreams(columns []Column, streams []string) []Column {
+	i := slices.Index(columns, ColumnStreams)
+	if i < 0 {
+		return columns
+	}
+	expanded := make([]Column, 0, len(columns)+len(streams))
+	expanded = append(expanded, columns[:i]...)
+	for _, name := range streams {
+		expanded = append(expanded, Column(streamColumnPrefix+name))
+	}
+	return append(expanded, columns[i+1:]...)
+}
+
+func headers(columns []Column) []string {
+	result := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if name, ok := streamName(c); ok {
+			result = append(result, "Predicted "+name)
+			continue
+		}
+		result = append(result, columnHeaders[c])
+	}
+	return result
+}
+
+// streamName returns the name of the stream of the column ColumnStreams is expanded into
+func streamName(c Column) (string, bool) {
+	return strings.CutPrefix(string(c), streamColumnPrefix)
+}
+
+// values returns the formatted values of the row for the given columns
+func (rw row) values(columns []Column, round int32) []string {
+	result := make([]string, 0, len(columns))
+	for _, c := range columns {
+		// failed rows have no values to print besides the key, users and the reason
+		if rw.failure != "" && isNumeric(c) && c != ColumnUsers {
+			result = append(result, "")
+			continue
+		}
+		// keys without the spend have no spend values
+		if rw.spend == nil && isSpend(c) {
+			result = append(result, "")
+			continue
+		}
+		if name, ok := streamName(c); ok {
+			// keys the stream failed or has no revenues for have no stream value
+			if v, ok := rw.streams[name]; ok {
+				result = append(result, v.StringFixed(round))
+			} else {
+				result = append(result, "")
+			}
+			continue
+		}
+		switch c {
+		case ColumnKey:
+			result = append(result, rw.key)
+		case ColumnUsers:
+			result = append(result, strconv.FormatInt(rw.users, 10))
+		case ColumnError:
+			result = append(result, rw.failure)
+		case ColumnAlert:
+			result = append(result, rw.alert)
+		case ColumnLTV7:
+			result = append(result, rw.ltv7.StringFixed(round))
+		case ColumnPredicted:
+			result = append(result, rw.predicted.StringFixed(round))
+		case ColumnUplift:
+			result = append(result, rw.uplift.StringFixed(round))
+		case ColumnModel:
+			result = append(result, rw.model)
+		case ColumnSpend:
+			result = append(result, rw.spend.Spend.StringFixed(round))
+		case ColumnInstalls:
+			result = append(result, strconv.FormatInt(rw.spend.Installs, 10))
+		case ColumnCPI:
+			result = append(result, rw.spend.CPI.StringFixed(round))
+		case ColumnROAS:
+			result = append(result, rw.spend.ROAS.StringFixed(round))
+		case ColumnProfit:
+			result = append(result, rw.spend.Profit.StringFixed(round))
+		case ColumnPayback:
+			result = append(result, paybackDay(rw.spend.PaybackDay))
+		case ColumnStreams:
+			// the column is expanded by tableColumns, it has no value of its own
+			result = append(result, "")
+		}
+	}
+	return result
+}
+
+// isNumeric reports whether the column contains numbers, used to align them to the right
+func isNumeric(c Column) bool {
+	return c != ColumnKey && c != ColumnModel && c != ColumnError && c != ColumnAlert
+}
+
+// isSpend reports whether the column contains the values joined from the spend file
+func isSpend(c Column) bool {
+	return slices.Contains([]Column{ColumnSpend, ColumnInstalls}, c) || slices.Contains(ROASColumns, c)
+}
+
+// paybackDay formats the payback day, it is empty if the spend is not paid back within the horizon
+func paybackDay(day int64) string {
+	if day == 0 {
+		return ""
+	}
+	return strconv.FormatInt(day, 10)
+}
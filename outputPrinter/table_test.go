@@ -0,0 +1,207 @@
+package outputPrinter
+
+import (
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/pklimuk/ltv-predictor/roas"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestReport() Report {
+	return Report{
+		Model:            "linearRegression",
+		PredictionLength: 60,
+		Predictions: predictor.PredictedLTVs{
+			"US": decimal.NewFromFloat(12.345),
+			"DE": decimal.NewFromFloat(20),
+			"TR": decimal.NewFromFloat(3),
+		},
+		Revenues: aggregator.AggregatedRevenuesByKey{
+			"US": {UsersCount: 10},
+			"DE": {UsersCount: 5},
+			"TR": {UsersCount: 20},
+		},
+		LTVs: aggregator.AggregatedLTVsByKey{
+			"US": {decimal.NewFromInt(1), decimal.NewFromInt(4)},
+			"DE": {decimal.NewFromInt(2), decimal.NewFromInt(5)},
+			"TR": {decimal.NewFromInt(1), decimal.Zero},
+		},
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		name              string
+		columns           string
+		expectedColumns   []Column
+		expectedErrString string
+	}{
+		{"Single column", "key", []Column{ColumnKey}, ""},
+		{"Multiple columns with spaces", "key, users,uplift", []Column{ColumnKey, ColumnUsers, ColumnUplift}, ""},
+		{"Unknown column", "key,unknown", nil, "unknown column(unknown)"},
+		{"No columns", " , ", nil, "at least one column should be selected"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			columns, err := ParseColumns(test.columns)
+
+			if test.expectedErrString != "" {
+				assert.EqualError(t, err, test.expectedErrString)
+				assert.Nil(t, columns)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expectedColumns, columns)
+			}
+		})
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	order, err := ParseSortOrder("predicted")
+	assert.NoError(t, err)
+	assert.Equal(t, SortByPredicted, order)
+
+	_, err = ParseSortOrder("unknown")
+	assert.EqualError(t, err, "unknown sort order(unknown)")
+}
+
+func TestBuildRows(t *testing.T) {
+	tests := []struct {
+		name         string
+		sortBy       SortOrder
+		expectedKeys []string
+	}{
+		{"Sort by key", SortByKey, []string{"DE", "TR", "US"}},
+		{"Sort by predicted", SortByPredicted, []string{"DE", "US", "TR"}},
+		{"Sort by users", SortByUsers, []string{"TR", "US", "DE"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows := buildRows(createTestReport(), test.sortBy)
+
+			keys := make([]string, 0, len(rows))
+			for _, rw := range rows {
+				keys = append(keys, rw.key)
+			}
+			assert.Equal(t, test.expectedKeys, keys)
+		})
+	}
+}
+
+func TestRow_Values(t *testing.T) {
+	rows := buildRows(createTestReport(), SortByKey)
+
+	assert.Equal(t, []string{"US", "10", "4.0", "12.3", "3.1", "linearRegression", "", "", "", "", "", "", "", "", ""}, rows[2].values(AllColumns, 1))
+	// uplift is not calculated when observed LTV is zero
+	assert.Equal(t, []string{"TR", "0.00", "0.00"}, rows[1].values([]Column{ColumnKey, ColumnLTV7, ColumnUplift}, 2))
+}
+
+func createTestReportWithFailures() Report {
+	report := createTestReport()
+	report.Revenues["FR"] = aggregator.AggregatedRevenues{UsersCount: 3}
+	report.Failures = predictor.Failures{"FR": predictor.ErrNotEnoughData}
+	return report
+}
+
+func createTestReportWithAlerts() Report {
+	report := createTestReport()
+	report.Alerts = []Alert{{
+		Key:       "DE",
+		Metric:    AlertMetricPredicted,
+		Value:     decimal.NewFromInt(20),
+		Average:   decimal.NewFromInt(40),
+		Deviation: decimal.NewFromFloat(-0.5),
+		ZScore:    decimal.NewFromFloat(-4.2),
+		Runs:      7,
+	}}
+	return report
+}
+
+func createTestReportWithROAS() Report {
+	report := createTestReport()
+	report.ROAS = roas.MetricsByKey{
+		"US": {
+			Spend:      decimal.NewFromInt(50),
+			Installs:   10,
+			CPI:        decimal.NewFromInt(5),
+			ROAS:       decimal.NewFromFloat(2.469),
+			Profit:     decimal.NewFromFloat(73.45),
+			PaybackDay: 9,
+		},
+		"DE": {
+			Spend:      decimal.NewFromInt(250),
+			Installs:   10,
+			CPI:        decimal.NewFromInt(25),
+			ROAS:       decimal.NewFromFloat(0.8),
+			Profit:     decimal.NewFromInt(-50),
+			PaybackDay: 0,
+		},
+	}
+	return report
+}
+
+func TestRow_Values_ROAS(t *testing.T) {
+	report := createTestReportWithROAS()
+	rows := buildRows(report, SortByKey)
+	columns := tableColumns(DefaultColumns, report)
+
+	assert.Equal(t, []Column{ColumnKey, ColumnPredicted, ColumnCPI, ColumnROAS, ColumnProfit, ColumnPayback}, columns)
+	assert.Equal(t, []string{"DE", "20.00", "25.00", "0.80", "-50.00", ""}, rows[0].values(columns, 2))
+	assert.Equal(t, []string{"TR", "3.00", "", "", "", ""}, rows[1].values(columns, 2))
+	assert.Equal(t, []string{"US", "12.35", "5.00", "2.47", "73.45", "9"}, rows[2].values(columns, 2))
+	// the ROAS columns are not added if any of the spend columns is selected
+	selected := []Column{ColumnKey, ColumnSpend, ColumnInstalls}
+	assert.Equal(t, selected, tableColumns(selected, report))
+	assert.Equal(t, []string{"US", "50.00", "10"}, rows[2].values(selected, 2))
+}
+
+func createTestReportWithStreams() Report {
+	report := createTestReport()
+	report.Streams = map[string]StreamReport{
+		"iap": {
+			Model:       "linearRegression",
+			Predictions: predictor.PredictedLTVs{"DE": decimal.NewFromInt(15), "US": decimal.NewFromFloat(8.5)},
+			LTVs:        aggregator.AggregatedLTVsByKey{"DE": {decimal.NewFromInt(2), decimal.NewFromInt(3)}},
+		},
+		"ads": {
+			Model:       "linearExtrapolation",
+			Predictions: predictor.PredictedLTVs{"DE": decimal.NewFromInt(5)},
+			Failures:    predictor.Failures{"US": predictor.ErrNotEnoughData},
+		},
+	}
+	return report
+}
+
+func TestRow_Values_Streams(t *testing.T) {
+	report := createTestReportWithStreams()
+	rows := buildRows(report, SortByKey)
+	columns := tableColumns(DefaultColumns, report)
+
+	assert.Equal(t, []Column{ColumnKey, ColumnPredicted, "stream:ads", "stream:iap"}, columns)
+	assert.Equal(t, []string{"Key", "Predicted LTV", "Predicted ads", "Predicted iap"}, headers(columns))
+	assert.Equal(t, []string{"DE", "20.00", "5.00", "15.00"}, rows[0].values(columns, 2))
+	// keys without the revenues of a stream and failed streams have no values
+	assert.Equal(t, []string{"TR", "3.00", "", ""}, rows[1].values(columns, 2))
+	assert.Equal(t, []string{"US", "12.35", "", "8.50"}, rows[2].values(columns, 2))
+	// the stream columns are placed where the streams column is selected
+	selected := []Column{ColumnKey, ColumnStreams, ColumnUsers}
+	assert.Equal(t, []Column{ColumnKey, "stream:ads", "stream:iap", ColumnUsers}, tableColumns(selected, report))
+	assert.Equal(t, []Column{ColumnKey}, tableColumns([]Column{ColumnKey, ColumnStreams}, createTestReport()))
+}
+
+func TestFailedRows(t *testing.T) {
+	report := createTestReportWithFailures()
+
+	rows := failedRows(report)
+
+	assert.Len(t, rows, 1)
+	assert.Equal(t, []string{"FR", "3", "", "", "", "linearRegression", "not enough data to make prediction", "", "", "", "", "", "", "", ""},
+		rows[0].values(AllColumns, 2))
+	assert.Equal(t, []Column{ColumnKey, ColumnPredicted, ColumnError}, tableColumns(DefaultColumns, report))
+	assert.Equal(t, DefaultColumns, tableColumns(DefaultColumns, createTestReport()))
+}
@@ -0,0 +1,120 @@
+package outputPrinter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/roas"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrTemplateError = errors.New("template error: %w")
+	ErrNoTemplate    = errors.New("path to the template file is not specified")
+)
+
+var templateFuncs = template.FuncMap{
+	"round": func(places int32, d decimal.Decimal) string {
+		return d.StringFixed(places)
+	},
+	"join": func(sep string, places int32, values []decimal.Decimal) string {
+		result := make([]string, 0, len(values))
+		for _, v := range values {
+			result = append(result, v.StringFixed(places))
+		}
+		return strings.Join(result, sep)
+	},
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"replace": func(old, new, s string) string {
+		return strings.ReplaceAll(s, old, new)
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// TemplatePrinter renders the report with a user supplied text/template
+type TemplatePrinter struct {
+	Template *template.Template
+}
+
+// TemplateData is the value the template is executed with
+type TemplateData struct {
+	Model            string
+	Aggregation      string
+	Source           string
+	PredictionLength int64
+	GeneratedAt      time.Time
+	Predictions      []TemplatePrediction
+	Failures         []TemplateFailure
+	Alerts           []Alert
+}
+
+// TemplatePrediction contains the prediction and the observed LTVs of a single key
+type TemplatePrediction struct {
+	Key       string
+	Users     int64
+	LTVs      []decimal.Decimal
+	LastLTV   decimal.Decimal
+	Predicted decimal.Decimal
+	Uplift    decimal.Decimal
+	// ROAS is nil if the key is not in the spend file
+	ROAS *roas.Metrics
+}
+
+// TemplateFailure contains the reason the prediction of a key failed
+type TemplateFailure struct {
+	Key   string
+	Users int64
+	Error string
+}
+
+// NewTemplatePrinter parses the template file, so errors in the template are reported before processing
+func NewTemplatePrinter(path string) (TemplatePrinter, error) {
+	if path == "" {
+		return TemplatePrinter{}, ErrNoTemplate
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return TemplatePrinter{}, fmt.Errorf(ErrTemplateError.Error(), err)
+	}
+	return TemplatePrinter{Template: tmpl}, nil
+}
+
+func (p TemplatePrinter) Print(w io.Writer, data Report) error {
+	templateData := TemplateData{
+		Model:            data.Model,
+		Aggregation:      data.Aggregation,
+		Source:           data.Source,
+		PredictionLength: data.PredictionLength,
+		GeneratedAt:      data.GeneratedAt,
+		Alerts:           data.Alerts,
+	}
+	for _, rw := range buildRows(data, SortByKey) {
+		templateData.Predictions = append(templateData.Predictions, TemplatePrediction{
+			Key:       rw.key,
+			Users:     rw.users,
+			LTVs:      data.LTVs[rw.key],
+			LastLTV:   rw.ltv7,
+			Predicted: rw.predicted,
+			Uplift:    rw.uplift,
+			ROAS:      rw.spend,
+		})
+	}
+	for _, rw := range failedRows(data) {
+		templateData.Failures = append(templateData.Failures, TemplateFailure{Key: rw.key, Users: rw.users, Error: rw.failure})
+	}
+	err := p.Template.Execute(w, templateData)
+	if err != nil {
+		return fmt.Errorf(ErrTemplateError.Error(), err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package outputPrinter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTempTemplateFile(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "output.tmpl")
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("Failed to create temp template file: %v", err)
+	}
+	return path
+}
+
+func TestTemplatePrinter_Print(t *testing.T) {
+	path := createTempTemplateFile(t, `-- {{.Model}} by {{.Aggregation}} from {{.Source}} at {{.GeneratedAt.Format "2006-01-02"}}
+{{range .Predictions -}}
+INSERT INTO ltv (key, users, horizon, predicted, history) VALUES ('{{replace "'" "''" .Key}}', {{.Users}}, {{$.PredictionLength}}, {{round 2 .Predicted}}, '{{join ";" 1 .LTVs}}');
+{{end}}`)
+	printer, err := NewTemplatePrinter(path)
+	assert.NoError(t, err)
+	report := createTestReport()
+	report.Aggregation = "country"
+	report.Source = "data.csv"
+	report.GeneratedAt = time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+
+	err = printer.Print(&buf, report)
+
+	assert.NoError(t, err)
+	expected := `-- linearRegression by country from data.csv at 2023-10-01
+INSERT INTO ltv (key, users, horizon, predicted, history) VALUES ('DE', 5, 60, 20.00, '2.0;5.0');
+INSERT INTO ltv (key, users, horizon, predicted, history) VALUES ('TR', 20, 60, 3.00, '1.0;0.0');
+INSERT INTO ltv (key, users, horizon, predicted, history) VALUES ('US', 10, 60, 12.35, '1.0;4.0');
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestTemplatePrinter_Print_ExecutionError(t *testing.T) {
+	path := createTempTemplateFile(t, `{{.Unknown}}`)
+	printer, err := NewTemplatePrinter(path)
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+
+	err = printer.Print(&buf, createTestReport())
+
+	assert.ErrorContains(t, err, "template error:")
+	assert.ErrorContains(t, err, "can't evaluate field Unknown")
+}
+
+func TestNewTemplatePrinter_Errors(t *testing.T) {
+	_, err := NewTemplatePrinter("")
+	assert.ErrorIs(t, err, ErrNoTemplate)
+
+	_, err = NewTemplatePrinter("invalid/path.tmpl")
+	assert.ErrorContains(t, err, "template error: open invalid/path.tmpl")
+
+	_, err = NewTemplatePrinter(createTempTemplateFile(t, `{{range}}`))
+	assert.ErrorContains(t, err, "template error:")
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+
+	"github.com/pklimuk/ltv-predictor/config"
+	flagsParser "github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/history"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/pklimuk/ltv-predictor/processor"
+	"github.com/pklimuk/ltv-predictor/roas"
+	"github.com/shopspring/decimal"
+)
+
+func predict(args []string) int {
+	// errors of the config file and the environment are reported together with the validation errors
+	flags, parseErr := flagsParser.ParseCommand(flagsParser.PredictCommand, args, os.LookupEnv)
+	if listComponents(flags) {
+		return exitOK
+	}
+
+	appConfig, err := config.CreateAppConfig(flags)
+	if err = errors.Join(parseErr, err); err != nil {
+		log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+		return exitUsage
+	}
+	if flags.ModelFile != "" {
+		// predictions are reproduced from the saved parameters instead of fitting the model
+		model, err := config.LoadModel(flags)
+		if err != nil {
+			log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+			return exitUsage
+		}
+		appConfig.Predictor = predictor.SavedModel{Model: model, KeepGoing: flags.KeepGoing}
+		appConfig.Model = model.Model
+		// the model file has no models of the streams, so they are not predicted instead of being fitted again
+		appConfig.Streams = nil
+	}
+	var spend roas.SpendByKey
+	if flags.Spend != "" {
+		spend, err = config.LoadSpend(flags)
+		if err != nil {
+			log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+			return exitUsage
+		}
+	}
+
+	ctx, cancel := newContext(flags.Timeout)
+	defer cancel()
+
+	p := newProcessor(appConfig)
+	p.Spend = spend
+	if flags.History != "" {
+		// the input is hashed before the run, so the saved hash matches the data the predictions are made from
+		hash, err := history.HashFile(flags.Source)
+		if err != nil {
+			log.Printf("An error occurred during hashing of the source file:\n\t%s", indent(err))
+			return exitFailure
+		}
+		p.Recorder = history.Recorder{Store: history.Store{Dir: flags.History}, SourceHash: hash}
+	}
+	if config.AlertsEnabled(flags) {
+		p.Detector = history.DriftDetector{
+			Store:     history.Store{Dir: flags.History},
+			Window:    flags.AlertWindow,
+			Threshold: decimal.NewFromFloat(flags.AlertThreshold),
+			ZScore:    decimal.NewFromFloat(flags.AlertZScore),
+		}
+	}
+	if flags.Webhook != "" {
+		p.Notifier = config.CreateNotifier(flags)
+	}
+	err = p.Process(ctx)
+	var partialErr *predictor.PartialError
+	partial := errors.As(err, &partialErr)
+	if partial {
+		log.Printf("Predictions of some keys failed:\n\t%s", indent(partialErr))
+	}
+	var alertsErr *processor.AlertsError
+	if errors.As(err, &alertsErr) {
+		for _, a := range alertsErr.Alerts {
+			log.Printf("Alert: %s: %s", a.Key, a)
+		}
+		return exitAlerts
+	}
+	if partial {
+		return exitPartialSuccess
+	}
+	if err != nil {
+		log.Printf("An error occurred during processing:\n\t%s", indent(err))
+		return exitFailure
+	}
+	return exitOK
+}
+
+// listComponents prints the registered components of the settings set to "list" and reports whether anything was printed
+func listComponents(flags *flagsParser.Flags) bool {
+	listed := false
+	for _, setting := range []struct {
+		value    string
+		describe func(io.Writer) error
+	}{
+		{flags.Model, config.Predictors.Describe},
+		{flags.AggregateBy, config.Aggregators.Describe},
+		{flags.Output, config.Printers.Describe},
+	} {
+		if setting.value != config.ListName {
+			continue
+		}
+		err := setting.describe(os.Stdout)
+		if err != nil {
+			log.Fatalf("An error occurred during listing:\n\t%s", indent(err))
+		}
+		listed = true
+	}
+	return listed
+}
@@ -0,0 +1,75 @@
+package predictor
+
+import (
+	"errors"
+	"math"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/shopspring/decimal"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+var (
+	ErrIntervalNotSupported = errors.New("confidence interval is supported only for linear curves")
+	ErrInvalidConfidence    = errors.New("confidence should be between 0 and 1")
+)
+
+// minIntervalDays is the number of observed days needed to estimate the spread of LTVs around a line
+const minIntervalDays = 3
+
+// LowerBounds returns the lower bounds of the one-sided prediction intervals of the fitted lines at the predicted day,
+// the true LTV is expected to be above the bound with the given confidence, e.g. 0.9. The interval is estimated from
+// the residuals of the observed LTVs around the line, keys which are not fitted or have too few days are reported as failures
+func LowerBounds(params ParamsByKey, al aggregator.AggregatedLTVsByKey, predictionLength int64, confidence float64) (PredictedLTVs, Failures, error) {
+	if confidence <= 0 || confidence >= 1 {
+		return nil, nil, ErrInvalidConfidence
+	}
+	result := make(PredictedLTVs)
+	failures := make(Failures)
+	for k, data := range al {
+		p, ok := params[k]
+		if !ok {
+			failures[k] = ErrKeyNotFitted
+			continue
+		}
+		lower, err := lowerBound(p, data, predictionLength, confidence)
+		if err != nil {
+			failures[k] = err
+			continue
+		}
+		result[k] = lower
+	}
+	return result, failures, nil
+}
+
+// lowerBound uses the prediction interval of the simple linear regression:
+// y0 - t(n-2) * s * sqrt(1 + 1/n + (x0 - mean(x))^2 / sum((x - mean(x))^2)).
+// The residuals and n are taken from the days before the plateau the line is fitted on, see prepareData
+func lowerBound(p Params, data []decimal.Decimal, predictionLength int64, confidence float64) (decimal.Decimal, error) {
+	if len(p.Coefficients) != 2 {
+		return decimal.Decimal{}, ErrIntervalNotSupported
+	}
+	days := min(p.Days, len(data))
+	if days == 0 {
+		return decimal.Decimal{}, ErrNotEnoughData
+	}
+	// conversion to float64 could affect the precision, but it is not critical for the width of the interval
+	ys := prepareData(data[:days])
+	n := len(ys)
+	if n < minIntervalDays {
+		return decimal.Decimal{}, ErrNotEnoughData
+	}
+	var squares, sxx float64
+	mean := float64(n-1) / 2
+	for i, y := range ys {
+		fitted, _ := p.At(int64(i + 1)).Float64()
+		residual := y - fitted
+		squares += residual * residual
+		sxx += (float64(i) - mean) * (float64(i) - mean)
+	}
+	s := math.Sqrt(squares / float64(n-2))
+	x0 := float64(predictionLength - 1)
+	width := s * math.Sqrt(1+1/float64(n)+(x0-mean)*(x0-mean)/sxx)
+	t := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(n - 2)}.Quantile(confidence)
+	return p.At(predictionLength).Sub(decimal.NewFromFloat(t * width)), nil
+}
@@ -0,0 +1,66 @@
+package predictor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLowerBounds(t *testing.T) {
+	line := Params{Coefficients: decimals(1, 1), Days: 4}
+	params := ParamsByKey{"US": line, "DE": line, "FR": {Coefficients: decimals(1, 1, 1), Days: 4}, "TR": line}
+	al := aggregator.AggregatedLTVsByKey{
+		// the observed LTVs lie on the line, so the interval has no width
+		"US": decimals(1, 2, 3, 4),
+		"DE": decimals(1, 3, 3, 4),
+		"FR": decimals(1, 2, 3, 4),
+		// the plateau after the second day is not a part of the fitted points
+		"TR": decimals(1, 2, 2, 2),
+		"PL": decimals(1, 2, 3, 4),
+	}
+
+	result, failures, err := LowerBounds(params, al, 10, 0.9)
+
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(10).Equal(result["US"]))
+	assert.Equal(t, Failures{
+		"DE": ErrNotEnoughData,
+		"FR": ErrIntervalNotSupported,
+		"TR": ErrNotEnoughData,
+		"PL": ErrKeyNotFitted,
+	}, failures)
+}
+
+func TestLowerBounds_Plateau(t *testing.T) {
+	// the line is fitted to the first four days, the plateau is dropped by prepareData
+	al := aggregator.AggregatedLTVsByKey{"DE": decimals(1, 3, 4, 6, 6, 6, 6)}
+	params, err := LinearRegressor{}.Fit(context.Background(), al)
+	assert.NoError(t, err)
+
+	result, failures, err := LowerBounds(params, al, 10, 0.9)
+
+	assert.NoError(t, err)
+	assert.Empty(t, failures)
+	// the line is 1.1 + 1.6x, the residuals are -0.1, 0.3, -0.3, 0.1, so s = sqrt(0.2 / 2) = 0.3162,
+	// the width is 0.3162 * sqrt(1 + 1/4 + 7.5^2 / 5) = 1.1180 and t(2, 0.9) = 1.8856, so the bound is 15.5 - 2.1082
+	assert.Equal(t, "13.39", result["DE"].StringFixed(2))
+}
+
+func TestLowerBounds_HigherConfidenceIsLower(t *testing.T) {
+	params := ParamsByKey{"DE": {Coefficients: decimals(1, 1), Days: 4}}
+	al := aggregator.AggregatedLTVsByKey{"DE": decimals(1, 3, 4, 5)}
+
+	low, _, _ := LowerBounds(params, al, 10, 0.8)
+	high, _, _ := LowerBounds(params, al, 10, 0.95)
+
+	assert.True(t, high["DE"].LessThan(low["DE"]))
+}
+
+func TestLowerBounds_InvalidConfidence(t *testing.T) {
+	_, _, err := LowerBounds(ParamsByKey{}, aggregator.AggregatedLTVsByKey{}, 10, 1)
+
+	assert.ErrorIs(t, err, ErrInvalidConfidence)
+}
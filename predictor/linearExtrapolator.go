@@ -1,36 +1,46 @@
 package predictor
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pklimuk/ltv-predictor/aggregator"
 	"github.com/shopspring/decimal"
 )
 
-type LinearExtrapolator struct{}
+// LinearExtrapolator predicts LTVs of keys concurrently if Workers is greater than 1, with KeepGoing
+// keys which could not be predicted are reported in PartialError instead of failing the whole run
+type LinearExtrapolator struct {
+	Workers   int
+	KeepGoing bool
+}
 
-func (le LinearExtrapolator) Predict(al aggregator.AggregatedLTVsByKey, predictionLength int64) (PredictedLTVs, error) {
-	var result = make(map[string]decimal.Decimal, len(al))
-	for k, v := range al {
-		predictedLTV, err := linearExtrapolation(v, decimal.NewFromInt(predictionLength))
+func (le LinearExtrapolator) Predict(ctx context.Context, al aggregator.AggregatedLTVsByKey, predictionLength int64) (PredictedLTVs, error) {
+	if predictionLength < 3 {
+		return nil, fmt.Errorf(ErrPredictorError.Error(), ErrPredictLengthTooShort)
+	}
+	return predictKeys(ctx, al, le.Workers, le.KeepGoing, func(v []decimal.Decimal) (*decimal.Decimal, error) {
+		params, err := linearExtrapolation(v)
 		if err != nil {
-			return nil, fmt.Errorf(ErrPredictorError.Error(), err)
+			return nil, err
 		}
-		result[k] = *predictedLTV
-	}
-	return result, nil
+		prediction := params.At(predictionLength)
+		return &prediction, nil
+	})
 }
 
-func linearExtrapolation(data []decimal.Decimal, predictLength decimal.Decimal) (*decimal.Decimal, error) {
-	if predictLength.LessThan(decimal.NewFromInt(3)) {
-		return nil, ErrPredictLengthTooShort
-	}
-	// decrease the length by one to take into account index starting from 0
-	predictLength = predictLength.Sub(decimal.NewFromInt(1))
+// Fit returns the line through the last two different LTVs of every key
+func (le LinearExtrapolator) Fit(ctx context.Context, al aggregator.AggregatedLTVsByKey) (ParamsByKey, error) {
+	result, err := forEachKey(ctx, al, le.Workers, le.KeepGoing, func(_ string, data []decimal.Decimal) (Params, error) {
+		return linearExtrapolation(data)
+	})
+	return ParamsByKey(result), err
+}
 
+func linearExtrapolation(data []decimal.Decimal) (Params, error) {
 	// at least two points are needed to extrapolate
 	if len(data) < 2 {
-		return nil, ErrNotEnoughData
+		return Params{}, ErrNotEnoughData
 	}
 	x1, y1 := decimal.NewFromInt(int64(len(data)-1)), data[len(data)-1]
 	x2, y2 := decimal.NewFromInt(int64(len(data)-1)), data[len(data)-1]
@@ -46,12 +56,8 @@ func linearExtrapolation(data []decimal.Decimal, predictLength decimal.Decimal)
 			break
 		}
 	}
-	//  y = y1 + ((x - x1) / (x2 - x1)) * (y2 - y1)
-	subX1FromX := predictLength.Sub(x1)
-	subX1FromX2 := x2.Sub(x1)
-	divSubs := subX1FromX.Div(subX1FromX2)
-	subY1FromY2 := y2.Sub(y1)
-	mulDivSubs := divSubs.Mul(subY1FromY2)
-	predictedY := y1.Add(mulDivSubs)
-	return &predictedY, nil
+	//  y = y1 + ((x - x1) / (x2 - x1)) * (y2 - y1) = (y1 - slope * x1) + slope * x
+	slope := y2.Sub(y1).Div(x2.Sub(x1))
+	intercept := y1.Sub(slope.Mul(x1))
+	return Params{Coefficients: []decimal.Decimal{intercept, slope}, Days: len(data)}, nil
 }
@@ -1,6 +1,7 @@
 package predictor
 
 import (
+	"context"
 	"testing"
 
 	"github.com/pklimuk/ltv-predictor/aggregator"
@@ -19,7 +20,7 @@ func TestLinearExtrapolator_Predict(t *testing.T) {
 	// Create a LinearExtrapolator instance
 	le := LinearExtrapolator{}
 
-	predictedLTVs, err := le.Predict(aggregatedData, 60)
+	predictedLTVs, err := le.Predict(context.Background(), aggregatedData, 60)
 
 	// Assert that there is no error
 	assert.NoError(t, err)
@@ -38,11 +39,11 @@ func TestLinearExtrapolator_Predict_NotEnoughData(t *testing.T) {
 	// Create a LinearExtrapolator instance
 	le := LinearExtrapolator{}
 
-	_, err := le.Predict(aggregatedData, 60)
+	_, err := le.Predict(context.Background(), aggregatedData, 60)
 
 	// Assert that there is no error
 	assert.Error(t, err)
-	assert.Equal(t, "predictor error: not enough data to make prediction", err.Error())
+	assert.Equal(t, "predictor error: campaign1: not enough data to make prediction", err.Error())
 }
 
 func TestLinearExtrapolator_Predict_PredictionLengthTooShort(t *testing.T) {
@@ -56,9 +57,21 @@ func TestLinearExtrapolator_Predict_PredictionLengthTooShort(t *testing.T) {
 	// Create a LinearExtrapolator instance
 	le := LinearExtrapolator{}
 
-	_, err := le.Predict(aggregatedData, 2)
+	_, err := le.Predict(context.Background(), aggregatedData, 2)
 
 	// Assert that there is no error
 	assert.Error(t, err)
 	assert.Equal(t, "predictor error: prediction length should be greater than 2", err.Error())
 }
+
+func TestLinearExtrapolator_Predict_ContextCancelled(t *testing.T) {
+	aggregatedData := aggregator.AggregatedLTVsByKey{
+		"campaign1": []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := LinearExtrapolator{}.Predict(ctx, aggregatedData, 60)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -1,37 +1,48 @@
 package predictor
 
 import (
+	"context"
 	"fmt"
+	"math"
 
 	"github.com/pklimuk/ltv-predictor/aggregator"
 	"github.com/shopspring/decimal"
 	"gonum.org/v1/gonum/stat"
 )
 
-type LinearRegressor struct{}
+// LinearRegressor predicts LTVs of keys concurrently if Workers is greater than 1, with KeepGoing
+// keys which could not be predicted are reported in PartialError instead of failing the whole run
+type LinearRegressor struct {
+	Workers   int
+	KeepGoing bool
+}
 
-func (lr LinearRegressor) Predict(al aggregator.AggregatedLTVsByKey, predictionLength int64) (PredictedLTVs, error) {
-	var result = make(map[string]decimal.Decimal, len(al))
-	for k, v := range al {
-		predictedLTV, err := linearRegression(v, float64(predictionLength))
+func (lr LinearRegressor) Predict(ctx context.Context, al aggregator.AggregatedLTVsByKey, predictionLength int64) (PredictedLTVs, error) {
+	if predictionLength < 3 {
+		return nil, fmt.Errorf(ErrPredictorError.Error(), ErrPredictLengthTooShort)
+	}
+	return predictKeys(ctx, al, lr.Workers, lr.KeepGoing, func(v []decimal.Decimal) (*decimal.Decimal, error) {
+		params, err := linearRegression(v)
 		if err != nil {
-			return nil, fmt.Errorf(ErrPredictorError.Error(), err)
+			return nil, err
 		}
-		result[k] = *predictedLTV
-	}
-	return result, nil
+		prediction := params.At(predictionLength)
+		return &prediction, nil
+	})
 }
 
-func linearRegression(data []decimal.Decimal, predictLength float64) (*decimal.Decimal, error) {
-	if predictLength < 3 {
-		return nil, ErrPredictLengthTooShort
-	}
-	// decrease the length by one to take into account index starting from 0
-	predictLength--
+// Fit returns the intercept and the slope of the line fitted to the LTVs of every key
+func (lr LinearRegressor) Fit(ctx context.Context, al aggregator.AggregatedLTVsByKey) (ParamsByKey, error) {
+	result, err := forEachKey(ctx, al, lr.Workers, lr.KeepGoing, func(_ string, data []decimal.Decimal) (Params, error) {
+		return linearRegression(data)
+	})
+	return ParamsByKey(result), err
+}
 
+func linearRegression(data []decimal.Decimal) (Params, error) {
 	// at least two points are needed for prediction
 	if len(data) < 2 {
-		return nil, ErrNotEnoughData
+		return Params{}, ErrNotEnoughData
 	}
 
 	// conversion to float64 could affect the precision, but it is not critical for this task
@@ -44,9 +55,15 @@ func linearRegression(data []decimal.Decimal, predictLength float64) (*decimal.D
 
 	// y = alpha + beta*x
 	alpha, beta := stat.LinearRegression(xs, ys, nil, false)
-	predictedY := alpha + beta*predictLength
-	predictedYDecimal := decimal.NewFromFloat(predictedY)
-	return &predictedYDecimal, nil
+	// a single point, e.g. of a flat curve, has no variance and gives NaN which decimals can't represent
+	if !isFinite(alpha) || !isFinite(beta) {
+		return Params{}, ErrCantFitLine
+	}
+	return Params{Coefficients: []decimal.Decimal{decimal.NewFromFloat(alpha), decimal.NewFromFloat(beta)}, Days: len(data)}, nil
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
 }
 
 // prepareData converts data to float64 and leaves only changing values
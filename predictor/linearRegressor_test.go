@@ -1,6 +1,7 @@
 package predictor
 
 import (
+	"context"
 	"testing"
 
 	"github.com/pklimuk/ltv-predictor/aggregator"
@@ -19,7 +20,7 @@ func TestLinearRegressor_Predict(t *testing.T) {
 	// Create a LinearRegressor instance
 	lr := LinearRegressor{}
 
-	predictedLTVs, err := lr.Predict(aggregatedData, 60)
+	predictedLTVs, err := lr.Predict(context.Background(), aggregatedData, 60)
 
 	// Assert that there is no error
 	assert.NoError(t, err)
@@ -38,11 +39,26 @@ func TestLinearRegressor_Predict_NotEnoughData(t *testing.T) {
 	// Create a LinearRegressor instance
 	lr := LinearRegressor{}
 
-	_, err := lr.Predict(aggregatedData, 60)
+	_, err := lr.Predict(context.Background(), aggregatedData, 60)
 
 	// Assert that there is no error
 	assert.Error(t, err)
-	assert.Equal(t, "predictor error: not enough data to make prediction", err.Error())
+	assert.Equal(t, "predictor error: campaign1: not enough data to make prediction", err.Error())
+}
+
+func TestLinearRegressor_Predict_FlatCurve(t *testing.T) {
+	aggregatedData := aggregator.AggregatedLTVsByKey{
+		"campaign1": []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)},
+		"flat":      []decimal.Decimal{decimal.Zero, decimal.Zero, decimal.Zero, decimal.Zero, decimal.Zero, decimal.Zero, decimal.Zero},
+	}
+
+	_, err := LinearRegressor{}.Predict(context.Background(), aggregatedData, 60)
+	assert.EqualError(t, err, "predictor error: flat: line could not be fitted to the LTVs")
+
+	// the key is reported as a failure in the keep going mode
+	predictedLTVs, err := LinearRegressor{KeepGoing: true}.Predict(context.Background(), aggregatedData, 60)
+	assert.Equal(t, &PartialError{Failures: Failures{"flat": ErrCantFitLine}}, err)
+	assert.True(t, decimal.NewFromInt(60).Equal(predictedLTVs["campaign1"]))
 }
 
 func TestLinearRegressor_Predict_PredictionLengthTooShort(t *testing.T) {
@@ -56,9 +72,21 @@ func TestLinearRegressor_Predict_PredictionLengthTooShort(t *testing.T) {
 	// Create a LinearRegressor instance
 	lr := LinearRegressor{}
 
-	_, err := lr.Predict(aggregatedData, 2)
+	_, err := lr.Predict(context.Background(), aggregatedData, 2)
 
 	// Assert that there is no error
 	assert.Error(t, err)
 	assert.Equal(t, "predictor error: prediction length should be greater than 2", err.Error())
 }
+
+func TestLinearRegressor_Predict_ContextCancelled(t *testing.T) {
+	aggregatedData := aggregator.AggregatedLTVsByKey{
+		"campaign1": []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2)},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := LinearRegressor{}.Predict(ctx, aggregatedData, 60)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
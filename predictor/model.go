@@ -0,0 +1,155 @@
+package predictor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/shopspring/decimal"
+)
+
+// ModelVersion is the version of the saved model format, it is increased on incompatible changes
+const ModelVersion = 1
+
+var (
+	ErrModelError              = errors.New("model error: %w")
+	ErrUnsupportedModelVersion = errors.New("unsupported model version(%d), supported version is %d")
+	ErrAggregationMismatch     = errors.New("model was fitted on keys aggregated by %s, not by %s")
+	ErrKeyNotFitted            = errors.New("key was not fitted")
+)
+
+// Params are the fitted parameters of a key
+type Params struct {
+	// Coefficients of the polynomial of the day index(starting from 0) starting from the constant term,
+	// e.g. the intercept and the slope of a line
+	Coefficients []decimal.Decimal `json:"coefficients"`
+	// Days is the number of observed days the parameters were fitted on
+	Days int `json:"days"`
+}
+
+// At returns the LTV of the day, days start from 1
+func (p Params) At(day int64) decimal.Decimal {
+	x := decimal.NewFromInt(day - 1)
+	result := decimal.Zero
+	for i := len(p.Coefficients) - 1; i >= 0; i-- {
+		result = result.Mul(x).Add(p.Coefficients[i])
+	}
+	return result
+}
+
+type ParamsByKey map[string]Params
+
+// Fitter is a predictor which fits parameters of every key, its predictions are the fitted curves at the predicted day
+type Fitter interface {
+	Predictor
+	Fit(ctx context.Context, al aggregator.AggregatedLTVsByKey) (ParamsByKey, error)
+}
+
+// Model is the saved result of fitting, it allows to reproduce the predictions without refitting
+type Model struct {
+	Version     int         `json:"version"`
+	Model       string      `json:"model"`
+	Aggregation string      `json:"aggregation"`
+	Source      string      `json:"source"`
+	FittedAt    time.Time   `json:"fittedAt"`
+	Params      ParamsByKey `json:"params"`
+}
+
+// WriteModel writes the model as an indented JSON document
+func WriteModel(w io.Writer, m Model) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(m)
+}
+
+// ReadModel reads the model written by WriteModel, models of other versions are rejected
+func ReadModel(r io.Reader) (*Model, error) {
+	var m Model
+	err := json.NewDecoder(r).Decode(&m)
+	if err != nil {
+		return nil, fmt.Errorf(ErrModelError.Error(), err)
+	}
+	if m.Version != ModelVersion {
+		return nil, fmt.Errorf(ErrModelError.Error(), fmt.Errorf(ErrUnsupportedModelVersion.Error(), m.Version, ModelVersion))
+	}
+	return &m, nil
+}
+
+// CheckAggregation returns an error if the model was fitted on keys of another aggregation
+func (m *Model) CheckAggregation(aggregation string) error {
+	if m.Aggregation != aggregation {
+		return fmt.Errorf(ErrModelError.Error(), fmt.Errorf(ErrAggregationMismatch.Error(), m.Aggregation, aggregation))
+	}
+	return nil
+}
+
+// SavedModel predicts LTVs from the parameters of the saved model instead of fitting them,
+// keys which are not in the model fail
+type SavedModel struct {
+	Model     *Model
+	KeepGoing bool
+}
+
+func (sm SavedModel) Predict(ctx context.Context, al aggregator.AggregatedLTVsByKey, predictionLength int64) (PredictedLTVs, error) {
+	if predictionLength < 3 {
+		return nil, fmt.Errorf(ErrPredictorError.Error(), ErrPredictLengthTooShort)
+	}
+	result, err := forEachKey(ctx, al, 1, sm.KeepGoing, func(key string, _ []decimal.Decimal) (decimal.Decimal, error) {
+		params, ok := sm.Model.Params[key]
+		if !ok {
+			return decimal.Decimal{}, ErrKeyNotFitted
+		}
+		return params.At(predictionLength), nil
+	})
+	return PredictedLTVs(result), err
+}
+
+// Score compares the curve of the saved parameters with the observed LTVs of a key
+type Score struct {
+	Key  string
+	Days int
+	// Observed and Predicted are the LTVs of the last observed day
+	Observed  decimal.Decimal
+	Predicted decimal.Decimal
+	// MAE is the mean absolute error over all the observed days
+	MAE decimal.Decimal
+}
+
+// Score compares the saved curves with the observed LTVs of every key, keys which are not in the model
+// are reported as failures. Scores are sorted by key
+func (m *Model) Score(ctx context.Context, al aggregator.AggregatedLTVsByKey) ([]Score, Failures, error) {
+	scores, err := forEachKey(ctx, al, 1, true, func(key string, data []decimal.Decimal) (Score, error) {
+		params, ok := m.Params[key]
+		if !ok {
+			return Score{}, ErrKeyNotFitted
+		}
+		if len(data) == 0 {
+			return Score{}, ErrNotEnoughData
+		}
+		score := Score{Key: key, Days: len(data), Observed: data[len(data)-1], Predicted: params.At(int64(len(data)))}
+		for i, observed := range data {
+			score.MAE = score.MAE.Add(params.At(int64(i + 1)).Sub(observed).Abs())
+		}
+		score.MAE = score.MAE.Div(decimal.NewFromInt(int64(len(data))))
+		return score, nil
+	})
+	var partialErr *PartialError
+	if err != nil && !errors.As(err, &partialErr) {
+		return nil, nil, err
+	}
+	result := make([]Score, 0, len(scores))
+	for _, score := range scores {
+		result = append(result, score)
+	}
+	slices.SortFunc(result, func(a, b Score) int { return strings.Compare(a.Key, b.Key) })
+	if partialErr != nil {
+		return result, partialErr.Failures, nil
+	}
+	return result, nil, nil
+}
@@ -0,0 +1,125 @@
+package predictor
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParams_At(t *testing.T) {
+	params := Params{Coefficients: []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(2), decimal.NewFromInt(3)}}
+
+	// 1 + 2*x + 3*x^2 for x = day - 1
+	assert.True(t, decimal.NewFromInt(1).Equal(params.At(1)))
+	assert.True(t, decimal.NewFromInt(17).Equal(params.At(3)))
+	assert.True(t, decimal.Zero.Equal(Params{}.At(10)))
+}
+
+func TestFitter_Fit(t *testing.T) {
+	al := createTestLTVs(20)
+	for _, fitter := range []Fitter{LinearExtrapolator{}, LinearRegressor{Workers: 4}} {
+		params, err := fitter.Fit(context.Background(), al)
+		assert.NoError(t, err)
+
+		// predictions of the saved parameters are the same as the ones of the fitter
+		expected, err := fitter.Predict(context.Background(), al, 60)
+		assert.NoError(t, err)
+		result, err := SavedModel{Model: &Model{Params: params}}.Predict(context.Background(), al, 60)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	}
+}
+
+func TestLinearRegressor_Fit(t *testing.T) {
+	al := aggregator.AggregatedLTVsByKey{"US": decimals(1, 3, 5, 7, 7)}
+
+	params, err := LinearRegressor{}.Fit(context.Background(), al)
+
+	assert.NoError(t, err)
+	assert.Len(t, params["US"].Coefficients, 2)
+	assert.True(t, decimal.NewFromInt(1).Equal(params["US"].Coefficients[0]))
+	assert.True(t, decimal.NewFromInt(2).Equal(params["US"].Coefficients[1]))
+	assert.Equal(t, 5, params["US"].Days)
+}
+
+func TestModel_WriteRead(t *testing.T) {
+	params, err := LinearExtrapolator{}.Fit(context.Background(), aggregator.AggregatedLTVsByKey{"US": decimals(1, 2, 4)})
+	assert.NoError(t, err)
+	model := Model{
+		Version:     ModelVersion,
+		Model:       "linearExtrapolation",
+		Aggregation: "country",
+		Source:      "data.csv",
+		FittedAt:    time.Date(2023, 11, 5, 10, 0, 0, 0, time.UTC),
+		Params:      params,
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteModel(&buf, model))
+	assert.JSONEq(t, `{"version": 1, "model": "linearExtrapolation", "aggregation": "country", "source": "data.csv",
+		"fittedAt": "2023-11-05T10:00:00Z", "params": {"US": {"coefficients": ["0", "2"], "days": 3}}}`, buf.String())
+
+	result, err := ReadModel(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "linearExtrapolation", result.Model)
+	assert.True(t, decimal.NewFromInt(20).Equal(result.Params["US"].At(11)))
+	assert.NoError(t, result.CheckAggregation("country"))
+	assert.EqualError(t, result.CheckAggregation("campaign"), "model error: model was fitted on keys aggregated by country, not by campaign")
+}
+
+func TestReadModel_UnsupportedVersion(t *testing.T) {
+	_, err := ReadModel(strings.NewReader(`{"version": 2}`))
+
+	assert.EqualError(t, err, "model error: unsupported model version(2), supported version is 1")
+}
+
+func TestSavedModel_Predict_KeyNotFitted(t *testing.T) {
+	model := &Model{Params: ParamsByKey{"US": {Coefficients: decimals(1, 1)}}}
+	al := aggregator.AggregatedLTVsByKey{"US": nil, "DE": nil}
+
+	_, err := SavedModel{Model: model}.Predict(context.Background(), al, 60)
+	assert.EqualError(t, err, "predictor error: DE: key was not fitted")
+
+	result, err := SavedModel{Model: model, KeepGoing: true}.Predict(context.Background(), al, 60)
+	assert.Equal(t, &PartialError{Failures: Failures{"DE": ErrKeyNotFitted}}, err)
+	assert.True(t, decimal.NewFromInt(60).Equal(result["US"]))
+}
+
+func TestModel_Score(t *testing.T) {
+	model := &Model{Params: ParamsByKey{
+		"US": {Coefficients: decimals(1, 1)},
+		"DE": {Coefficients: decimals(2, 2)},
+	}}
+	al := aggregator.AggregatedLTVsByKey{
+		"US": decimals(1, 2, 3, 4),
+		// the curve is 2 4 6 8, so the errors are 0 1 2 3
+		"DE": decimals(2, 3, 4, 5),
+		"FR": decimals(1, 2),
+	}
+
+	scores, failures, err := model.Score(context.Background(), al)
+
+	assert.NoError(t, err)
+	assert.Equal(t, Failures{"FR": ErrKeyNotFitted}, failures)
+	assert.Len(t, scores, 2)
+	assert.Equal(t, "DE", scores[0].Key)
+	assert.Equal(t, 4, scores[0].Days)
+	assert.True(t, decimal.NewFromInt(5).Equal(scores[0].Observed))
+	assert.True(t, decimal.NewFromInt(8).Equal(scores[0].Predicted))
+	assert.True(t, decimal.NewFromFloat(1.5).Equal(scores[0].MAE))
+	assert.True(t, decimal.Zero.Equal(scores[1].MAE))
+}
+
+func decimals(values ...int64) []decimal.Decimal {
+	result := make([]decimal.Decimal, 0, len(values))
+	for _, v := range values {
+		result = append(result, decimal.NewFromInt(v))
+	}
+	return result
+}
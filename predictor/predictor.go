@@ -1,7 +1,9 @@
 package predictor
 
 import (
+	"context"
 	"errors"
+	"fmt"
 
 	"github.com/pklimuk/ltv-predictor/aggregator"
 	"github.com/shopspring/decimal"
@@ -11,10 +13,25 @@ var (
 	ErrPredictorError        = errors.New("predictor error: %w")
 	ErrNotEnoughData         = errors.New("not enough data to make prediction")
 	ErrPredictLengthTooShort = errors.New("prediction length should be greater than 2")
+	ErrKeyError              = errors.New("%s: %w")
+	ErrCantFitLine           = errors.New("line could not be fitted to the LTVs")
 )
 
 type PredictedLTVs map[string]decimal.Decimal
 
 type Predictor interface {
-	Predict(al aggregator.AggregatedLTVsByKey, predictionLength int64) (PredictedLTVs, error)
+	Predict(ctx context.Context, al aggregator.AggregatedLTVsByKey, predictionLength int64) (PredictedLTVs, error)
+}
+
+// Failures contains the reasons of failed predictions by key
+type Failures map[string]error
+
+// PartialError is returned in the keep going mode if predictions of some keys failed,
+// predictions of the other keys are returned together with it
+type PartialError struct {
+	Failures Failures
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("prediction failed for %d key(s)", len(e.Failures))
 }
@@ -0,0 +1,96 @@
+package predictor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/shopspring/decimal"
+)
+
+// predictKeyFunc predicts the LTV of a single key
+type predictKeyFunc func(data []decimal.Decimal) (*decimal.Decimal, error)
+
+// predictKeys calls predict for every key of the aggregated LTVs, see forEachKey
+func predictKeys(ctx context.Context, al aggregator.AggregatedLTVsByKey, workers int, keepGoing bool, predict predictKeyFunc) (PredictedLTVs, error) {
+	result, err := forEachKey(ctx, al, workers, keepGoing, func(_ string, data []decimal.Decimal) (decimal.Decimal, error) {
+		prediction, err := predict(data)
+		if err != nil {
+			return decimal.Decimal{}, err
+		}
+		return *prediction, nil
+	})
+	return PredictedLTVs(result), err
+}
+
+// forEachKey calls f for every key of the aggregated LTVs using up to workers goroutines,
+// keys are processed sequentially if workers is less than 2. Keys are taken in the sorted order and
+// errors of all failed keys are returned in the same order prefixed with their keys, so the result does not depend on scheduling.
+// In the keep going mode results of the succeeded keys are returned together with PartialError,
+// the run still fails if no key succeeded
+func forEachKey[T any](ctx context.Context, al aggregator.AggregatedLTVsByKey, workers int, keepGoing bool,
+	f func(key string, data []decimal.Decimal) (T, error)) (map[string]T, error) {
+	keys := make([]string, 0, len(al))
+	for k := range al {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	results := make([]T, len(keys))
+	errs := make([]error, len(keys))
+	work := func(i int) {
+		if ctx.Err() != nil {
+			return
+		}
+		results[i], errs[i] = f(keys[i], al[keys[i]])
+	}
+
+	if workers < 2 {
+		for i := range keys {
+			work(i)
+		}
+	} else {
+		indexes := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < min(workers, len(keys)); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					work(i)
+				}
+			}()
+		}
+		for i := range keys {
+			indexes <- i
+		}
+		close(indexes)
+		wg.Wait()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf(ErrPredictorError.Error(), err)
+	}
+	failures := make(Failures)
+	result := make(map[string]T, len(keys))
+	// errors are named by their keys, so a failed run tells which keys could not be predicted
+	var keyErrs []error
+	for i, k := range keys {
+		if errs[i] != nil {
+			failures[k] = errs[i]
+			keyErrs = append(keyErrs, fmt.Errorf(ErrKeyError.Error(), k, errs[i]))
+			continue
+		}
+		result[k] = results[i]
+	}
+	if len(failures) == 0 {
+		return result, nil
+	}
+	if keepGoing && len(result) > 0 {
+		return result, &PartialError{Failures: failures}
+	}
+	return nil, fmt.Errorf(ErrPredictorError.Error(), errors.Join(keyErrs...))
+}
@@ -0,0 +1,121 @@
+package predictor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestLTVs(keysCount int) aggregator.AggregatedLTVsByKey {
+	al := make(aggregator.AggregatedLTVsByKey, keysCount)
+	for i := 0; i < keysCount; i++ {
+		al[fmt.Sprintf("key%d", i)] = []decimal.Decimal{
+			decimal.NewFromInt(int64(i + 1)),
+			decimal.NewFromInt(int64(2*i + 3)),
+			decimal.NewFromInt(int64(3*i + 4)),
+		}
+	}
+	return al
+}
+
+func TestPredictKeys_WorkersGiveSameResult(t *testing.T) {
+	al := createTestLTVs(100)
+	predictors := []struct {
+		name       string
+		sequential Predictor
+		concurrent Predictor
+	}{
+		{"linearExtrapolation", LinearExtrapolator{}, LinearExtrapolator{Workers: 8}},
+		{"linearRegression", LinearRegressor{}, LinearRegressor{Workers: 8}},
+	}
+
+	for _, p := range predictors {
+		t.Run(p.name, func(t *testing.T) {
+			expected, err := p.sequential.Predict(context.Background(), al, 60)
+			assert.NoError(t, err)
+
+			result, err := p.concurrent.Predict(context.Background(), al, 60)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestPredictKeys_CollectsErrorsInKeyOrder(t *testing.T) {
+	al := createTestLTVs(20)
+	errFirst := errors.New("first failed")
+	errSecond := errors.New("second failed")
+	predict := func(data []decimal.Decimal) (*decimal.Decimal, error) {
+		switch {
+		case data[0].Equal(decimal.NewFromInt(4)):
+			return nil, errFirst
+		case data[0].Equal(decimal.NewFromInt(13)):
+			return nil, errSecond
+		}
+		return &data[0], nil
+	}
+
+	for _, workers := range []int{1, 4} {
+		result, err := predictKeys(context.Background(), al, workers, false, predict)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, errFirst)
+		assert.ErrorIs(t, err, errSecond)
+		// key12 goes before key3 in the sorted order
+		assert.EqualError(t, err, "predictor error: key12: second failed\nkey3: first failed")
+	}
+}
+
+func TestPredictKeys_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := predictKeys(ctx, createTestLTVs(10), 4, false, func(data []decimal.Decimal) (*decimal.Decimal, error) {
+		return &data[0], nil
+	})
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "predictor error: context canceled")
+}
+
+func BenchmarkLinearRegressor_Predict(b *testing.B) {
+	al := createTestLTVs(10000)
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			lr := LinearRegressor{Workers: workers}
+			for i := 0; i < b.N; i++ {
+				_, _ = lr.Predict(context.Background(), al, 60)
+			}
+		})
+	}
+}
+
+func TestPredictKeys_KeepGoing(t *testing.T) {
+	al := createTestLTVs(5)
+	al["empty"] = nil
+
+	for _, p := range []Predictor{LinearExtrapolator{KeepGoing: true}, LinearRegressor{KeepGoing: true, Workers: 4}} {
+		result, err := p.Predict(context.Background(), al, 60)
+
+		var partialErr *PartialError
+		assert.ErrorAs(t, err, &partialErr)
+		assert.EqualError(t, err, "prediction failed for 1 key(s)")
+		assert.Equal(t, Failures{"empty": ErrNotEnoughData}, partialErr.Failures)
+		assert.Len(t, result, 5)
+		assert.NotContains(t, result, "empty")
+	}
+}
+
+func TestPredictKeys_KeepGoingAllFailed(t *testing.T) {
+	al := aggregator.AggregatedLTVsByKey{"empty": nil}
+
+	result, err := LinearExtrapolator{KeepGoing: true}.Predict(context.Background(), al, 60)
+
+	assert.Nil(t, result)
+	assert.EqualError(t, err, "predictor error: empty: not enough data to make prediction")
+}
@@ -1,37 +1,309 @@
 package processor
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
 	"github.com/pklimuk/ltv-predictor/aggregator"
 	"github.com/pklimuk/ltv-predictor/fileParser"
 	"github.com/pklimuk/ltv-predictor/outputPrinter"
 	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/pklimuk/ltv-predictor/roas"
 )
 
+// now is used to set the time of the report, could be replaced in tests
+var now = time.Now
+
+// streamBuffer is the number of records which could wait for the next stage of the streaming aggregation
+const streamBuffer = 1024
+
+// Recorder saves the printed reports, e.g. to the history of predictions
+type Recorder interface {
+	Record(report outputPrinter.Report) error
+}
+
+// Detector finds values of the report which deviate from the expected ones, e.g. from the previous runs
+type Detector interface {
+	Detect(report outputPrinter.Report) ([]outputPrinter.Alert, error)
+}
+
+// Notifier sends the summary of the printed reports, e.g. to a webhook
+type Notifier interface {
+	Notify(ctx context.Context, report outputPrinter.Report) error
+}
+
+// CurrencyConverter is implemented by the parsers converting the revenues into one currency, the records they
+// skip because their currency has no rate are added to the data quality
+type CurrencyConverter interface {
+	UnknownCurrencies() map[string]int
+}
+
+// StreamModel is the model a revenue stream is predicted with
+type StreamModel struct {
+	Model     string
+	Predictor predictor.Predictor
+}
+
+// StreamModels are the models of the revenue streams by name, streams without their own model are predicted by Default
+type StreamModels struct {
+	Default StreamModel
+	ByName  map[string]StreamModel
+}
+
+// For returns the model of the stream
+func (m StreamModels) For(name string) StreamModel {
+	if model, ok := m.ByName[name]; ok {
+		return model
+	}
+	return m.Default
+}
+
+var (
+	ErrStreamError = errors.New("stream %s: %w")
+)
+
+// AlertsError is returned together with the report if the detector raised alerts
+type AlertsError struct {
+	Alerts []outputPrinter.Alert
+}
+
+func (e *AlertsError) Error() string {
+	return fmt.Sprintf("%d alert(s) raised", len(e.Alerts))
+}
+
 type Processor struct {
 	Parser           fileParser.FileParser
 	Aggregator       aggregator.Aggregator
+	Filter           aggregator.Filter
 	Predictor        predictor.Predictor
+	Model            string
+	AggregateBy      string
+	Source           string
 	PredictionLength int64
 	OutputPrinter    outputPrinter.OutputPrinter
+	OutputPath       string
+	// Recorder is optional, reports are not saved if it is nil
+	Recorder Recorder
+	// Detector is optional, alerts are not checked if it is nil
+	Detector Detector
+	// Notifier is optional, nobody is notified if it is nil
+	Notifier Notifier
+	// Spend is optional, the returns on it are added to the report if it is set
+	Spend roas.SpendByKey
+	// Streams is optional, the revenue streams of the records are predicted separately from the total if it is set
+	Streams *StreamModels
 }
 
-func (p *Processor) Process() error {
-	data, err := p.Parser.Parse()
+// Process runs the pipeline, prints the report, records it and notifies about it if the recorder and the notifier
+// are set. If predictions of some keys failed in the keep going mode or alerts were raised the report is printed
+// and the errors of Run are returned, failed notifications of such runs are logged
+func (p *Processor) Process(ctx context.Context) error {
+	report, runErr := p.Run(ctx)
+	if report == nil {
+		return runErr
+	}
+	// nothing is printed if the run was cancelled after the last stage
+	err := ctx.Err()
 	if err != nil {
 		return err
 	}
-	aggregatedRevenues, err := p.Aggregator.AggregateRevenues(data)
+	err = outputPrinter.Print(p.OutputPrinter, p.OutputPath, *report)
 	if err != nil {
 		return err
 	}
+	if p.Recorder != nil {
+		err = p.Recorder.Record(*report)
+		if err != nil {
+			return err
+		}
+	}
+	if p.Notifier != nil {
+		err = p.Notifier.Notify(ctx, *report)
+		if err != nil && runErr == nil {
+			return err
+		}
+		// the report is already written, so the errors of the run are returned and the notification error is only logged
+		if err != nil {
+			log.Printf("The notification could not be sent:\n\t%s", strings.ReplaceAll(err.Error(), "\n", "\n\t"))
+		}
+	}
+	return runErr
+}
+
+// Run parses, aggregates and predicts the data and returns the report without printing it. If predictions
+// of some keys or streams failed in the keep going mode the report contains the failures and is returned together
+// with *predictor.PartialError, if the detector raised alerts the report contains them and is returned
+// together with *AlertsError
+func (p *Processor) Run(ctx context.Context) (*outputPrinter.Report, error) {
+	aggregatedRevenues, aggregatedLTVs, quality, err := p.aggregate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	predictions, err := p.Predictor.Predict(ctx, aggregatedLTVs, p.PredictionLength)
+	var partialErr *predictor.PartialError
+	if err != nil && !errors.As(err, &partialErr) {
+		return nil, err
+	}
+	report := &outputPrinter.Report{
+		Model:            p.Model,
+		Aggregation:      p.AggregateBy,
+		Source:           p.Source,
+		GeneratedAt:      now(),
+		PredictionLength: p.PredictionLength,
+		Predictions:      predictions,
+		Revenues:         aggregatedRevenues,
+		LTVs:             aggregatedLTVs,
+		Quality:          quality,
+	}
+	if p.Spend != nil {
+		report.ROAS = roas.Compute(p.Spend, predictions, aggregatedLTVs, p.PredictionLength)
+	}
+	if p.Streams != nil {
+		report.Streams, err = p.predictStreams(ctx, aggregatedRevenues)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var errs []error
+	if partialErr != nil {
+		report.Failures = partialErr.Failures
+	}
+	// the error of the predictor is returned as it is unless some streams failed as well
+	if failures := failedKeysAndStreams(*report); len(failures) > len(report.Failures) {
+		errs = append(errs, &predictor.PartialError{Failures: failures})
+	} else if partialErr != nil {
+		errs = append(errs, partialErr)
+	}
+	if p.Detector != nil {
+		report.Alerts, err = p.Detector.Detect(*report)
+		if err != nil {
+			return nil, err
+		}
+		if len(report.Alerts) > 0 {
+			errs = append(errs, &AlertsError{Alerts: report.Alerts})
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// predictStreams predicts every revenue stream of the aggregated revenues with the model of the stream
+func (p *Processor) predictStreams(ctx context.Context, ar aggregator.AggregatedRevenuesByKey) (map[string]outputPrinter.StreamReport, error) {
+	names := ar.Streams()
+	if len(names) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]outputPrinter.StreamReport, len(names))
+	for _, name := range names {
+		revenues := ar.Stream(name)
+		ltvs, err := p.Aggregator.ConvertAggregatedByKeyRevenuesToLTVs(revenues)
+		if err != nil {
+			return nil, fmt.Errorf(ErrStreamError.Error(), name, err)
+		}
+		model := p.Streams.For(name)
+		predictions, err := model.Predictor.Predict(ctx, ltvs, p.PredictionLength)
+		var partialErr *predictor.PartialError
+		if err != nil && !errors.As(err, &partialErr) {
+			return nil, fmt.Errorf(ErrStreamError.Error(), name, err)
+		}
+		stream := outputPrinter.StreamReport{Model: model.Model, Predictions: predictions, Revenues: revenues, LTVs: ltvs}
+		if partialErr != nil {
+			stream.Failures = partialErr.Failures
+		}
+		result[name] = stream
+	}
+	return result, nil
+}
+
+// failedKeysAndStreams joins the failures of the total and the streams, failed streams are named as "key (stream)"
+func failedKeysAndStreams(report outputPrinter.Report) predictor.Failures {
+	failures := make(predictor.Failures, len(report.Failures))
+	for k, err := range report.Failures {
+		failures[k] = err
+	}
+	for name, stream := range report.Streams {
+		for k, err := range stream.Failures {
+			failures[fmt.Sprintf("%s (%s)", k, name)] = err
+		}
+	}
+	return failures
+}
+
+// Aggregate runs only the parse and aggregate stages of the pipeline
+func (p *Processor) Aggregate(ctx context.Context) (aggregator.AggregatedRevenuesByKey, aggregator.AggregatedLTVsByKey, error) {
+	aggregatedRevenues, aggregatedLTVs, _, err := p.aggregate(ctx)
+	return aggregatedRevenues, aggregatedLTVs, err
+}
+
+// aggregate runs the parse and aggregate stages and counts the data excluded by the filter
+func (p *Processor) aggregate(ctx context.Context) (aggregator.AggregatedRevenuesByKey, aggregator.AggregatedLTVsByKey, outputPrinter.DataQuality, error) {
+	var quality outputPrinter.DataQuality
+	var aggregatedRevenues aggregator.AggregatedRevenuesByKey
+	var err error
+	streamParser, canStream := p.Parser.(fileParser.StreamParser)
+	streamAggregator, canAggregateStream := p.Aggregator.(aggregator.StreamAggregator)
+	if canStream && canAggregateStream {
+		aggregatedRevenues, err = p.aggregateStream(ctx, streamParser, streamAggregator, &quality)
+	} else {
+		aggregatedRevenues, err = p.aggregateRecords(ctx, &quality)
+	}
+	if err != nil {
+		return nil, nil, quality, err
+	}
+	// parsing is finished, so the counts of the converter are complete
+	if converter, ok := p.Parser.(CurrencyConverter); ok && len(converter.UnknownCurrencies()) > 0 {
+		quality.UnknownCurrencies = converter.UnknownCurrencies()
+	}
+	keys := len(aggregatedRevenues)
+	aggregatedRevenues = p.Filter.Keys(aggregatedRevenues)
+	quality.FilteredKeys = keys - len(aggregatedRevenues)
 	aggregatedLTVs, err := p.Aggregator.ConvertAggregatedByKeyRevenuesToLTVs(aggregatedRevenues)
 	if err != nil {
-		return err
+		return nil, nil, quality, err
 	}
-	predictions, err := p.Predictor.Predict(aggregatedLTVs, p.PredictionLength)
+	return aggregatedRevenues, aggregatedLTVs, quality, nil
+}
+
+// aggregateRecords parses all the records before aggregating them
+func (p *Processor) aggregateRecords(ctx context.Context, quality *outputPrinter.DataQuality) (aggregator.AggregatedRevenuesByKey, error) {
+	data, err := p.Parser.Parse(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	records := p.Filter.Records(data)
+	quality.Records = len(data)
+	quality.FilteredRecords = len(data) - len(records)
+	return p.Aggregator.AggregateRevenues(ctx, records)
+}
+
+// aggregateStream aggregates the records as they are parsed, so the whole input is never kept in memory.
+// Errors of the parser are reported before the errors of the aggregator, which fails if the parser sends nothing
+func (p *Processor) aggregateStream(ctx context.Context, sp fileParser.StreamParser, sa aggregator.StreamAggregator,
+	quality *outputPrinter.DataQuality) (aggregator.AggregatedRevenuesByKey, error) {
+	parsed := make(chan fileParser.Revenues, streamBuffer)
+	selected := make(chan fileParser.Revenues, streamBuffer)
+	parseErr := make(chan error, 1)
+	go func() {
+		parseErr <- sp.ParseStream(ctx, parsed)
+	}()
+	// the counts are written before selected is closed, so they are complete once the aggregator returns
+	go func() {
+		defer close(selected)
+		for rec := range parsed {
+			quality.Records++
+			if !p.Filter.Selects(rec) {
+				quality.FilteredRecords++
+				continue
+			}
+			selected <- rec
+		}
+	}()
+	aggregatedRevenues, err := sa.AggregateRevenuesStream(ctx, selected)
+	if pErr := <-parseErr; pErr != nil {
+		return nil, pErr
 	}
-	p.OutputPrinter.Print(predictions)
-	return nil
+	return aggregatedRevenues, err
 }
@@ -1,12 +1,21 @@
 package processor
 
 import (
+	"context"
 	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/currency"
 	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
 	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/pklimuk/ltv-predictor/roas"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -18,8 +27,8 @@ type MockAggregator struct {
 	mock.Mock
 }
 
-func (m *MockAggregator) AggregateRevenues(revenues []fileParser.Revenues) (aggregator.AggregatedRevenuesByKey, error) {
-	args := m.Called(revenues)
+func (m *MockAggregator) AggregateRevenues(ctx context.Context, revenues []fileParser.Revenues) (aggregator.AggregatedRevenuesByKey, error) {
+	args := m.Called(ctx, revenues)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -38,8 +47,8 @@ type MockPredictor struct {
 	mock.Mock
 }
 
-func (m *MockPredictor) Predict(al aggregator.AggregatedLTVsByKey, predictionLength int64) (predictor.PredictedLTVs, error) {
-	args := m.Called(al, predictionLength)
+func (m *MockPredictor) Predict(ctx context.Context, al aggregator.AggregatedLTVsByKey, predictionLength int64) (predictor.PredictedLTVs, error) {
+	args := m.Called(ctx, al, predictionLength)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -50,8 +59,8 @@ type MockParser struct {
 	mock.Mock
 }
 
-func (m *MockParser) Parse() ([]fileParser.Revenues, error) {
-	args := m.Called()
+func (m *MockParser) Parse(ctx context.Context) ([]fileParser.Revenues, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -62,12 +71,44 @@ type MockOutputPrinter struct {
 	mock.Mock
 }
 
-func (m *MockOutputPrinter) Print(data predictor.PredictedLTVs) {
-	m.Called(data)
+func (m *MockOutputPrinter) Print(w io.Writer, data outputPrinter.Report) error {
+	args := m.Called(w, data)
+	return args.Error(0)
+}
+
+type MockRecorder struct {
+	mock.Mock
+}
+
+func (m *MockRecorder) Record(report outputPrinter.Report) error {
+	args := m.Called(report)
+	return args.Error(0)
+}
+
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, report outputPrinter.Report) error {
+	args := m.Called(ctx, report)
+	return args.Error(0)
+}
+
+type MockDetector struct {
+	mock.Mock
+}
+
+func (m *MockDetector) Detect(report outputPrinter.Report) ([]outputPrinter.Alert, error) {
+	args := m.Called(report)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]outputPrinter.Alert), args.Error(1)
 }
 
 func TestProcessor_Process(t *testing.T) {
 	// Setup
+	ctx := context.Background()
 	mockParser := new(MockParser)
 	mockAggregator := new(MockAggregator)
 	mockPredictor := new(MockPredictor)
@@ -77,9 +118,15 @@ func TestProcessor_Process(t *testing.T) {
 		Parser:           mockParser,
 		Aggregator:       mockAggregator,
 		Predictor:        mockPredictor,
+		Model:            "linearExtrapolation",
+		AggregateBy:      "country",
+		Source:           "data.csv",
 		PredictionLength: 7,
 		OutputPrinter:    mockOutputPrinter,
 	}
+	generatedAt := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return generatedAt }
+	defer func() { now = time.Now }()
 
 	// Test data
 	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10), decimal.NewFromInt(20), decimal.NewFromInt(30)}, Country: "US", CampaignID: "123", UsersCount: 2}}
@@ -88,14 +135,24 @@ func TestProcessor_Process(t *testing.T) {
 	predictions := make(predictor.PredictedLTVs)
 
 	// Mock behavior
-	mockParser.On("Parse").Return(revenues, nil)
-	mockAggregator.On("AggregateRevenues", revenues).Return(aggregatedRevenues, nil)
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
 	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
-	mockPredictor.On("Predict", aggregatedLTVs, int64(7)).Return(predictions, nil)
-	mockOutputPrinter.On("Print", predictions).Return()
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Return(predictions, nil)
+	mockOutputPrinter.On("Print", os.Stdout, outputPrinter.Report{
+		Model:            "linearExtrapolation",
+		Aggregation:      "country",
+		Source:           "data.csv",
+		GeneratedAt:      generatedAt,
+		PredictionLength: 7,
+		Predictions:      predictions,
+		Revenues:         aggregatedRevenues,
+		LTVs:             aggregatedLTVs,
+		Quality:          outputPrinter.DataQuality{Records: 1},
+	}).Return(nil)
 
 	// Execute the method under test
-	err := p.Process()
+	err := p.Process(ctx)
 
 	// Assertions
 	assert.NoError(t, err)
@@ -107,6 +164,7 @@ func TestProcessor_Process(t *testing.T) {
 
 func TestProcessor_Process_ErrorInParser(t *testing.T) {
 	// Setup
+	ctx := context.Background()
 	mockParser := new(MockParser)
 	mockAggregator := new(MockAggregator)
 
@@ -119,10 +177,10 @@ func TestProcessor_Process_ErrorInParser(t *testing.T) {
 	}
 
 	// Mock behavior - Error in parser
-	mockParser.On("Parse").Return(nil, errors.New("error parsing"))
+	mockParser.On("Parse", ctx).Return(nil, errors.New("error parsing"))
 
 	// Execute the method under test
-	err := p.Process()
+	err := p.Process(ctx)
 
 	// Assertions
 	assert.Error(t, err)
@@ -133,6 +191,7 @@ func TestProcessor_Process_ErrorInParser(t *testing.T) {
 
 func TestProcessor_Process_ErrorInAggregator(t *testing.T) {
 	// Setup
+	ctx := context.Background()
 	mockParser := new(MockParser)
 	mockAggregator := new(MockAggregator)
 
@@ -148,11 +207,11 @@ func TestProcessor_Process_ErrorInAggregator(t *testing.T) {
 	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10), decimal.NewFromInt(20), decimal.NewFromInt(30)}, Country: "US", CampaignID: "123", UsersCount: 2}}
 
 	// Mock behavior - Error in aggregator
-	mockParser.On("Parse").Return(revenues, nil)
-	mockAggregator.On("AggregateRevenues", revenues).Return(nil, errors.New("error aggregating"))
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(nil, errors.New("error aggregating"))
 
 	// Execute the method under test
-	err := p.Process()
+	err := p.Process(ctx)
 
 	// Assertions
 	assert.Error(t, err)
@@ -163,6 +222,7 @@ func TestProcessor_Process_ErrorInAggregator(t *testing.T) {
 
 func TestProcessor_Process_ErrorInConvertAggregatedByKeyRevenuesToLTVs(t *testing.T) {
 	// Setup
+	ctx := context.Background()
 	mockParser := new(MockParser)
 	mockAggregator := new(MockAggregator)
 
@@ -179,12 +239,12 @@ func TestProcessor_Process_ErrorInConvertAggregatedByKeyRevenuesToLTVs(t *testin
 	aggregatedRevenues := make(aggregator.AggregatedRevenuesByKey)
 
 	// Mock behavior - Error in aggregator
-	mockParser.On("Parse").Return(revenues, nil)
-	mockAggregator.On("AggregateRevenues", revenues).Return(aggregatedRevenues, nil)
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
 	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(nil, errors.New("error converting"))
 
 	// Execute the method under test
-	err := p.Process()
+	err := p.Process(ctx)
 
 	// Assertions
 	assert.Error(t, err)
@@ -195,6 +255,7 @@ func TestProcessor_Process_ErrorInConvertAggregatedByKeyRevenuesToLTVs(t *testin
 
 func TestProcessor_Process_ErrorInPredictor(t *testing.T) {
 	// Setup
+	ctx := context.Background()
 	mockParser := new(MockParser)
 	mockAggregator := new(MockAggregator)
 	mockPredictor := new(MockPredictor)
@@ -213,13 +274,13 @@ func TestProcessor_Process_ErrorInPredictor(t *testing.T) {
 	aggregatedLTVs := make(aggregator.AggregatedLTVsByKey)
 
 	// Mock behavior - Error in aggregator
-	mockParser.On("Parse").Return(revenues, nil)
-	mockAggregator.On("AggregateRevenues", revenues).Return(aggregatedRevenues, nil)
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
 	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
-	mockPredictor.On("Predict", aggregatedLTVs, int64(7)).Return(nil, errors.New("error predicting"))
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Return(nil, errors.New("error predicting"))
 
 	// Execute the method under test
-	err := p.Process()
+	err := p.Process(ctx)
 
 	// Assertions
 	assert.Error(t, err)
@@ -228,3 +289,572 @@ func TestProcessor_Process_ErrorInPredictor(t *testing.T) {
 	mockAggregator.AssertExpectations(t)
 	mockPredictor.AssertExpectations(t)
 }
+
+func TestProcessor_Process_ErrorInOutputPrinter(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	mockParser := new(MockParser)
+	mockAggregator := new(MockAggregator)
+	mockPredictor := new(MockPredictor)
+	mockOutputPrinter := new(MockOutputPrinter)
+
+	p := Processor{
+		Parser:           mockParser,
+		Aggregator:       mockAggregator,
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+		OutputPrinter:    mockOutputPrinter,
+		OutputPath:       filepath.Join(t.TempDir(), "output.csv"),
+	}
+
+	// Test data
+	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10), decimal.NewFromInt(20), decimal.NewFromInt(30)}, Country: "US", CampaignID: "123", UsersCount: 2}}
+	aggregatedRevenues := make(aggregator.AggregatedRevenuesByKey)
+	aggregatedLTVs := make(aggregator.AggregatedLTVsByKey)
+	predictions := make(predictor.PredictedLTVs)
+
+	// Mock behavior - Error in output printer
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Return(predictions, nil)
+	mockOutputPrinter.On("Print", mock.Anything, mock.Anything).Return(errors.New("error printing"))
+
+	// Execute the method under test
+	err := p.Process(ctx)
+
+	// Assertions
+	assert.Error(t, err)
+	assert.EqualError(t, err, "output error: error printing")
+	_, statErr := os.Stat(p.OutputPath)
+	assert.True(t, os.IsNotExist(statErr))
+	mockOutputPrinter.AssertExpectations(t)
+}
+
+func TestProcessor_Process_ContextCancelledBeforePrinting(t *testing.T) {
+	// Setup
+	ctx, cancel := context.WithCancel(context.Background())
+	mockParser := new(MockParser)
+	mockAggregator := new(MockAggregator)
+	mockPredictor := new(MockPredictor)
+	mockOutputPrinter := new(MockOutputPrinter)
+
+	p := Processor{
+		Parser:           mockParser,
+		Aggregator:       mockAggregator,
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+		OutputPrinter:    mockOutputPrinter,
+	}
+
+	// Test data
+	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10), decimal.NewFromInt(20), decimal.NewFromInt(30)}, Country: "US", CampaignID: "123", UsersCount: 2}}
+	aggregatedRevenues := make(aggregator.AggregatedRevenuesByKey)
+	aggregatedLTVs := make(aggregator.AggregatedLTVsByKey)
+	predictions := make(predictor.PredictedLTVs)
+
+	// Mock behavior - the run is cancelled while predicting
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Run(func(args mock.Arguments) { cancel() }).Return(predictions, nil)
+
+	// Execute the method under test
+	err := p.Process(ctx)
+
+	// Assertions
+	assert.ErrorIs(t, err, context.Canceled)
+	mockOutputPrinter.AssertNotCalled(t, "Print", mock.Anything, mock.Anything)
+}
+
+func TestProcessor_Process_PartialPrediction(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	mockParser := new(MockParser)
+	mockAggregator := new(MockAggregator)
+	mockPredictor := new(MockPredictor)
+	mockOutputPrinter := new(MockOutputPrinter)
+
+	p := Processor{
+		Parser:           mockParser,
+		Aggregator:       mockAggregator,
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+		OutputPrinter:    mockOutputPrinter,
+	}
+
+	// Test data
+	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10), decimal.NewFromInt(20), decimal.NewFromInt(30)}, Country: "US", CampaignID: "123", UsersCount: 2}}
+	aggregatedRevenues := make(aggregator.AggregatedRevenuesByKey)
+	aggregatedLTVs := make(aggregator.AggregatedLTVsByKey)
+	predictions := predictor.PredictedLTVs{"US": decimal.NewFromInt(10)}
+	partialErr := &predictor.PartialError{Failures: predictor.Failures{"DE": predictor.ErrNotEnoughData}}
+
+	// Mock behavior
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Return(predictions, partialErr)
+	mockOutputPrinter.On("Print", os.Stdout, mock.MatchedBy(func(r outputPrinter.Report) bool {
+		return len(r.Predictions) == 1 && r.Failures["DE"] == predictor.ErrNotEnoughData
+	})).Return(nil)
+
+	// Execute the method under test
+	err := p.Process(ctx)
+
+	// Assertions
+	assert.ErrorIs(t, err, partialErr)
+	mockOutputPrinter.AssertExpectations(t)
+}
+
+func TestProcessor_Process_Recorder(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	mockParser := new(MockParser)
+	mockAggregator := new(MockAggregator)
+	mockPredictor := new(MockPredictor)
+	mockOutputPrinter := new(MockOutputPrinter)
+	mockRecorder := new(MockRecorder)
+
+	p := Processor{
+		Parser:           mockParser,
+		Aggregator:       mockAggregator,
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+		OutputPrinter:    mockOutputPrinter,
+		Recorder:         mockRecorder,
+	}
+
+	// Test data
+	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10)}, Country: "US", CampaignID: "123", UsersCount: 2}}
+	aggregatedRevenues := make(aggregator.AggregatedRevenuesByKey)
+	aggregatedLTVs := make(aggregator.AggregatedLTVsByKey)
+	predictions := predictor.PredictedLTVs{"US": decimal.NewFromInt(10)}
+	isReport := mock.MatchedBy(func(r outputPrinter.Report) bool {
+		return len(r.Predictions) == 1
+	})
+
+	// Mock behavior
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Return(predictions, nil)
+	mockOutputPrinter.On("Print", os.Stdout, isReport).Return(nil)
+	mockRecorder.On("Record", isReport).Return(errors.New("error recording"))
+
+	// Execute the method under test
+	err := p.Process(ctx)
+
+	// Assertions
+	assert.EqualError(t, err, "error recording")
+	mockOutputPrinter.AssertExpectations(t)
+	mockRecorder.AssertExpectations(t)
+}
+
+func TestProcessor_Process_Alerts(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	mockParser := new(MockParser)
+	mockAggregator := new(MockAggregator)
+	mockPredictor := new(MockPredictor)
+	mockOutputPrinter := new(MockOutputPrinter)
+	mockDetector := new(MockDetector)
+
+	p := Processor{
+		Parser:           mockParser,
+		Aggregator:       mockAggregator,
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+		OutputPrinter:    mockOutputPrinter,
+		Detector:         mockDetector,
+	}
+
+	// Test data
+	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10)}, Country: "US", CampaignID: "123", UsersCount: 2}}
+	aggregatedRevenues := make(aggregator.AggregatedRevenuesByKey)
+	aggregatedLTVs := make(aggregator.AggregatedLTVsByKey)
+	predictions := predictor.PredictedLTVs{"US": decimal.NewFromInt(10)}
+	partialErr := &predictor.PartialError{Failures: predictor.Failures{"DE": predictor.ErrNotEnoughData}}
+	alerts := []outputPrinter.Alert{{Key: "US", Metric: outputPrinter.AlertMetricPredicted}}
+
+	// Mock behavior
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Return(predictions, partialErr)
+	mockDetector.On("Detect", mock.MatchedBy(func(r outputPrinter.Report) bool {
+		return len(r.Predictions) == 1 && len(r.Failures) == 1
+	})).Return(alerts, nil)
+	mockOutputPrinter.On("Print", os.Stdout, mock.MatchedBy(func(r outputPrinter.Report) bool {
+		return len(r.Alerts) == 1
+	})).Return(nil)
+
+	// Execute the method under test
+	err := p.Process(ctx)
+
+	// Assertions
+	var alertsErr *AlertsError
+	assert.ErrorAs(t, err, &alertsErr)
+	assert.Equal(t, alerts, alertsErr.Alerts)
+	assert.ErrorIs(t, err, partialErr)
+	assert.EqualError(t, err, "prediction failed for 1 key(s)\n1 alert(s) raised")
+	mockOutputPrinter.AssertExpectations(t)
+	mockDetector.AssertExpectations(t)
+}
+
+func TestProcessor_Process_Notifier(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	mockParser := new(MockParser)
+	mockAggregator := new(MockAggregator)
+	mockPredictor := new(MockPredictor)
+	mockOutputPrinter := new(MockOutputPrinter)
+	mockRecorder := new(MockRecorder)
+	mockNotifier := new(MockNotifier)
+
+	p := Processor{
+		Parser:           mockParser,
+		Aggregator:       mockAggregator,
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+		OutputPrinter:    mockOutputPrinter,
+		Recorder:         mockRecorder,
+		Notifier:         mockNotifier,
+	}
+
+	// Test data
+	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10)}, Country: "US", CampaignID: "123", UsersCount: 2}}
+	aggregatedRevenues := make(aggregator.AggregatedRevenuesByKey)
+	aggregatedLTVs := make(aggregator.AggregatedLTVsByKey)
+	predictions := predictor.PredictedLTVs{"US": decimal.NewFromInt(10)}
+	partialErr := &predictor.PartialError{Failures: predictor.Failures{"DE": predictor.ErrNotEnoughData}}
+	isReport := mock.MatchedBy(func(r outputPrinter.Report) bool {
+		return len(r.Predictions) == 1 && len(r.Failures) == 1 && r.Quality.Records == 1
+	})
+
+	// Mock behavior
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Return(predictions, partialErr)
+	mockOutputPrinter.On("Print", os.Stdout, isReport).Return(nil)
+	mockRecorder.On("Record", isReport).Return(nil)
+	mockNotifier.On("Notify", ctx, isReport).Return(nil)
+
+	// Execute the method under test
+	err := p.Process(ctx)
+
+	// Assertions
+	assert.ErrorIs(t, err, partialErr)
+	mockRecorder.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestProcessor_Process_NotifierError(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	mockParser := new(MockParser)
+	mockAggregator := new(MockAggregator)
+	mockPredictor := new(MockPredictor)
+	mockOutputPrinter := new(MockOutputPrinter)
+	mockNotifier := new(MockNotifier)
+
+	p := Processor{
+		Parser:           mockParser,
+		Aggregator:       mockAggregator,
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+		OutputPrinter:    mockOutputPrinter,
+		Notifier:         mockNotifier,
+	}
+
+	// Test data
+	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10)}, Country: "US", CampaignID: "123", UsersCount: 2}}
+	aggregatedRevenues := make(aggregator.AggregatedRevenuesByKey)
+	aggregatedLTVs := make(aggregator.AggregatedLTVsByKey)
+	predictions := predictor.PredictedLTVs{"US": decimal.NewFromInt(10)}
+
+	// Mock behavior
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Return(predictions, nil)
+	mockOutputPrinter.On("Print", os.Stdout, mock.Anything).Return(nil)
+	mockNotifier.On("Notify", ctx, mock.Anything).Return(errors.New("error notifying"))
+
+	// Execute the method under test
+	err := p.Process(ctx)
+
+	// Assertions
+	assert.EqualError(t, err, "error notifying")
+	mockOutputPrinter.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestProcessor_Process_NotifierErrorOfPartialRun(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	mockParser := new(MockParser)
+	mockAggregator := new(MockAggregator)
+	mockPredictor := new(MockPredictor)
+	mockOutputPrinter := new(MockOutputPrinter)
+	mockNotifier := new(MockNotifier)
+
+	p := Processor{
+		Parser:           mockParser,
+		Aggregator:       mockAggregator,
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+		OutputPrinter:    mockOutputPrinter,
+		Notifier:         mockNotifier,
+	}
+
+	// Test data
+	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10)}, Country: "US", CampaignID: "123", UsersCount: 2}}
+	aggregatedRevenues := make(aggregator.AggregatedRevenuesByKey)
+	aggregatedLTVs := make(aggregator.AggregatedLTVsByKey)
+	predictions := predictor.PredictedLTVs{"US": decimal.NewFromInt(10)}
+	partialErr := &predictor.PartialError{Failures: predictor.Failures{"DE": predictor.ErrNotEnoughData}}
+
+	// Mock behavior
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Return(predictions, partialErr)
+	mockOutputPrinter.On("Print", os.Stdout, mock.Anything).Return(nil)
+	mockNotifier.On("Notify", ctx, mock.Anything).Return(errors.New("error notifying"))
+
+	// Execute the method under test
+	err := p.Process(ctx)
+
+	// Assertions
+	// the partial failure is returned, so the exit code of the run is kept
+	assert.ErrorIs(t, err, partialErr)
+	mockNotifier.AssertExpectations(t)
+}
+
+func TestProcessor_Run_Spend(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	mockParser := new(MockParser)
+	mockAggregator := new(MockAggregator)
+	mockPredictor := new(MockPredictor)
+
+	p := Processor{
+		Parser:           mockParser,
+		Aggregator:       mockAggregator,
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+		Spend:            roas.SpendByKey{"US": {Spend: decimal.NewFromInt(40), Installs: 10}},
+	}
+
+	// Test data
+	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10)}, Country: "US", CampaignID: "123", UsersCount: 2}}
+	aggregatedRevenues := make(aggregator.AggregatedRevenuesByKey)
+	aggregatedLTVs := aggregator.AggregatedLTVsByKey{"US": {decimal.NewFromInt(2)}, "DE": {decimal.NewFromInt(1)}}
+	predictions := predictor.PredictedLTVs{"US": decimal.NewFromInt(10), "DE": decimal.NewFromInt(5)}
+
+	// Mock behavior
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Return(predictions, nil)
+
+	// Execute the method under test
+	report, err := p.Run(ctx)
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Len(t, report.ROAS, 1)
+	assert.True(t, decimal.NewFromFloat(2.5).Equal(report.ROAS["US"].ROAS))
+	assert.Equal(t, int64(3), report.ROAS["US"].PaybackDay)
+}
+
+func TestProcessor_Run_Streams(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	mockParser := new(MockParser)
+	mockAggregator := new(MockAggregator)
+	mockPredictor := new(MockPredictor)
+	mockAdsPredictor := new(MockPredictor)
+
+	p := Processor{
+		Parser:           mockParser,
+		Aggregator:       mockAggregator,
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+		Streams: &StreamModels{
+			Default: StreamModel{Model: "default", Predictor: mockPredictor},
+			ByName:  map[string]StreamModel{"ads": {Model: "ads", Predictor: mockAdsPredictor}},
+		},
+	}
+
+	// Test data
+	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10)}, Country: "US", UsersCount: 2}}
+	adsRevenues := aggregator.AggregatedRevenuesByKey{"US": {Revenues: []decimal.Decimal{decimal.NewFromInt(4)}, UsersCount: 2}}
+	iapRevenues := aggregator.AggregatedRevenuesByKey{"US": {Revenues: []decimal.Decimal{decimal.NewFromInt(6)}, UsersCount: 1}}
+	aggregatedRevenues := aggregator.AggregatedRevenuesByKey{"US": {
+		Revenues:   []decimal.Decimal{decimal.NewFromInt(10)},
+		UsersCount: 2,
+		Streams:    map[string]aggregator.AggregatedRevenues{"ads": adsRevenues["US"], "iap": iapRevenues["US"]},
+	}}
+	aggregatedLTVs := aggregator.AggregatedLTVsByKey{"US": {decimal.NewFromInt(5)}}
+	adsLTVs := aggregator.AggregatedLTVsByKey{"US": {decimal.NewFromInt(2)}}
+	iapLTVs := aggregator.AggregatedLTVsByKey{"US": {decimal.NewFromInt(6)}}
+
+	// Mock behavior
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", adsRevenues).Return(adsLTVs, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", iapRevenues).Return(iapLTVs, nil)
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Return(predictor.PredictedLTVs{"US": decimal.NewFromInt(30)}, nil)
+	mockAdsPredictor.On("Predict", ctx, adsLTVs, int64(7)).Return(predictor.PredictedLTVs{"US": decimal.NewFromInt(12)}, nil)
+	mockPredictor.On("Predict", ctx, iapLTVs, int64(7)).
+		Return(predictor.PredictedLTVs{}, &predictor.PartialError{Failures: predictor.Failures{"US": predictor.ErrNotEnoughData}})
+
+	// Execute the method under test
+	report, err := p.Run(ctx)
+
+	// Assertions
+	var partialErr *predictor.PartialError
+	assert.ErrorAs(t, err, &partialErr)
+	assert.Equal(t, predictor.Failures{"US (iap)": predictor.ErrNotEnoughData}, partialErr.Failures)
+	assert.Empty(t, report.Failures)
+	assert.Equal(t, []string{"ads", "iap"}, report.StreamNames())
+	assert.Equal(t, outputPrinter.StreamReport{
+		Model:       "ads",
+		Predictions: predictor.PredictedLTVs{"US": decimal.NewFromInt(12)},
+		Revenues:    adsRevenues,
+		LTVs:        adsLTVs,
+	}, report.Streams["ads"])
+	assert.Equal(t, "default", report.Streams["iap"].Model)
+	assert.Equal(t, predictor.Failures{"US": predictor.ErrNotEnoughData}, report.Streams["iap"].Failures)
+	mockAggregator.AssertExpectations(t)
+	mockAdsPredictor.AssertExpectations(t)
+}
+
+func TestProcessor_Run_StreamError(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	mockParser := new(MockParser)
+	mockAggregator := new(MockAggregator)
+	mockPredictor := new(MockPredictor)
+
+	p := Processor{
+		Parser:           mockParser,
+		Aggregator:       mockAggregator,
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+		Streams:          &StreamModels{Default: StreamModel{Model: "default", Predictor: mockPredictor}},
+	}
+
+	// Test data
+	revenues := []fileParser.Revenues{{Revenues: []decimal.Decimal{decimal.NewFromInt(10)}, Country: "US", UsersCount: 2}}
+	adsRevenues := aggregator.AggregatedRevenuesByKey{"US": {Revenues: []decimal.Decimal{decimal.NewFromInt(4)}, UsersCount: 2}}
+	aggregatedRevenues := aggregator.AggregatedRevenuesByKey{"US": {
+		Revenues:   []decimal.Decimal{decimal.NewFromInt(10)},
+		UsersCount: 2,
+		Streams:    map[string]aggregator.AggregatedRevenues{"ads": adsRevenues["US"]},
+	}}
+	aggregatedLTVs := aggregator.AggregatedLTVsByKey{"US": {decimal.NewFromInt(5)}}
+	adsLTVs := aggregator.AggregatedLTVsByKey{"US": {decimal.NewFromInt(2)}}
+
+	// Mock behavior
+	mockParser.On("Parse", ctx).Return(revenues, nil)
+	mockAggregator.On("AggregateRevenues", ctx, revenues).Return(aggregatedRevenues, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", aggregatedRevenues).Return(aggregatedLTVs, nil)
+	mockAggregator.On("ConvertAggregatedByKeyRevenuesToLTVs", adsRevenues).Return(adsLTVs, nil)
+	mockPredictor.On("Predict", ctx, aggregatedLTVs, int64(7)).Return(predictor.PredictedLTVs{"US": decimal.NewFromInt(30)}, nil)
+	mockPredictor.On("Predict", ctx, adsLTVs, int64(7)).Return(nil, predictor.ErrNotEnoughData)
+
+	// Execute the method under test
+	report, err := p.Run(ctx)
+
+	// Assertions
+	assert.Nil(t, report)
+	assert.EqualError(t, err, "stream ads: not enough data to make prediction")
+	assert.ErrorIs(t, err, predictor.ErrNotEnoughData)
+}
+
+func TestProcessor_Run_StreamingAggregation(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	csv := "UserId,CampaignId,Country,Ltv1,Ltv2,Ltv3,Ltv4,Ltv5,Ltv6,Ltv7\n" +
+		"1,c1,US,1,2,3,4,5,6,7\n" +
+		"2,c1,US,3,4,5,6,7,8,9\n" +
+		"3,c2,DE,1,1,1,1,1,1,1\n" +
+		"4,c2,US,invalid,1,1,1,1,1,1\n"
+	mockPredictor := new(MockPredictor)
+	p := Processor{
+		// both the parser and the aggregator support streaming, so the records are aggregated as they are read
+		Parser:           fileParser.CSVParser{Reader: strings.NewReader(csv)},
+		Aggregator:       aggregator.ByCountryAggregator{Shards: 2},
+		Filter:           aggregator.Filter{Countries: []string{"US"}},
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+	}
+	expectedLTVs := aggregator.AggregatedLTVsByKey{"US": {decimal.NewFromInt(2), decimal.NewFromInt(3), decimal.NewFromInt(4),
+		decimal.NewFromInt(5), decimal.NewFromInt(6), decimal.NewFromInt(7), decimal.NewFromInt(8)}}
+
+	// Mock behavior
+	mockPredictor.On("Predict", ctx, mock.Anything, int64(7)).Return(predictor.PredictedLTVs{"US": decimal.NewFromInt(10)}, nil)
+
+	// Execute the method under test
+	report, err := p.Run(ctx)
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, outputPrinter.DataQuality{Records: 3, FilteredRecords: 1}, report.Quality)
+	assert.Equal(t, int64(2), report.Revenues["US"].UsersCount)
+	assert.Len(t, report.LTVs, 1)
+	for i, v := range expectedLTVs["US"] {
+		assert.True(t, v.Equal(report.LTVs["US"][i]))
+	}
+}
+
+func TestProcessor_Run_StreamingParserError(t *testing.T) {
+	// Setup
+	p := Processor{
+		Parser:           fileParser.CSVParser{Path: filepath.Join(t.TempDir(), "missing.csv")},
+		Aggregator:       aggregator.ByCountryAggregator{},
+		Predictor:        new(MockPredictor),
+		PredictionLength: 7,
+	}
+
+	// Execute the method under test
+	_, err := p.Run(context.Background())
+
+	// Assertions
+	// the error of the parser is reported instead of the aggregator having no data
+	assert.ErrorContains(t, err, "can't open specified file")
+}
+
+func TestProcessor_Run_UnknownCurrencies(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	csv := "UserId,CampaignId,Country,Ltv1,Ltv2,Ltv3,Ltv4,Ltv5,Ltv6,Ltv7,Currency\n" +
+		"1,c1,US,1,2,3,4,5,6,7,\n" +
+		"2,c1,GB,1,2,3,4,5,6,7,GBP\n" +
+		"3,c1,TR,1,2,3,4,5,6,7,TRY\n" +
+		"4,c1,TR,1,2,3,4,5,6,7,TRY\n"
+	rates, _ := currency.ReadRates(strings.NewReader("date,currency,rate\n2023-10-01,EUR,1.05\n"))
+	mockPredictor := new(MockPredictor)
+	p := Processor{
+		// the currency parser streams the records of the CSV parser, so the streaming aggregation is used
+		Parser:           &currency.Parser{Parser: fileParser.CSVParser{Reader: strings.NewReader(csv)}, Rates: rates, Target: "USD"},
+		Aggregator:       aggregator.ByCountryAggregator{},
+		Predictor:        mockPredictor,
+		PredictionLength: 7,
+	}
+
+	// Mock behavior
+	mockPredictor.On("Predict", ctx, mock.Anything, int64(7)).Return(predictor.PredictedLTVs{"US": decimal.NewFromInt(10)}, nil)
+
+	// Execute the method under test
+	report, err := p.Run(ctx)
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, outputPrinter.DataQuality{Records: 1, UnknownCurrencies: map[string]int{"GBP": 1, "TRY": 2}}, report.Quality)
+}
@@ -0,0 +1,67 @@
+package roas
+
+import (
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/shopspring/decimal"
+)
+
+// Metrics are the returns on the spend of a key predicted at the end of the prediction horizon
+type Metrics struct {
+	Spend    decimal.Decimal
+	Installs int64
+	// CPI is the cost per install
+	CPI decimal.Decimal
+	// ROAS is the predicted revenue of the installs divided by the spend
+	ROAS decimal.Decimal
+	// Profit is the predicted revenue of the installs minus the spend
+	Profit decimal.Decimal
+	// PaybackDay is the first day the LTV reaches the CPI, 0 if it is not reached within the horizon
+	PaybackDay int64
+}
+
+type MetricsByKey map[string]Metrics
+
+// Compute joins the spend with the predictions, keys missing in any of them are skipped. The revenue is the predicted
+// LTV multiplied by the installs of the spend file, as the users of the source may be counted differently
+func Compute(spend SpendByKey, predictions predictor.PredictedLTVs, ltvs aggregator.AggregatedLTVsByKey, predictionLength int64) MetricsByKey {
+	result := make(MetricsByKey)
+	for k, predicted := range predictions {
+		s, ok := spend[k]
+		if !ok {
+			continue
+		}
+		installs := decimal.NewFromInt(s.Installs)
+		cpi := s.Spend.Div(installs)
+		result[k] = Metrics{
+			Spend:      s.Spend,
+			Installs:   s.Installs,
+			CPI:        cpi,
+			ROAS:       predicted.Mul(installs).Div(s.Spend),
+			Profit:     predicted.Mul(installs).Sub(s.Spend),
+			PaybackDay: paybackDay(ltvs[k], predicted, cpi, predictionLength),
+		}
+	}
+	return result
+}
+
+// paybackDay finds the first observed day the LTV reaches the CPI, after the observed days the curve is
+// linearly interpolated between the last observed LTV and the predicted one
+func paybackDay(ltvs []decimal.Decimal, predicted, cpi decimal.Decimal, predictionLength int64) int64 {
+	for i, v := range ltvs {
+		if v.GreaterThanOrEqual(cpi) {
+			return int64(i + 1)
+		}
+	}
+	days := int64(len(ltvs))
+	last := decimal.Zero
+	if days > 0 {
+		last = ltvs[days-1]
+	}
+	// the last observed LTV is lower than the CPI, so the predicted one is higher than it if it reaches the CPI
+	if predictionLength <= days || predicted.LessThan(cpi) {
+		return 0
+	}
+	steps := cpi.Sub(last).Mul(decimal.NewFromInt(predictionLength - days)).Div(predicted.Sub(last)).Ceil()
+	return days + steps.IntPart()
+}
@@ -0,0 +1,67 @@
+package roas
+
+import (
+	"testing"
+
+	"github.com/pklimuk/ltv-predictor/aggregator"
+	"github.com/pklimuk/ltv-predictor/predictor"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompute(t *testing.T) {
+	spend := SpendByKey{
+		"US": {Spend: decimal.NewFromInt(100), Installs: 20},
+		"DE": {Spend: decimal.NewFromInt(90), Installs: 10},
+		"FR": {Spend: decimal.NewFromInt(10), Installs: 1},
+	}
+	predictions := predictor.PredictedLTVs{
+		"US": decimal.NewFromInt(10),
+		"DE": decimal.NewFromInt(6),
+		"TR": decimal.NewFromInt(1),
+	}
+	ltvs := aggregator.AggregatedLTVsByKey{
+		"US": {decimal.NewFromInt(1), decimal.NewFromInt(2)},
+		"DE": {decimal.NewFromInt(1), decimal.NewFromInt(2)},
+		"TR": {decimal.NewFromInt(1)},
+	}
+
+	result := Compute(spend, predictions, ltvs, 10)
+
+	assert.Len(t, result, 2)
+	us := result["US"]
+	assert.True(t, decimal.NewFromInt(5).Equal(us.CPI))
+	assert.True(t, decimal.NewFromInt(2).Equal(us.ROAS))
+	assert.True(t, decimal.NewFromInt(100).Equal(us.Profit))
+	// 2 + (10 - 2) * (day - 2) / 8 reaches 5 on day 5
+	assert.Equal(t, int64(5), us.PaybackDay)
+
+	de := result["DE"]
+	assert.True(t, decimal.NewFromInt(9).Equal(de.CPI))
+	assert.Equal(t, "0.67", de.ROAS.StringFixed(2))
+	assert.True(t, decimal.NewFromInt(-30).Equal(de.Profit))
+	assert.Equal(t, int64(0), de.PaybackDay)
+}
+
+func TestPaybackDay(t *testing.T) {
+	ltvs := []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(3), decimal.NewFromInt(4)}
+	tests := []struct {
+		name      string
+		predicted int64
+		cpi       int64
+		expected  int64
+	}{
+		{"Paid back on an observed day", 10, 3, 2},
+		{"Paid back after the observed days", 10, 7, 6},
+		{"Paid back on the last day", 10, 10, 9},
+		{"Not paid back within the horizon", 10, 11, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			day := paybackDay(ltvs, decimal.NewFromInt(test.predicted), decimal.NewFromInt(test.cpi), 9)
+
+			assert.Equal(t, test.expected, day)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package roas
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pklimuk/ltv-predictor/fileParser"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	spendColumn    = "spend"
+	installsColumn = "installs"
+)
+
+var (
+	ErrSpendError    = errors.New("spend error: %w")
+	ErrNegativeValue = errors.New("%s should not be negative")
+	ErrNotPositive   = errors.New("%s of key %s should be greater than 0")
+	ErrNoSpend       = errors.New("no spend records")
+)
+
+// Spend is the money spent on acquiring the users of a key
+type Spend struct {
+	Spend    decimal.Decimal
+	Installs int64
+}
+
+type SpendByKey map[string]Spend
+
+// ReadSpend reads the spend CSV file, the header should contain the column named after the aggregation(e.g. country
+// or campaign), spend and installs columns, other columns are ignored. Records of the same key are summed,
+// e.g. the spend of campaigns in every country. Invalid records are reported all at once
+func ReadSpend(r io.Reader, aggregateBy string) (SpendByKey, error) {
+	result := make(SpendByKey)
+	records, err := fileParser.ReadCSVColumns(r, []string{aggregateBy, spendColumn, installsColumn}, func(fields []string) error {
+		key, spend, err := convertSpendRecord(fields)
+		if err != nil {
+			return err
+		}
+		total := result[key]
+		total.Spend = total.Spend.Add(spend.Spend)
+		total.Installs += spend.Installs
+		result[key] = total
+		return nil
+	})
+	errs := []error{err, result.Validate()}
+	if records == 0 && err == nil {
+		errs = append(errs, ErrNoSpend)
+	}
+	if err = errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf(ErrSpendError.Error(), err)
+	}
+	return result, nil
+}
+
+// Validate checks that the spend and the installs of every key are positive, invalid keys are reported all at once
+func (s SpendByKey) Validate() error {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var errs []error
+	for _, k := range keys {
+		if !s[k].Spend.IsPositive() {
+			errs = append(errs, fmt.Errorf(ErrNotPositive.Error(), spendColumn, k))
+		}
+		if s[k].Installs <= 0 {
+			errs = append(errs, fmt.Errorf(ErrNotPositive.Error(), installsColumn, k))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// convertSpendRecord converts the key, spend and installs fields of the record
+func convertSpendRecord(fields []string) (string, Spend, error) {
+	spend, err := decimal.NewFromString(strings.TrimSpace(fields[1]))
+	if err != nil {
+		return "", Spend{}, err
+	}
+	if spend.IsNegative() {
+		return "", Spend{}, fmt.Errorf(ErrNegativeValue.Error(), spendColumn)
+	}
+	installs, err := strconv.ParseInt(strings.TrimSpace(fields[2]), 10, 64)
+	if err != nil {
+		return "", Spend{}, err
+	}
+	if installs < 0 {
+		return "", Spend{}, fmt.Errorf(ErrNegativeValue.Error(), installsColumn)
+	}
+	return strings.TrimSpace(fields[0]), Spend{Spend: spend, Installs: installs}, nil
+}
@@ -0,0 +1,60 @@
+package roas
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadSpend(t *testing.T) {
+	data := `date,Campaign,Country,Spend,Installs
+2023-10-01,c1,US,100.50,10
+2023-10-01,c2,US,50,5
+2023-10-01,c1,DE,30,3
+`
+
+	byCountry, err := ReadSpend(strings.NewReader(data), "country")
+
+	assert.NoError(t, err)
+	assert.Len(t, byCountry, 2)
+	assert.True(t, decimal.RequireFromString("150.5").Equal(byCountry["US"].Spend))
+	assert.Equal(t, int64(15), byCountry["US"].Installs)
+	assert.Equal(t, int64(3), byCountry["DE"].Installs)
+
+	byCampaign, err := ReadSpend(strings.NewReader(data), "campaign")
+
+	assert.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("130.5").Equal(byCampaign["c1"].Spend))
+	assert.Equal(t, int64(13), byCampaign["c1"].Installs)
+}
+
+func TestReadSpend_MissingColumns(t *testing.T) {
+	_, err := ReadSpend(strings.NewReader("campaign,cost\nc1,10\n"), "country")
+
+	assert.EqualError(t, err, "spend error: column country is not found in the header\n"+
+		"column spend is not found in the header\ncolumn installs is not found in the header")
+}
+
+func TestReadSpend_InvalidRecords(t *testing.T) {
+	data := `country,spend,installs
+US,abc,10
+DE,-5,1
+FR,10
+TR,0,0
+`
+
+	_, err := ReadSpend(strings.NewReader(data), "country")
+
+	assert.EqualError(t, err, "spend error: invalid record on line 2: can't convert abc to decimal\n"+
+		"invalid record on line 3: spend should not be negative\n"+
+		"invalid record on line 4: not enough fields in the record\n"+
+		"spend of key TR should be greater than 0\ninstalls of key TR should be greater than 0")
+}
+
+func TestReadSpend_NoRecords(t *testing.T) {
+	_, err := ReadSpend(strings.NewReader("country,spend,installs\n"), "country")
+
+	assert.EqualError(t, err, "spend error: no spend records")
+}
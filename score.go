@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pklimuk/ltv-predictor/config"
+	flagsParser "github.com/pklimuk/ltv-predictor/flagsParser"
+)
+
+// score compares the curves of the saved model with the observed LTVs of every key
+func score(args []string) int {
+	flags, parseErr := flagsParser.ParseCommand(flagsParser.ScoreCommand, args, os.LookupEnv)
+	appConfig, err := config.CreateAppConfig(flags)
+	if err = errors.Join(parseErr, err); err != nil {
+		log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+		return exitUsage
+	}
+	model, err := config.LoadModel(flags)
+	if err != nil {
+		log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+		return exitUsage
+	}
+
+	ctx, cancel := newContext(flags.Timeout)
+	defer cancel()
+
+	_, ltvs, err := newProcessor(appConfig).Aggregate(ctx)
+	if err != nil {
+		log.Printf("An error occurred during processing:\n\t%s", indent(err))
+		return exitFailure
+	}
+	scores, failures, err := model.Score(ctx, ltvs)
+	if err != nil {
+		log.Printf("An error occurred during scoring:\n\t%s", indent(err))
+		return exitFailure
+	}
+	fmt.Printf("Model: %s fitted on %s at %s\n\n", model.Model, model.Source, model.FittedAt.Format("2006-01-02 15:04:05 MST"))
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "KEY\tDAYS\tOBSERVED\tPREDICTED\tMAE\t")
+	for _, s := range scores {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t\n", s.Key, s.Days, s.Observed.StringFixed(2), s.Predicted.StringFixed(2), s.MAE.StringFixed(2))
+	}
+	err = tw.Flush()
+	if err != nil {
+		log.Printf("An error occurred during printing:\n\t%s", indent(err))
+		return exitFailure
+	}
+	if len(failures) > 0 {
+		printFailures(os.Stdout, failures)
+		return exitPartialSuccess
+	}
+	return exitOK
+}
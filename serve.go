@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	flagsParser "github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/grpcApi"
+	"github.com/pklimuk/ltv-predictor/server"
+)
+
+func serve(args []string) int {
+	flags, err := flagsParser.ParseCommand(flagsParser.ServeCommand, args, os.LookupEnv)
+	if err != nil {
+		log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+		return exitUsage
+	}
+
+	// the rates file is read before serving, so an invalid file is reported at once
+	service, err := grpcApi.NewService(flags.Rates, flags.RatesDate)
+	if err != nil {
+		log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+		return exitUsage
+	}
+
+	ctx, stop := newContext(0)
+	defer stop()
+	// both servers are stopped if either of them fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	serveErrs := make(chan error, 2)
+	running := 0
+	if flags.GRPCAddr != "" {
+		// the address is bound before the HTTP server is started, so an invalid address fails at once
+		listener, err := net.Listen("tcp", flags.GRPCAddr)
+		if err != nil {
+			log.Printf("An error occurred during serving gRPC:\n\t%s", indent(err))
+			return exitFailure
+		}
+		running++
+		go func() {
+			serveErrs <- serveGRPC(ctx, listener, service, flags)
+		}()
+	}
+
+	s := server.Server{
+		Addr:            flags.Addr,
+		MaxBodySize:     flags.MaxBodySize,
+		ShutdownTimeout: flags.ShutdownTimeout,
+	}
+	running++
+	go func() {
+		serveErrs <- s.ListenAndServe(ctx)
+	}()
+
+	var errs []error
+	for ; running > 0; running-- {
+		err := <-serveErrs
+		if err != nil {
+			errs = append(errs, err)
+			cancel()
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		log.Printf("An error occurred during serving:\n\t%s", indent(err))
+		return exitFailure
+	}
+	return exitOK
+}
+
+// serveGRPC serves the gRPC service until the context is cancelled and then stops it gracefully,
+// the in-flight streams are closed if they don't finish within the shutdown timeout
+func serveGRPC(ctx context.Context, listener net.Listener, service *grpcApi.Server, flags *flagsParser.Flags) error {
+	s := grpcApi.NewServer(service)
+	stopped := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-stopped:
+			return
+		case <-ctx.Done():
+		}
+		timer := time.AfterFunc(flags.ShutdownTimeout, s.Stop)
+		defer timer.Stop()
+		s.GracefulStop()
+	}()
+	log.Printf("gRPC listening on %s", listener.Addr())
+	err := s.Serve(listener)
+	close(stopped)
+	// Serve returns as soon as the listener is closed, the in-flight streams are waited for before returning
+	<-done
+	if err != nil {
+		return fmt.Errorf(grpcApi.ErrServerError.Error(), err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/config"
+	"github.com/pklimuk/ltv-predictor/flagsParser"
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/pklimuk/ltv-predictor/processor"
+)
+
+// multipartMemory is the part of the multipart form kept in memory, the rest is stored in temporary files
+const multipartMemory = 8 << 20
+
+var (
+	ErrServerError          = errors.New("server error: %w")
+	ErrUnsupportedMediaType = errors.New("unsupported content type")
+	ErrInvalidParameter     = errors.New("invalid value of %s parameter")
+	ErrNoFile               = errors.New("multipart form should contain file field")
+)
+
+// Server exposes the prediction pipeline over HTTP
+type Server struct {
+	Addr            string
+	MaxBodySize     int64
+	ShutdownTimeout time.Duration
+
+	metrics outputPrinter.MetricsHandler
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler returns the handler with all the endpoints of the server:
+// POST /predict, GET /healthz and GET /metrics with the latest predictions
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/predict", s.handlePredict)
+	mux.HandleFunc("/healthz", handleHealthz)
+	mux.Handle("/metrics", &s.metrics)
+	return mux
+}
+
+// ListenAndServe serves requests until the context is cancelled and then shuts the server down,
+// giving in-flight requests ShutdownTimeout to finish
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	httpServer := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+	log.Printf("Listening on %s", s.Addr)
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf(ErrServerError.Error(), err)
+	case <-ctx.Done():
+	}
+	log.Printf("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+	err := httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		return fmt.Errorf(ErrServerError.Error(), err)
+	}
+	return nil
+}
+
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, "ok\n")
+}
+
+func (s *Server) handlePredict(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, s.MaxBodySize)
+
+	flags, body, err := parsePredictRequest(r)
+	if err != nil {
+		writeError(w, requestErrorStatus(err), err)
+		return
+	}
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	appConfig, err := config.CreateReaderAppConfig(flags, body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	p := processor.Processor{
+		Parser:           appConfig.Parser,
+		Aggregator:       appConfig.Aggregator,
+		Predictor:        appConfig.Predictor,
+		Model:            appConfig.Model,
+		AggregateBy:      appConfig.AggregateBy,
+		PredictionLength: appConfig.PredictionLength,
+	}
+	report, err := p.Run(r.Context())
+	if err != nil {
+		writeError(w, requestErrorStatus(err), err)
+		return
+	}
+	s.metrics.Update(*report)
+
+	w.Header().Set("Content-Type", "application/json")
+	err = outputPrinter.JSONPrinter{}.Print(w, *report)
+	if err != nil {
+		log.Printf("Can't write response: %v", err)
+	}
+}
+
+// parsePredictRequest converts parameters of the request into flags and returns the reader with the source data.
+// The data could be sent as a raw CSV or JSON body or as the file field of a multipart form
+func parsePredictRequest(r *http.Request) (*flagsParser.Flags, io.Reader, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w(%s)", ErrUnsupportedMediaType, r.Header.Get("Content-Type"))
+	}
+	var source string
+	var body io.Reader
+	switch mediaType {
+	case "text/csv":
+		source, body = "request.csv", r.Body
+	case "application/json":
+		source, body = "request.json", r.Body
+	case "multipart/form-data":
+		err = r.ParseMultipartForm(multipartMemory)
+		if err != nil {
+			return nil, nil, err
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, nil, ErrNoFile
+		}
+		source, body = header.Filename, file
+	default:
+		return nil, nil, fmt.Errorf("%w(%s)", ErrUnsupportedMediaType, mediaType)
+	}
+	// format parameter overrides the format detected from the content type or the file name
+	switch format := r.FormValue("format"); format {
+	case "":
+	case "csv", "json":
+		source = "request." + format
+	default:
+		return nil, nil, fmt.Errorf(ErrInvalidParameter.Error(), "format")
+	}
+
+	flags := &flagsParser.Flags{
+		Source:           source,
+		Model:            valueOrDefault(r.FormValue("model"), flagsParser.DefaultModel),
+		AggregateBy:      valueOrDefault(r.FormValue("aggregate"), flagsParser.DefaultAggregateBy),
+		PredictionLength: flagsParser.DefaultPredictionLength,
+	}
+	if predictionLength := r.FormValue("predictionLength"); predictionLength != "" {
+		flags.PredictionLength, err = strconv.ParseInt(predictionLength, 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf(ErrInvalidParameter.Error(), "predictionLength")
+		}
+	}
+	return flags, body, nil
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func requestErrorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pklimuk/ltv-predictor/outputPrinter"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCSV = `UserId,CampaignId,Country,Ltv1,Ltv2,Ltv3,Ltv4,Ltv5,Ltv6,Ltv7
+1,campaign1,TR,1,2,3,4,5,6,7
+2,campaign2,US,2,4,6,8,10,12,14
+`
+
+const testJSON = `[{"CampaignId": "campaign1", "Country": "TR", "Ltv1": 1, "Ltv2": 2, "Ltv3": 3, "Ltv4": 4, "Ltv5": 5, "Ltv6": 6, "Ltv7": 7, "Users": 2}]`
+
+func newTestServer() *httptest.Server {
+	s := &Server{MaxBodySize: 1 << 20, ShutdownTimeout: time.Second}
+	return httptest.NewServer(s.Handler())
+}
+
+func decodeReport(t *testing.T, body io.Reader) outputPrinter.JSONReport {
+	var report outputPrinter.JSONReport
+	err := json.NewDecoder(body).Decode(&report)
+	assert.NoError(t, err)
+	return report
+}
+
+func TestServer_Predict(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	t.Run("CSV body", func(t *testing.T) {
+		resp, err := http.Post(ts.URL+"/predict?predictionLength=10&aggregate=campaign", "text/csv", strings.NewReader(testCSV))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		report := decodeReport(t, resp.Body)
+		assert.Equal(t, "linearExtrapolation", report.Model)
+		assert.Equal(t, "campaign", report.Aggregation)
+		assert.Equal(t, int64(10), report.PredictionLength)
+		assert.Len(t, report.Predictions, 2)
+		assert.Equal(t, "campaign1", report.Predictions[0].Key)
+		assert.Equal(t, json.Number("10"), report.Predictions[0].Predicted)
+	})
+
+	t.Run("JSON body", func(t *testing.T) {
+		resp, err := http.Post(ts.URL+"/predict?model=linearRegression", "application/json; charset=utf-8", strings.NewReader(testJSON))
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		report := decodeReport(t, resp.Body)
+		assert.Equal(t, "linearRegression", report.Model)
+		assert.Equal(t, []outputPrinter.JSONPrediction{{
+			Key:       "TR",
+			Users:     2,
+			LTVs:      []json.Number{"1", "2", "3", "4", "5", "6", "7"},
+			Predicted: "60",
+		}}, report.Predictions)
+	})
+
+	t.Run("Multipart upload", func(t *testing.T) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		assert.NoError(t, writer.WriteField("predictionLength", "20"))
+		part, err := writer.CreateFormFile("file", "data.csv")
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(testCSV))
+		assert.NoError(t, err)
+		assert.NoError(t, writer.Close())
+
+		resp, err := http.Post(ts.URL+"/predict", writer.FormDataContentType(), &body)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		report := decodeReport(t, resp.Body)
+		assert.Equal(t, int64(20), report.PredictionLength)
+		assert.Equal(t, "TR", report.Predictions[0].Key)
+		assert.Equal(t, json.Number("20"), report.Predictions[0].Predicted)
+	})
+}
+
+func TestServer_Predict_Errors(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{"Wrong method", http.MethodGet, "/predict", "", "", http.StatusMethodNotAllowed, "method not allowed"},
+		{"Unsupported content type", http.MethodPost, "/predict", "text/plain", "data", http.StatusUnsupportedMediaType, "unsupported content type(text/plain)"},
+		{"Unknown model", http.MethodPost, "/predict?model=unknown", "text/csv", testCSV, http.StatusBadRequest, "config error: unknown model"},
+		{"Invalid prediction length", http.MethodPost, "/predict?predictionLength=abc", "text/csv", testCSV, http.StatusUnprocessableEntity, "invalid value of predictionLength parameter"},
+		{"Invalid format", http.MethodPost, "/predict?format=xml", "text/csv", testCSV, http.StatusUnprocessableEntity, "invalid value of format parameter"},
+		{"Invalid data", http.MethodPost, "/predict", "application/json", "{", http.StatusUnprocessableEntity, "parsing error: json parsing error: unexpected end of JSON input"},
+		{"Body too large", http.MethodPost, "/predict", "application/json", strings.Repeat(" ", 2<<20), http.StatusRequestEntityTooLarge, "parsing error: can't read data from file: http: request body too large"},
+		{"Missing file", http.MethodPost, "/predict", "multipart/form-data; boundary=x", "--x--\r\n", http.StatusUnprocessableEntity, "multipart form should contain file field"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(test.method, ts.URL+test.url, strings.NewReader(test.body))
+			assert.NoError(t, err)
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			var errResp errorResponse
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+			assert.Equal(t, test.expectedError, errResp.Error)
+		})
+	}
+}
+
+func TestServer_HealthzAndMetrics(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/healthz")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ok\n", string(body))
+
+	resp, err = http.Post(ts.URL+"/predict", "text/csv", strings.NewReader(testCSV))
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	resp, err = http.Get(ts.URL + "/metrics")
+	assert.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), `ltv_users{key="US"} 1`)
+}
+
+func TestServer_ListenAndServe_GracefulShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	s := &Server{Addr: addr, MaxBodySize: 1 << 20, ShutdownTimeout: time.Second}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.ListenAndServe(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + "/healthz")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/pklimuk/ltv-predictor/config"
+	flagsParser "github.com/pklimuk/ltv-predictor/flagsParser"
+)
+
+// validate parses and aggregates the source file and prints a short summary of it
+func validate(args []string) int {
+	flags, parseErr := flagsParser.ParseCommand(flagsParser.ValidateCommand, args, os.LookupEnv)
+	// invalid records are reported instead of being skipped
+	flags.Strict = true
+	appConfig, err := config.CreateAppConfig(flags)
+	if err = errors.Join(parseErr, err); err != nil {
+		log.Printf("An error occurred during configuration:\n\t%s", indent(err))
+		return exitUsage
+	}
+
+	ctx, cancel := newContext(flags.Timeout)
+	defer cancel()
+
+	revenues, ltvs, err := newProcessor(appConfig).Aggregate(ctx)
+	if err != nil {
+		fmt.Printf("%s is invalid:\n\t%s\n", flags.Source, indent(err))
+		return exitInvalidData
+	}
+	var users int64
+	days := 0
+	for k, v := range revenues {
+		users += v.UsersCount
+		days = max(days, len(ltvs[k]))
+	}
+	fmt.Printf("%s is valid: %d users, %d keys by %s, %d days\n", flags.Source, users, len(revenues), flags.AggregateBy, days)
+	return exitOK
+}